@@ -0,0 +1,151 @@
+package simconnect
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SimVar is a simulation variable of the catalog of those commonly used, taken from the SDK documentation of each
+// simulator.
+type SimVar struct {
+	// Name is the name of the simvar in upper case, without an index
+	Name string
+	// Unit is the unit the SDK documents the simvar in, empty for strings and structs
+	Unit string
+	// Type is the value of the type tag the simvar is best requested as
+	Type string
+	// Indexed is set for simvars taking an index after a colon, such as the engine in GENERAL ENG RPM:1
+	Indexed bool
+	// Settable is set for simvars which can be written with SetDataOnSimObject
+	Settable bool
+
+	FSX  bool
+	P3D  bool
+	MSFS bool
+}
+
+//go:embed simvars.csv
+var simVarsCSV string
+
+var (
+	catalogOnce sync.Once
+	catalog     map[string]SimVar
+)
+
+// loadCatalog returns the catalog parsed from simvars.csv, keyed by name.
+func loadCatalog() map[string]SimVar {
+	catalogOnce.Do(func() {
+		records, err := csv.NewReader(strings.NewReader(simVarsCSV)).ReadAll()
+		if err != nil {
+			panic(fmt.Sprintf("error reading simvars.csv: %v", err))
+		}
+
+		catalog = make(map[string]SimVar, len(records))
+		// The first record is the header
+		for _, record := range records[1:] {
+			sims := strings.Fields(record[5])
+			catalog[record[0]] = SimVar{
+				Name:     record[0],
+				Unit:     record[1],
+				Type:     record[2],
+				Indexed:  record[3] == "Y",
+				Settable: record[4] == "Y",
+				FSX:      containsString(sims, "FSX"),
+				P3D:      containsString(sims, "P3D"),
+				MSFS:     containsString(sims, "MSFS"),
+			}
+		}
+	})
+	return catalog
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// LookupSimVar returns the simvar name from the catalog. Simvar names are case insensitive and any index, such as
+// the :1 of ADF ACTIVE FREQUENCY:1, is ignored.
+func LookupSimVar(name string) (SimVar, bool) {
+	name = strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	if colon := strings.LastIndexByte(name, ':'); colon >= 0 {
+		name = strings.TrimSpace(name[:colon])
+	}
+
+	simVar, ok := loadCatalog()[name]
+	return simVar, ok
+}
+
+// SimVars returns every simvar of the catalog, ordered by name.
+func SimVars() []SimVar {
+	simVars := make([]SimVar, 0, len(loadCatalog()))
+	for _, simVar := range loadCatalog() {
+		simVars = append(simVars, simVar)
+	}
+	sort.Slice(simVars, func(i, j int) bool { return simVars[i].Name < simVars[j].Name })
+	return simVars
+}
+
+// SimVarWarningKind is why a SimVarWarning was given.
+type SimVarWarningKind int
+
+const (
+	// SimVarUnknown warns of a simvar missing from the catalog, often a misspelt name
+	SimVarUnknown SimVarWarningKind = iota
+	// SimVarNotSettable warns of a simvar written with SetDataOnSimObject which the catalog says is read only
+	SimVarNotSettable
+)
+
+// SimVarWarning is passed to the handler set with WithSimVarWarnings for a field of a data definition which may not
+// work as intended. The simulator is still sent the field as it is.
+type SimVarWarning struct {
+	Kind         SimVarWarningKind
+	DefinitionID uint32
+	Field        string
+	Name         string
+}
+
+func (w SimVarWarning) String() string {
+	switch w.Kind {
+	case SimVarNotSettable:
+		return fmt.Sprintf("field %s of definition %d: simvar %s is not settable", w.Field, w.DefinitionID, w.Name)
+	default:
+		return fmt.Sprintf("field %s of definition %d: simvar %s is not in the catalog", w.Field, w.DefinitionID, w.Name)
+	}
+}
+
+// warnUnknownSimVars warns of the fields of definitionID naming simvars missing from the catalog.
+func (instance *SimconnectInstance) warnUnknownSimVars(definitionID uint32, fields []definitionField) {
+	for _, field := range fields {
+		if _, ok := LookupSimVar(field.name); !ok {
+			instance.simVarWarning(SimVarWarning{Kind: SimVarUnknown, DefinitionID: definitionID, Field: field.fieldName, Name: field.name})
+		}
+	}
+}
+
+// warnNotSettable warns of the fields of definitionID which the catalog says can not be set, the first time data is
+// set for it.
+func (instance *SimconnectInstance) warnNotSettable(definitionID uint32) {
+	instance.definitionMapMutex.Lock()
+	if instance.settableChecked[definitionID] {
+		instance.definitionMapMutex.Unlock()
+		return
+	}
+	instance.settableChecked[definitionID] = true
+	fields := instance.definitionFields[definitionID]
+	instance.definitionMapMutex.Unlock()
+
+	for _, field := range fields {
+		if simVar, ok := LookupSimVar(field.name); ok && !simVar.Settable {
+			instance.simVarWarning(SimVarWarning{Kind: SimVarNotSettable, DefinitionID: definitionID, Field: field.fieldName, Name: field.name})
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package simconnect
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+func TestLookupSimVar(t *testing.T) {
+	adf, ok := LookupSimVar("ADF ACTIVE FREQUENCY:1")
+	require.True(t, ok)
+	assert.Equal(t, SimVar{
+		Name:    "ADF ACTIVE FREQUENCY",
+		Unit:    "Frequency ADF BCD32",
+		Type:    "int32",
+		Indexed: true,
+		FSX:     true,
+		P3D:     true,
+		MSFS:    true,
+	}, adf)
+
+	heading, ok := LookupSimVar("Plane  Heading Degrees True")
+	require.True(t, ok)
+	assert.True(t, heading.Settable)
+	assert.Equal(t, "radians", heading.Unit)
+
+	parked, ok := LookupSimVar("plane in parking state")
+	require.True(t, ok)
+	assert.False(t, parked.FSX)
+	assert.True(t, parked.MSFS)
+
+	_, ok = LookupSimVar("AUTOPILOT ALTITUDE LOCK VR:3")
+	assert.False(t, ok)
+}
+
+func TestSimVars(t *testing.T) {
+	simVars := SimVars()
+	require.NotEmpty(t, simVars)
+	assert.True(t, sort.SliceIsSorted(simVars, func(i, j int) bool { return simVars[i].Name < simVars[j].Name }))
+
+	for _, simVar := range simVars {
+		_, ok := tagDataTypes[simVar.Type]
+		assert.True(t, ok, "type %q of %s", simVar.Type, simVar.Name)
+		assert.True(t, simVar.FSX || simVar.P3D || simVar.MSFS, "%s is in no simulator", simVar.Name)
+	}
+}
+
+// warnings collects the warnings given to WithSimVarWarnings.
+type warnings struct {
+	mutex    sync.Mutex
+	warnings []SimVarWarning
+}
+
+func (w *warnings) add(warning SimVarWarning) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.warnings = append(w.warnings, warning)
+}
+
+func (w *warnings) get() []SimVarWarning {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return append([]SimVarWarning(nil), w.warnings...)
+}
+
+func TestCatalogUnits(t *testing.T) {
+	type unitReport struct {
+		Altitude float64 `name:"Plane Altitude"`
+		Heading  float64 `name:"PLANE HEADING DEGREES TRUE"`
+		Latitude float64 `name:"Plane Latitude" unit:"degrees"`
+		Title    string  `name:"Title"`
+	}
+
+	instance, err := NewSimConnectWithTransport(t.Name(), NewFakeTransport())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	_, err = definitionFor[unitReport](instance)
+	require.NoError(t, err)
+	definition, ok := instance.DataDefinition(reflect.TypeOf(unitReport{}))
+	require.True(t, ok)
+
+	var units []string
+	for _, field := range definition.Fields {
+		units = append(units, field.Unit)
+	}
+	assert.Equal(t, []string{"feet", "radians", "degrees", ""}, units)
+}
+
+func TestCatalogWarnings(t *testing.T) {
+	type misspelt struct {
+		Altitude float64 `name:"Plane Altitude" unit:"feet"`
+		Speed    float64 `name:"Airspeed Indicted" unit:"knots"`
+	}
+	type settable struct {
+		Altitude float64 `name:"Plane Altitude" unit:"feet"`
+		OnGround bool    `name:"Sim On Ground" unit:"bool"`
+	}
+
+	collected := &warnings{}
+	instance, err := NewSimConnectWithTransport(t.Name(), NewFakeTransport(), WithSimVarWarnings(collected.add))
+	require.NoError(t, err)
+	defer instance.Close()
+
+	_, err = definitionFor[misspelt](instance)
+	require.NoError(t, err)
+	require.Len(t, collected.get(), 1)
+	warning := collected.get()[0]
+	assert.Equal(t, SimVarWarning{Kind: SimVarUnknown, DefinitionID: 1, Field: "Speed", Name: "Airspeed Indicted"}, warning)
+	assert.Equal(t, "field Speed of definition 1: simvar Airspeed Indicted is not in the catalog", warning.String())
+
+	// Setting warns of the fields which can not be set once per definition
+	require.NoError(t, Set(instance, simconnect_data.OBJECT_ID_USER, settable{Altitude: 1000}))
+	require.NoError(t, Set(instance, simconnect_data.OBJECT_ID_USER, settable{Altitude: 2000}))
+	require.Len(t, collected.get(), 2)
+	warning = collected.get()[1]
+	assert.Equal(t, SimVarWarning{Kind: SimVarNotSettable, DefinitionID: 2, Field: "OnGround", Name: "Sim On Ground"}, warning)
+	assert.Equal(t, "field OnGround of definition 2: simvar Sim On Ground is not settable", warning.String())
+}
+
+func TestCatalogWarningsIgnoredByDefault(t *testing.T) {
+	type misspelt struct {
+		Speed float64 `name:"Airspeed Indicted" unit:"knots"`
+	}
+
+	logged := &bytes.Buffer{}
+	log.SetOutput(logged)
+	defer log.SetOutput(os.Stderr)
+
+	instance, err := NewSimConnectWithTransport(t.Name(), NewFakeTransport())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	_, err = definitionFor[misspelt](instance)
+	require.NoError(t, err)
+	assert.Empty(t, logged.String())
+}
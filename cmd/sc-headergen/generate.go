@@ -0,0 +1,586 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// prefix is dropped from the names of the header, as the package name says as much
+const prefix = "SIMCONNECT_"
+
+// goTypeNames are the Go names of the types of the header which are not simply the C name in camel case, mostly
+// those written by hand before the header was generated.
+var goTypeNames = map[string]string{
+	"SIMCONNECT_DATATYPE":                    "DataType",
+	"SIMCONNECT_DATA_INITPOSITION":           "SimconnectDataInitPosition",
+	"SIMCONNECT_DATA_LATLONALT":              "LatLonAlt",
+	"SIMCONNECT_DATA_MARKERSTATE":            "MarkerState",
+	"SIMCONNECT_DATA_XYZ":                    "XYZ",
+	"SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE": "RecvEventObjectAddRemove",
+	"SIMCONNECT_RECV_SIMOBJECT_DATA_BYTYPE":  "RecvSimobjectDataByType",
+}
+
+// cTypes maps the C types of fields and constants to Go types, GUID being a 16 byte array.
+var cTypes = map[string]string{
+	"DWORD":         "uint32",
+	"unsigned long": "uint32",
+	"unsigned int":  "uint32",
+	"int":           "int32",
+	"long":          "int32",
+	"float":         "float32",
+	"double":        "float64",
+	"char":          "byte",
+	"BYTE":          "byte",
+	"GUID":          "[16]byte",
+}
+
+// cValues are the values of the identifiers from other headers used in constants and array lengths.
+var cValues = map[string]struct {
+	goExpr string
+	value  constant.Value
+}{
+	"DWORD_MAX": {"0xffffffff", constant.MakeUint64(math.MaxUint32)},
+	"FLT_MAX":   {"math.MaxFloat32", constant.MakeFloat64(math.MaxFloat32)},
+	"MAX_PATH":  {"MAX_PATH", constant.MakeInt64(260)},
+}
+
+// generator writes the Go for a parsed header, leaving out whatever the package already declares.
+type generator struct {
+	header   *header
+	pkg      string
+	declared map[string]bool
+	// declaredValues are the values of the constants the package declares, checked against the header
+	declaredValues map[string]constant.Value
+	values         map[string]constant.Value
+	imports        map[string]bool
+	body           bytes.Buffer
+	// consts are the lines of the block of constants being written, if any
+	consts []string
+}
+
+// generate returns the Go source of the constants, enum types and structs of the header in src for the package in
+// dir, whose other files, apart from output, are parsed to leave out the names they declare.
+func generate(src, dir, output string) ([]byte, error) {
+	h, err := parseHeader(src)
+	if err != nil {
+		return nil, fmt.Errorf("parsing header error: %v", err)
+	}
+
+	pkg, declared, declaredValues, err := packageDeclarations(dir, output)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		header:         h,
+		pkg:            pkg,
+		declared:       declared,
+		declaredValues: declaredValues,
+		values:         map[string]constant.Value{},
+		imports:        map[string]bool{},
+	}
+	for _, decl := range h.declarations {
+		switch decl := decl.(type) {
+		case *cConst:
+			err = g.constant(decl)
+		case *enum:
+			g.flushConsts()
+			err = g.enum(decl)
+		case *cStruct:
+			g.flushConsts()
+			err = g.cStruct(decl)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	g.flushConsts()
+	if g.imports["fmt"] && g.imports["strings"] {
+		g.body.WriteString(flagsStringFunc)
+	}
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "// Code generated by sc-headergen from %s. DO NOT EDIT.\n\npackage %s\n\n", "SimConnect.h", g.pkg)
+	var imports []string
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	if len(imports) > 0 {
+		out.WriteString("import (\n")
+		for _, imp := range imports {
+			fmt.Fprintf(out, "\t%q\n", imp)
+		}
+		out.WriteString(")\n")
+	}
+	out.Write(g.body.Bytes())
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code error: %v", err)
+	}
+	return formatted, nil
+}
+
+// packageDeclarations returns the package name, top level names and values of the constants declared by the Go files
+// in dir, leaving out output and the tests.
+func packageDeclarations(dir, output string) (string, map[string]bool, map[string]constant.Value, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	pkg := filepath.Base(dir)
+	declared := map[string]bool{}
+	values := map[string]constant.Value{}
+	fileSet := token.NewFileSet()
+	for _, file := range files {
+		if filepath.Base(file) == filepath.Base(output) || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		parsed, err := parser.ParseFile(fileSet, file, src, parser.SkipObjectResolution)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("parsing %s error: %v", file, err)
+		}
+		pkg = parsed.Name.Name
+
+		for _, decl := range parsed.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					declared[decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				if decl.Tok == token.CONST {
+					constValues(decl, values)
+				}
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						declared[spec.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							declared[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return pkg, declared, values, nil
+}
+
+// constValues adds the values of the constants of decl to values, leaving out those which can not be evaluated.
+func constValues(decl *ast.GenDecl, values map[string]constant.Value) {
+	var exprs []ast.Expr
+	for i, spec := range decl.Specs {
+		spec := spec.(*ast.ValueSpec)
+		// Constants without a value repeat the expressions of the previous ones
+		if len(spec.Values) > 0 {
+			exprs = spec.Values
+		}
+		iota := constant.MakeInt64(int64(i))
+		for j, name := range spec.Names {
+			if j >= len(exprs) {
+				break
+			}
+			value, err := evalExpr(exprs[j], func(name string) (constant.Value, bool) {
+				if name == "iota" {
+					return iota, true
+				}
+				value, ok := values[name]
+				return value, ok
+			})
+			if err == nil {
+				values[name.Name] = value
+			}
+		}
+	}
+}
+
+// checkDeclared returns an error if the package declares the constant name with a value other than value, the value
+// given by the header.
+func (g *generator) checkDeclared(name string, value constant.Value) error {
+	declared, ok := g.declaredValues[name]
+	if ok && !constant.Compare(declared, token.EQL, value) {
+		return fmt.Errorf("%s is %s in the header but %s in package %s", name, value.ExactString(), declared.ExactString(), g.pkg)
+	}
+	return nil
+}
+
+// constName returns the Go name of the constant cName, which keeps the SIMCONNECT_ prefix only if the package
+// already declares it with it.
+func (g *generator) constName(cName string) string {
+	if g.declared[cName] {
+		return cName
+	}
+	return strings.TrimPrefix(cName, prefix)
+}
+
+// typeName returns the Go name of the enum cName.
+func typeName(cName string) string {
+	if name, ok := goTypeNames[cName]; ok {
+		return name
+	}
+	return camelCase(strings.TrimPrefix(cName, prefix))
+}
+
+// structName returns the Go name of the struct cName, without DATA_ for the structs of data.
+func structName(cName string) string {
+	if name, ok := goTypeNames[cName]; ok {
+		return name
+	}
+	return camelCase(strings.TrimPrefix(strings.TrimPrefix(cName, prefix), "DATA_"))
+}
+
+// camelCase turns an upper case name with underscores into camel case, keeping the initialisms in upper case.
+func camelCase(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		switch word {
+		case "ID", "GUID":
+			b.WriteString(word)
+		default:
+			if word != "" {
+				b.WriteString(word[:1] + strings.ToLower(word[1:]))
+			}
+		}
+	}
+	return b.String()
+}
+
+// fieldName returns the Go name of a struct field, without the lower case type prefix of the C name.
+func fieldName(cName string) string {
+	name := cName
+	for _, hungarian := range []string{"guid", "rgb", "dw", "sz", "rg", "u", "f", "e", "b"} {
+		rest := strings.TrimPrefix(name, hungarian)
+		if rest != name && rest != "" && rest[0] >= 'A' && rest[0] <= 'Z' {
+			name = rest
+			break
+		}
+	}
+
+	name = strings.ToUpper(name[:1]) + name[1:]
+	if strings.HasSuffix(name, "Id") {
+		name = strings.TrimSuffix(name, "Id") + "ID"
+	}
+	return name
+}
+
+// goType returns the Go type of a field or constant of cType.
+func (g *generator) goType(cType string) (string, error) {
+	if alias, ok := g.header.aliases[cType]; ok {
+		cType = alias
+	}
+	if goType, ok := cTypes[cType]; ok {
+		return goType, nil
+	}
+	if _, ok := g.header.enums[cType]; ok {
+		return "uint32", nil
+	}
+	if _, ok := g.header.structs[cType]; ok {
+		return structName(cType), nil
+	}
+	return "", fmt.Errorf("unknown type %s", cType)
+}
+
+// expr returns the Go expression and value of the tokens of a C expression.
+func (g *generator) expr(tokens []string) (string, constant.Value, error) {
+	var goTokens []string
+	for _, t := range tokens {
+		if known, ok := cValues[t]; ok {
+			goTokens = append(goTokens, known.goExpr)
+			continue
+		}
+		if _, ok := g.values[t]; ok {
+			goTokens = append(goTokens, g.constName(t))
+			continue
+		}
+		// Integer suffixes, as in 0x10UL
+		if t[0] >= '0' && t[0] <= '9' {
+			t = strings.TrimRight(t, "uUlL")
+		}
+		goTokens = append(goTokens, t)
+	}
+	goExpr := strings.Join(goTokens, "")
+
+	parsed, err := parser.ParseExpr(strings.Join(tokensForEval(tokens), ""))
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing %s error: %v", strings.Join(tokens, " "), err)
+	}
+	value, err := g.eval(parsed)
+	if err != nil {
+		return "", nil, fmt.Errorf("evaluating %s error: %v", strings.Join(tokens, " "), err)
+	}
+	return goExpr, value, nil
+}
+
+// tokensForEval strips the integer suffixes from tokens so they parse as Go.
+func tokensForEval(tokens []string) []string {
+	var stripped []string
+	for _, t := range tokens {
+		if t[0] >= '0' && t[0] <= '9' {
+			t = strings.TrimRight(t, "uUlL")
+		}
+		stripped = append(stripped, t)
+	}
+	return stripped
+}
+
+func (g *generator) eval(expr ast.Expr) (constant.Value, error) {
+	return evalExpr(expr, func(name string) (constant.Value, bool) {
+		if known, ok := cValues[name]; ok {
+			return known.value, true
+		}
+		value, ok := g.values[name]
+		return value, ok
+	})
+}
+
+// evalExpr returns the value of a constant expression, lookup giving the values of the names it uses.
+func evalExpr(expr ast.Expr, lookup func(name string) (constant.Value, bool)) (constant.Value, error) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		value := constant.MakeFromLiteral(expr.Value, expr.Kind, 0)
+		if value.Kind() == constant.Unknown {
+			return nil, fmt.Errorf("bad literal %s", expr.Value)
+		}
+		return value, nil
+	case *ast.Ident:
+		if value, ok := lookup(expr.Name); ok {
+			return value, nil
+		}
+		return nil, fmt.Errorf("unknown name %s", expr.Name)
+	case *ast.ParenExpr:
+		return evalExpr(expr.X, lookup)
+	case *ast.CallExpr:
+		// Conversions, such as uint32(1)
+		if len(expr.Args) != 1 {
+			return nil, fmt.Errorf("unsupported call")
+		}
+		return evalExpr(expr.Args[0], lookup)
+	case *ast.UnaryExpr:
+		x, err := evalExpr(expr.X, lookup)
+		if err != nil {
+			return nil, err
+		}
+		return constant.UnaryOp(expr.Op, x, 0), nil
+	case *ast.BinaryExpr:
+		x, err := evalExpr(expr.X, lookup)
+		if err != nil {
+			return nil, err
+		}
+		y, err := evalExpr(expr.Y, lookup)
+		if err != nil {
+			return nil, err
+		}
+		if expr.Op == token.SHL || expr.Op == token.SHR {
+			shift, _ := constant.Uint64Val(y)
+			return constant.Shift(x, expr.Op, uint(shift)), nil
+		}
+		return constant.BinaryOp(x, expr.Op, y), nil
+	}
+	return nil, fmt.Errorf("unsupported expression %T", expr)
+}
+
+// valueExpr returns the Go for a constant of goType given by the C expression tokens, negative DWORDs wrapping
+// around as they do in C.
+func (g *generator) valueExpr(cName, goType string, tokens []string) (string, error) {
+	goExpr, value, err := g.expr(tokens)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", cName, err)
+	}
+
+	if strings.HasPrefix(goType, "uint") && constant.Sign(value) < 0 {
+		value = constant.BinaryOp(value, token.ADD, constant.MakeUint64(1<<32))
+		goExpr = fmt.Sprintf("%#x", constant.Val(value))
+	}
+	if strings.Contains(goExpr, "math.") {
+		g.imports["math"] = true
+	}
+	g.values[cName] = value
+	return goExpr, nil
+}
+
+func (g *generator) constant(c *cConst) error {
+	goType, err := g.goType(c.cType)
+	if err != nil {
+		return fmt.Errorf("constant %s: %v", c.name, err)
+	}
+	goExpr, err := g.valueExpr(c.name, goType, c.expr)
+	if err != nil {
+		return err
+	}
+
+	if c.doc != "" {
+		g.flushConsts()
+		g.consts = append(g.consts, "// "+c.doc)
+	}
+	name := g.constName(c.name)
+	if err := g.checkDeclared(name, g.values[c.name]); err != nil {
+		return err
+	}
+	if !g.declared[name] {
+		g.consts = append(g.consts, fmt.Sprintf("%s %s = %s%s", name, goType, goExpr, lineComment(c.comment)))
+	}
+	return nil
+}
+
+// flushConsts writes the block of constants written so far, unless the package declares all of them.
+func (g *generator) flushConsts() {
+	consts := g.consts
+	g.consts = nil
+	if len(consts) == 0 || len(consts) == 1 && strings.HasPrefix(consts[0], "//") {
+		return
+	}
+
+	g.body.WriteString("\n")
+	if strings.HasPrefix(consts[0], "//") {
+		fmt.Fprintf(&g.body, "%s\n", consts[0])
+		consts = consts[1:]
+	}
+	fmt.Fprintf(&g.body, "const (\n\t%s\n)\n", strings.Join(consts, "\n\t"))
+}
+
+func (g *generator) enum(e *enum) error {
+	goName := typeName(e.name)
+	next := constant.MakeInt64(0)
+
+	var consts bytes.Buffer
+	var names []string
+	seen := map[string]bool{}
+	for _, member := range e.members {
+		var goExpr string
+		if len(member.expr) > 0 {
+			var err error
+			if goExpr, err = g.valueExpr(member.name, "uint32", member.expr); err != nil {
+				return err
+			}
+		} else {
+			goExpr = next.ExactString()
+			g.values[member.name] = next
+		}
+		value := g.values[member.name]
+		next = constant.BinaryOp(value, token.ADD, constant.MakeInt64(1))
+
+		name := g.constName(member.name)
+		if err := g.checkDeclared(name, value); err != nil {
+			return err
+		}
+		if !g.declared[name] {
+			fmt.Fprintf(&consts, "\t%s uint32 = %s%s\n", name, goExpr, lineComment(member.comment))
+		}
+		// The first name of a value is the one it is known by
+		if !seen[value.ExactString()] {
+			seen[value.ExactString()] = true
+			names = append(names, name)
+		}
+	}
+
+	if consts.Len() > 0 {
+		fmt.Fprintf(&g.body, "\n// %s values\nconst (\n%s)\n", e.name, consts.String())
+	}
+	if g.declared[goName] || len(names) == 0 {
+		return nil
+	}
+
+	g.imports["fmt"] = true
+	fmt.Fprintf(&g.body, "\n// %s is %s, giving its uint32 constants a String method.\ntype %s uint32\n", goName, e.name, goName)
+	if e.flags {
+		g.imports["strings"] = true
+		fmt.Fprintf(&g.body, "\n// String returns the names of the flags set in v, joined by |.\nfunc (v %s) String() string {\n\treturn flagsString(uint32(v), []flagName{\n", goName)
+		for _, name := range names {
+			fmt.Fprintf(&g.body, "\t\t{%s, %q},\n", name, name)
+		}
+		g.body.WriteString("\t})\n}\n")
+		return nil
+	}
+
+	fmt.Fprintf(&g.body, "\n// String returns the name of the constant with the value of v.\nfunc (v %s) String() string {\n\tswitch uint32(v) {\n", goName)
+	for _, name := range names {
+		fmt.Fprintf(&g.body, "\tcase %s:\n\t\treturn %q\n", name, name)
+	}
+	fmt.Fprintf(&g.body, "\t}\n\treturn fmt.Sprintf(\"%s(%%d)\", uint32(v))\n}\n", goName)
+	return nil
+}
+
+func (g *generator) cStruct(s *cStruct) error {
+	goName := structName(s.name)
+	if g.declared[goName] {
+		return nil
+	}
+
+	fmt.Fprintf(&g.body, "\n// %s is %s.\ntype %s struct {\n", goName, s.name, goName)
+	if s.base != "" {
+		fmt.Fprintf(&g.body, "\t%s\n", structName(s.base))
+	}
+	for _, f := range s.fields {
+		goType, err := g.goType(f.cType)
+		if err != nil {
+			return fmt.Errorf("field %s of %s: %v", f.name, s.name, err)
+		}
+		if len(f.array) > 0 {
+			length, _, err := g.expr(f.array)
+			if err != nil {
+				return fmt.Errorf("field %s of %s: %v", f.name, s.name, err)
+			}
+			goType = "[" + length + "]" + goType
+		}
+		fmt.Fprintf(&g.body, "\t%s %s%s\n", fieldName(f.name), goType, lineComment(f.comment))
+	}
+	if s.variable != "" {
+		fmt.Fprintf(&g.body, "\t// %s follows, its length varying with the message\n", fieldName(s.variable))
+	}
+	g.body.WriteString("}\n")
+	return nil
+}
+
+func lineComment(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	return " // " + comment
+}
+
+const flagsStringFunc = `
+type flagName struct {
+	value uint32
+	name  string
+}
+
+// flagsString returns the names of the flags set in v joined by |, with any bits left over in hex.
+func flagsString(v uint32, flags []flagName) string {
+	var names []string
+	for _, flag := range flags {
+		if flag.value == 0 {
+			if v == 0 {
+				return flag.name
+			}
+			continue
+		}
+		if v&flag.value == flag.value {
+			names = append(names, flag.name)
+			v &^= flag.value
+		}
+	}
+	if v != 0 || len(names) == 0 {
+		names = append(names, fmt.Sprintf("%#x", v))
+	}
+	return strings.Join(names, "|")
+}
+`
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// cToken is a word, number or punctuation mark of the header, or a whole // comment.
+type cToken struct {
+	text    string
+	line    int
+	comment bool
+}
+
+// tokenize splits a C header into tokens, dropping preprocessor lines and /* */ comments.
+func tokenize(src string) []cToken {
+	var tokens []cToken
+	line := 1
+	atLineStart := true
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			atLineStart = true
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+		case c == '#' && atLineStart:
+			// Preprocessor directives, continued with a trailing backslash
+			for i < len(src) && (src[i] != '\n' || src[i-1] == '\\') {
+				if src[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			continue
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			tokens = append(tokens, cToken{text: strings.TrimSpace(src[i+2 : i+end]), line: line, comment: true})
+			i += end
+			continue
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				end = len(src) - i - 4
+			}
+			line += strings.Count(src[i:i+end+4], "\n")
+			i += end + 4
+			continue
+		}
+
+		atLineStart = false
+		start := i
+		if isWordByte(c) {
+			for i < len(src) && isWordByte(src[i]) {
+				i++
+			}
+		} else {
+			i++
+		}
+		tokens = append(tokens, cToken{text: src[start:i], line: line})
+	}
+	return tokens
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c < unicode.MaxASCII && (unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)))
+}
+
+// cConst is a static const, or a member of an enum.
+type cConst struct {
+	name    string
+	cType   string
+	expr    []string // tokens of the value, none for enum members numbered on from the previous one
+	comment string
+	// doc is the comment on the line before a static const, which starts a group of them
+	doc string
+}
+
+// enum is an enum, or a flags type whose values are static consts.
+type enum struct {
+	name    string
+	flags   bool
+	members []cConst
+}
+
+// field is a member of a struct, array giving the tokens of its length if it is an array.
+type field struct {
+	name    string
+	cType   string
+	array   []string
+	comment string
+}
+
+type cStruct struct {
+	name   string
+	base   string
+	fields []field
+	// variable is the name of the array of variable length ending the struct, if any
+	variable string
+}
+
+// declaration is one of *cConst, *enum or *cStruct, in the order they appear in the header.
+type declaration interface{}
+
+// header holds the declarations of a header which are generated.
+type header struct {
+	declarations []declaration
+	enums        map[string]*enum
+	structs      map[string]*cStruct
+	// aliases are the typedefs, such as SIMCONNECT_OBJECT_ID, mapped to the type they stand for
+	aliases map[string]string
+}
+
+// headerParser walks the tokens of a header.
+type headerParser struct {
+	tokens []cToken
+	pos    int
+	header *header
+}
+
+// parseHeader parses the constants, enums, flags and structs declared in src.
+func parseHeader(src string) (*header, error) {
+	p := &headerParser{
+		tokens: tokenize(src),
+		header: &header{
+			enums:   map[string]*enum{},
+			structs: map[string]*cStruct{},
+			aliases: map[string]string{},
+		},
+	}
+
+	for !p.done() {
+		if err := p.parseDeclaration(); err != nil {
+			return nil, err
+		}
+	}
+	return p.header, nil
+}
+
+func (p *headerParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *headerParser) peek() cToken {
+	if p.done() {
+		return cToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *headerParser) next() cToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// skipComments skips the comments at the current position.
+func (p *headerParser) skipComments() {
+	for !p.done() && p.peek().comment {
+		p.pos++
+	}
+}
+
+// trailingComment returns the comment following on line, if any, skipping it.
+func (p *headerParser) trailingComment(line int) string {
+	if t := p.peek(); t.comment && t.line == line {
+		p.pos++
+		return t.text
+	}
+	return ""
+}
+
+// leadingComment returns the comment on a line of its own just before the token at index, if any.
+func (p *headerParser) leadingComment(index int) string {
+	if index < 1 {
+		return ""
+	}
+	t, comment := p.tokens[index], p.tokens[index-1]
+	if !comment.comment || comment.line != t.line-1 {
+		return ""
+	}
+	if index >= 2 && p.tokens[index-2].line == comment.line {
+		return ""
+	}
+	return comment.text
+}
+
+// word returns the next token, which must be a word or number.
+func (p *headerParser) word() (cToken, error) {
+	p.skipComments()
+	t := p.next()
+	if t.text == "" || !isWordByte(t.text[0]) {
+		return t, p.errorf(t, "expected a name, found %q", t.text)
+	}
+	return t, nil
+}
+
+// expect skips the next token, which must be text.
+func (p *headerParser) expect(text string) error {
+	p.skipComments()
+	if t := p.next(); t.text != text {
+		return p.errorf(t, "expected %q, found %q", text, t.text)
+	}
+	return nil
+}
+
+// until returns the tokens up to, but not including, the first of ends.
+func (p *headerParser) until(ends ...string) ([]string, error) {
+	var texts []string
+	for {
+		p.skipComments()
+		if p.done() {
+			return nil, fmt.Errorf("unexpected end of header looking for %q", ends)
+		}
+		t := p.peek()
+		for _, end := range ends {
+			if t.text == end {
+				return texts, nil
+			}
+		}
+		texts = append(texts, t.text)
+		p.pos++
+	}
+}
+
+func (p *headerParser) errorf(t cToken, format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", t.line, fmt.Sprintf(format, args...))
+}
+
+func (p *headerParser) parseDeclaration() error {
+	t := p.next()
+	if t.comment {
+		return nil
+	}
+
+	switch t.text {
+	case "SIMCONNECT_ENUM", "enum":
+		return p.parseEnum()
+	case "SIMCONNECT_ENUM_FLAGS":
+		name, err := p.word()
+		if err != nil {
+			return err
+		}
+		flags := &enum{name: name.text, flags: true}
+		p.header.enums[flags.name] = flags
+		p.header.aliases[flags.name] = "DWORD"
+		p.header.declarations = append(p.header.declarations, flags)
+		return p.expect(";")
+	case "SIMCONNECT_USER_ENUM":
+		name, err := p.word()
+		if err != nil {
+			return err
+		}
+		p.header.aliases[name.text] = "DWORD"
+		return p.expect(";")
+	case "typedef":
+		texts, err := p.until(";")
+		if err != nil {
+			return err
+		}
+		p.pos++
+		if len(texts) >= 2 {
+			p.header.aliases[texts[len(texts)-1]] = strings.Join(texts[:len(texts)-1], " ")
+		}
+		return nil
+	case "static":
+		doc := p.leadingComment(p.pos - 1)
+		c, err := p.parseStaticConst("")
+		if err != nil {
+			return err
+		}
+		c.doc = doc
+		if flags, ok := p.header.enums[c.cType]; ok && flags.flags {
+			flags.members = append(flags.members, *c)
+			return nil
+		}
+		p.header.declarations = append(p.header.declarations, c)
+		return nil
+	case "SIMCONNECT_STRUCT", "SIMCONNECT_REFSTRUCT", "struct":
+		return p.parseStruct()
+	}
+	return nil
+}
+
+// parseStaticConst parses a static const following static, naming it after prefix if it is declared in a struct.
+func (p *headerParser) parseStaticConst(prefix string) (*cConst, error) {
+	if err := p.expect("const"); err != nil {
+		return nil, err
+	}
+	texts, err := p.until("=")
+	if err != nil {
+		return nil, err
+	}
+	if len(texts) < 2 {
+		return nil, p.errorf(p.peek(), "expected the type and name of a constant")
+	}
+	p.pos++
+
+	expr, err := p.until(";")
+	if err != nil {
+		return nil, err
+	}
+	end := p.next()
+
+	name := texts[len(texts)-1]
+	if prefix != "" {
+		name = prefix + "_" + name
+	}
+	return &cConst{
+		name:    name,
+		cType:   strings.Join(texts[:len(texts)-1], " "),
+		expr:    expr,
+		comment: p.trailingComment(end.line),
+	}, nil
+}
+
+func (p *headerParser) parseEnum() error {
+	name, err := p.word()
+	if err != nil {
+		return err
+	}
+	e := &enum{name: name.text}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		p.skipComments()
+		if p.peek().text == "}" {
+			p.pos++
+			break
+		}
+
+		member, err := p.word()
+		if err != nil {
+			return err
+		}
+		c := cConst{name: member.text, cType: e.name}
+		if p.peek().text == "=" {
+			p.pos++
+			if c.expr, err = p.until(",", "}"); err != nil {
+				return err
+			}
+		}
+		if p.peek().text == "," {
+			p.pos++
+		}
+		c.comment = p.trailingComment(member.line)
+		e.members = append(e.members, c)
+	}
+
+	p.header.enums[e.name] = e
+	p.header.declarations = append(p.header.declarations, e)
+	return p.expect(";")
+}
+
+func (p *headerParser) parseStruct() error {
+	name, err := p.word()
+	if err != nil {
+		return err
+	}
+	s := &cStruct{name: name.text}
+
+	p.skipComments()
+	if p.peek().text == ":" {
+		p.pos++
+		base, err := p.until("{")
+		if err != nil {
+			return err
+		}
+		s.base = base[len(base)-1]
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		p.skipComments()
+		if p.peek().text == "}" {
+			p.pos++
+			break
+		}
+
+		if p.peek().text == "static" {
+			p.pos++
+			c, err := p.parseStaticConst(strings.TrimPrefix(s.name, prefix))
+			if err != nil {
+				return err
+			}
+			p.header.declarations = append(p.header.declarations, c)
+			continue
+		}
+
+		start := p.peek()
+		texts, err := p.until(";", "[")
+		if err != nil {
+			return err
+		}
+		if len(texts) < 2 {
+			return p.errorf(start, "expected the type and name of a field of %s", s.name)
+		}
+		f := field{name: texts[len(texts)-1], cType: strings.Join(texts[:len(texts)-1], " ")}
+		if p.peek().text == "[" {
+			p.pos++
+			if f.array, err = p.until("]"); err != nil {
+				return err
+			}
+			p.pos++
+		}
+		end := p.next()
+		if end.text != ";" {
+			return p.errorf(end, "expected \";\" after field %s of %s, found %q", f.name, s.name, end.text)
+		}
+		f.comment = p.trailingComment(end.line)
+
+		// An array of one ending a message holds as many as the message has room for
+		if len(f.array) == 1 && f.array[0] == "1" && s.base != "" {
+			s.variable = f.name
+			continue
+		}
+		s.fields = append(s.fields, f)
+	}
+
+	p.header.structs[s.name] = s
+	p.header.declarations = append(p.header.declarations, s)
+	return p.expect(";")
+}
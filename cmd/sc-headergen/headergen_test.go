@@ -0,0 +1,206 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedFileIsUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..", "simconnect-data")
+	src, err := os.ReadFile(filepath.Join(dir, "SimConnect.h"))
+	require.NoError(t, err)
+	checkedIn, err := os.ReadFile(filepath.Join(dir, "simconnect_gen.go"))
+	require.NoError(t, err)
+
+	generated, err := generate(string(src), dir, filepath.Join(dir, "simconnect_gen.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(checkedIn), string(generated), "run go generate in simconnect-data")
+}
+
+func TestHeaderPath(t *testing.T) {
+	header, err := headerPath("SimConnect.h", "/sdk")
+	require.NoError(t, err)
+	assert.Equal(t, "SimConnect.h", header)
+
+	header, err = headerPath("", "/sdk")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/sdk", "SimConnect SDK", "include", "SimConnect.h"), header)
+
+	_, err = headerPath("", "")
+	assert.EqualError(t, err, "no SimConnect.h given and MSFS_SDK is not set")
+}
+
+const testHeader = `
+#pragma once
+#define SIMCONNECT_ENUM enum
+#define SIMCONNECT_ENUM_FLAGS typedef DWORD
+#define SIMCONNECT_STRUCT struct
+
+typedef DWORD SIMCONNECT_OBJECT_ID;
+
+/* Limits */
+static const DWORD SIMCONNECT_UNUSED = DWORD_MAX;   // special value
+
+// Sizes of things
+static const DWORD SIMCONNECT_SIZE_SMALL = 1 << 2;
+static const DWORD SIMCONNECT_SIZE_NEGATIVE = -3;   // wraps around
+
+SIMCONNECT_ENUM SIMCONNECT_COLOUR {
+    SIMCONNECT_COLOUR_RED,
+    SIMCONNECT_COLOUR_GREEN = 5,     // five
+    SIMCONNECT_COLOUR_BLUE,
+};
+
+SIMCONNECT_ENUM_FLAGS SIMCONNECT_SHAPE_FLAG;
+    static const SIMCONNECT_SHAPE_FLAG SIMCONNECT_SHAPE_FLAG_NONE = 0;
+    static const SIMCONNECT_SHAPE_FLAG SIMCONNECT_SHAPE_FLAG_ROUND = 0x1;
+    static const SIMCONNECT_SHAPE_FLAG SIMCONNECT_SHAPE_FLAG_SQUARE = 0x2;
+
+SIMCONNECT_STRUCT SIMCONNECT_RECV
+{
+    DWORD   dwSize;
+};
+
+SIMCONNECT_STRUCT SIMCONNECT_RECV_THING : public SIMCONNECT_RECV
+{
+    static const DWORD UNKNOWN = 7;
+    SIMCONNECT_OBJECT_ID dwObjectID;   // the thing
+    double  fAltitude;
+    char    szName[8];
+    DWORD   dwItems[1];
+};
+`
+
+const testHandWritten = `package things
+
+type Recv struct {
+	Size uint32
+}
+
+const SIZE_SMALL uint32 = 4
+`
+
+const testGenerated = `// Code generated by sc-headergen from SimConnect.h. DO NOT EDIT.
+
+package things
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	UNUSED uint32 = 0xffffffff // special value
+)
+
+// Sizes of things
+const (
+	SIZE_NEGATIVE uint32 = 0xfffffffd // wraps around
+)
+
+// SIMCONNECT_COLOUR values
+const (
+	COLOUR_RED   uint32 = 0
+	COLOUR_GREEN uint32 = 5 // five
+	COLOUR_BLUE  uint32 = 6
+)
+
+// Colour is SIMCONNECT_COLOUR, giving its uint32 constants a String method.
+type Colour uint32
+
+// String returns the name of the constant with the value of v.
+func (v Colour) String() string {
+	switch uint32(v) {
+	case COLOUR_RED:
+		return "COLOUR_RED"
+	case COLOUR_GREEN:
+		return "COLOUR_GREEN"
+	case COLOUR_BLUE:
+		return "COLOUR_BLUE"
+	}
+	return fmt.Sprintf("Colour(%d)", uint32(v))
+}
+
+// SIMCONNECT_SHAPE_FLAG values
+const (
+	SHAPE_FLAG_NONE   uint32 = 0
+	SHAPE_FLAG_ROUND  uint32 = 0x1
+	SHAPE_FLAG_SQUARE uint32 = 0x2
+)
+
+// ShapeFlag is SIMCONNECT_SHAPE_FLAG, giving its uint32 constants a String method.
+type ShapeFlag uint32
+
+// String returns the names of the flags set in v, joined by |.
+func (v ShapeFlag) String() string {
+	return flagsString(uint32(v), []flagName{
+		{SHAPE_FLAG_NONE, "SHAPE_FLAG_NONE"},
+		{SHAPE_FLAG_ROUND, "SHAPE_FLAG_ROUND"},
+		{SHAPE_FLAG_SQUARE, "SHAPE_FLAG_SQUARE"},
+	})
+}
+
+const (
+	RECV_THING_UNKNOWN uint32 = 7
+)
+
+// RecvThing is SIMCONNECT_RECV_THING.
+type RecvThing struct {
+	Recv
+	ObjectID uint32 // the thing
+	Altitude float64
+	Name     [8]byte
+	// Items follows, its length varying with the message
+}
+`
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "things.go"), []byte(testHandWritten), 0644))
+
+	generated, err := generate(testHeader, dir, filepath.Join(dir, "things_gen.go"))
+	require.NoError(t, err)
+	assert.Equal(t, testGenerated+flagsStringFunc, string(generated))
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "things.go"), []byte(testHandWritten), 0644))
+
+	_, err := generate("SIMCONNECT_ENUM SIMCONNECT_COLOUR { SIMCONNECT_COLOUR_RED = 1", dir, "things_gen.go")
+	assert.EqualError(t, err, `parsing header error: unexpected end of header looking for ["," "}"]`)
+
+	_, err = generate("static const DWORD SIMCONNECT_A = SIMCONNECT_B;", dir, "things_gen.go")
+	assert.Error(t, err)
+
+	// The constants declared by hand must have the values of the header
+	_, err = generate("static const DWORD SIMCONNECT_SIZE_SMALL = 1 << 3;", dir, "things_gen.go")
+	assert.EqualError(t, err, "SIZE_SMALL is 8 in the header but 4 in package things")
+
+	enumDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(enumDir, "things.go"), []byte(`package things
+
+const (
+	COLOUR_RED uint32 = iota
+	COLOUR_BLUE
+)
+`), 0644))
+	_, err = generate(testHeader, enumDir, "things_gen.go")
+	assert.EqualError(t, err, "COLOUR_BLUE is 6 in the header but 1 in package things")
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, "RecvAssignedObjectID", structName("SIMCONNECT_RECV_ASSIGNED_OBJECT_ID"))
+	assert.Equal(t, "MarkerState", structName("SIMCONNECT_DATA_MARKERSTATE"))
+	assert.Equal(t, "FacilityListType", typeName("SIMCONNECT_FACILITY_LIST_TYPE"))
+
+	assert.Equal(t, "ObjectID", fieldName("dwObjectID"))
+	assert.Equal(t, "InstanceID", fieldName("guidInstanceId"))
+	assert.Equal(t, "Altitude", fieldName("fAltitude"))
+	assert.Equal(t, "Flags", fieldName("Flags"))
+	assert.Equal(t, "Ident", fieldName("Ident"))
+}
@@ -0,0 +1,59 @@
+// Command sc-headergen generates the Go constants, enum types and structs of simconnect_data from a SimConnect.h. It
+// leaves out every name the package declares by hand, so the generated file only fills the gaps, and fails if a
+// constant declared by hand has a value other than that of the header. Run it with go generate in simconnect-data:
+//
+//	//go:generate go run ../cmd/sc-headergen -o simconnect_gen.go SimConnect.h
+//
+// Without a header given, it reads the SimConnect.h of the SDK installed at $MSFS_SDK, which the SDK installer sets:
+//
+//	go run ./cmd/sc-headergen -o simconnect-data/simconnect_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	output := flag.String("o", "simconnect_gen.go", "file to write, in the package the constants are generated for")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: sc-headergen [-o file] [SimConnect.h]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	header, err := headerPath(flag.Arg(0), os.Getenv("MSFS_SDK"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := os.ReadFile(header)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	generated, err := generate(string(src), filepath.Dir(*output), *output)
+	if err != nil {
+		log.Fatalf("%s: %v", header, err)
+	}
+	if err := os.WriteFile(*output, generated, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// headerPath returns the header given, or else the SimConnect.h of the SDK installed in sdkDir.
+func headerPath(header, sdkDir string) (string, error) {
+	if header != "" {
+		return header, nil
+	}
+	if sdkDir == "" {
+		return "", fmt.Errorf("no SimConnect.h given and MSFS_SDK is not set")
+	}
+	return filepath.Join(sdkDir, "SimConnect SDK", "include", "SimConnect.h"), nil
+}
@@ -0,0 +1,42 @@
+// Command simconnect-emulator runs a fake simulator speaking the SimConnect network protocol. Point a client at it
+// with NewSimConnectTCP, or with a SimConnect.cfg entry, to run SimConnect code without a simulator.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/JRascagneres/Simconnect-Go/emulator"
+)
+
+func main() {
+	address := flag.String("listen", "127.0.0.1:500", "address to accept SimConnect connections on")
+	name := flag.String("name", "SimConnect Emulator", "application name reported to clients")
+	strict := flag.Bool("strict", false, "reject simvars the emulator does not model")
+	verbose := flag.Bool("v", false, "log every request")
+	flag.Parse()
+
+	config := emulator.Config{
+		ApplicationName:      *name,
+		RejectUnknownSimVars: *strict,
+	}
+	if *verbose {
+		config.Logger = log.New(os.Stderr, "", log.LstdFlags)
+	}
+
+	server, err := emulator.Start(*address, config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("listening on %s", server.Addr())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	<-signals
+
+	if err := server.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
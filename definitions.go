@@ -0,0 +1,349 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// DataDefinition describes a struct registered with the simulator as a data definition.
+type DataDefinition struct {
+	ID     uint32
+	Type   reflect.Type
+	Fields []DataDefinitionField
+}
+
+// DataDefinitionField is one field of a DataDefinition. Err is set when the simulator rejected the field.
+type DataDefinitionField struct {
+	Field    string
+	Name     string
+	Unit     string
+	DataType uint32
+	Err      *FieldError
+}
+
+// FieldError is returned by requests for a struct containing a field the simulator rejected when the struct was
+// registered as a data definition. It unwraps to the *SimConnectError sent by the simulator.
+type FieldError struct {
+	Field string
+	Tag   string
+	Err   *SimConnectError
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %s (tag %s) rejected: %s", e.Field, e.Tag, ExceptionName(e.Err.Exception))
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func (instance *SimconnectInstance) recordDefinitionType(definitionID uint32, definitionType reflect.Type) {
+	instance.definitionMapMutex.Lock()
+	defer instance.definitionMapMutex.Unlock()
+
+	instance.definitionTypes[definitionID] = definitionType
+}
+
+// rejectField records that the field added by packet was rejected with err.
+func (instance *SimconnectInstance) rejectField(packet sentPacket, err *SimConnectError) {
+	instance.definitionMapMutex.Lock()
+	defer instance.definitionMapMutex.Unlock()
+
+	rejected, ok := instance.rejectedFields[packet.definitionID]
+	if !ok {
+		rejected = map[int]*FieldError{}
+		instance.rejectedFields[packet.definitionID] = rejected
+	}
+	rejected[packet.fieldIndex] = &FieldError{Field: packet.fieldName, Tag: packet.tag, Err: err}
+}
+
+// firstFieldError returns the error of the first rejected field in struct order.
+func firstFieldError(rejected map[int]*FieldError) *FieldError {
+	first := -1
+	for index := range rejected {
+		if first == -1 || index < first {
+			first = index
+		}
+	}
+	return rejected[first]
+}
+
+var recvSimobjectDataByType = reflect.TypeOf(simconnect_data.RecvSimobjectDataByType{})
+
+// recvSimobjectDataSize is the size of the header preceding the fields of a definition in the data sent by the
+// simulator.
+var recvSimobjectDataSize = int(recvSimobjectDataByType.Size())
+
+// firstDefinitionField returns the index of the first simvar field of t, skipping a leading embedded
+// RecvSimobjectDataByType which is filled in from the header instead.
+func firstDefinitionField(t reflect.Type) int {
+	if t.NumField() > 0 && t.Field(0).Anonymous && t.Field(0).Type == recvSimobjectDataByType {
+		return 1
+	}
+	return 0
+}
+
+// decodeHeader returns the header of data received for a definition, by object or by type.
+func decodeHeader(data []byte) (simconnect_data.RecvSimobjectDataByType, error) {
+	recv, err := simconnect_data.DecodeRecv(data)
+	if err != nil {
+		return simconnect_data.RecvSimobjectDataByType{}, err
+	}
+	if recv.ID == simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE {
+		header, err := simconnect_data.DecodeRecvSimobjectDataByType(data)
+		if err != nil {
+			return simconnect_data.RecvSimobjectDataByType{}, err
+		}
+		return *header, nil
+	}
+
+	header, err := simconnect_data.DecodeRecvSimobjectData(data)
+	if err != nil {
+		return simconnect_data.RecvSimobjectDataByType{}, err
+	}
+	return simconnect_data.RecvSimobjectDataByType{RecvSimobjectData: *header}, nil
+}
+
+// tagDataTypes maps the values accepted by the type tag to the SIMCONNECT_DATATYPE they register a field as.
+var tagDataTypes = map[string]uint32{
+	"int32":        simconnect_data.DATATYPE_INT32,
+	"int64":        simconnect_data.DATATYPE_INT64,
+	"float32":      simconnect_data.DATATYPE_FLOAT32,
+	"float64":      simconnect_data.DATATYPE_FLOAT64,
+	"string8":      simconnect_data.DATATYPE_STRING8,
+	"string32":     simconnect_data.DATATYPE_STRING32,
+	"string64":     simconnect_data.DATATYPE_STRING64,
+	"string128":    simconnect_data.DATATYPE_STRING128,
+	"string256":    simconnect_data.DATATYPE_STRING256,
+	"string260":    simconnect_data.DATATYPE_STRING260,
+	"stringv":      simconnect_data.DATATYPE_STRINGV,
+	"initposition": simconnect_data.DATATYPE_INITPOSITION,
+	"markerstate":  simconnect_data.DATATYPE_MARKERSTATE,
+	"waypoint":     simconnect_data.DATATYPE_WAYPOINT,
+	"latlonalt":    simconnect_data.DATATYPE_LATLONALT,
+	"xyz":          simconnect_data.DATATYPE_XYZ,
+}
+
+// parseDefinitionFields reads the fields of t to register from their tags: name and unit of the simvar, the unit
+// defaulting to the one in the catalog, and optionally the epsilon below which changes are not sent, the datum ID
+// identifying it in tagged data and the type overriding the one implied by the Go type of the field.
+func parseDefinitionFields(t reflect.Type) ([]definitionField, error) {
+	var fields []definitionField
+	for j := firstDefinitionField(t); j < t.NumField(); j++ {
+		structField := t.Field(j)
+		field := definitionField{
+			fieldIndex: j,
+			fieldName:  structField.Name,
+			datumID:    simconnect_data.UNUSED,
+		}
+		field.name, _ = structField.Tag.Lookup("name")
+		unit, hasUnit := structField.Tag.Lookup("unit")
+
+		if field.name == "" {
+			return nil, fmt.Errorf("name tag not found %s", structField.Name)
+		}
+
+		field.unit = unit
+		// Without a unit tag the simvar is requested in the unit the catalog documents it in
+		if simVar, ok := LookupSimVar(field.name); ok && !hasUnit {
+			field.unit = simVar.Unit
+		}
+
+		if typeTag, ok := structField.Tag.Lookup("type"); ok {
+			dataType, ok := tagDataTypes[strings.ToLower(typeTag)]
+			if !ok {
+				return nil, fmt.Errorf("unknown type tag %q on field %s", typeTag, structField.Name)
+			}
+			if !canHoldDataType(structField.Type, dataType) {
+				return nil, fmt.Errorf("field %s of type %s can not hold type %s", structField.Name, structField.Type, typeTag)
+			}
+			field.dataType = dataType
+		} else {
+			dataType, err := fieldDataType(structField.Type)
+			if err != nil {
+				return nil, fmt.Errorf("error derefing datatype: %v", err)
+			}
+			field.dataType = dataType
+		}
+
+		if epsilonTag, ok := structField.Tag.Lookup("epsilon"); ok {
+			epsilon, err := strconv.ParseFloat(epsilonTag, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid epsilon tag %q on field %s: %v", epsilonTag, structField.Name, err)
+			}
+			field.epsilon = float32(epsilon)
+		}
+
+		if datumTag, ok := structField.Tag.Lookup("datum"); ok {
+			datumID, err := strconv.ParseUint(datumTag, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid datum tag %q on field %s: %v", datumTag, structField.Name, err)
+			}
+			field.datumID = uint32(datumID)
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// decodeDefinition decodes the data received for a definition registered with fields into value, leaving the fields
+// in skip out as the simulator does.
+func decodeDefinition(data []byte, value reflect.Value, fields []definitionField, skip map[int]*FieldError) error {
+	header, err := decodeHeader(data)
+	if err != nil {
+		return err
+	}
+
+	if firstDefinitionField(value.Type()) == 1 {
+		value.Field(0).Set(reflect.ValueOf(header))
+	}
+
+	offset := recvSimobjectDataSize
+	if header.Flags&simconnect_data.DATA_REQUEST_FLAG_TAGGED != 0 {
+		return decodeTagged(data[offset:], value, fields, header.DefineCount)
+	}
+
+	for _, field := range fields {
+		if _, ok := skip[field.fieldIndex]; ok {
+			continue
+		}
+
+		n, err := decodeField(data[offset:], value.Field(field.fieldIndex), field.dataType)
+		if err != nil {
+			return fmt.Errorf("error decoding field %s: %v", field.fieldName, err)
+		}
+		offset += n
+	}
+
+	return nil
+}
+
+// decodeTagged decodes data sent with DATA_REQUEST_FLAG_TAGGED, where only count fields are sent, each preceded by
+// its datum ID.
+func decodeTagged(data []byte, value reflect.Value, fields []definitionField, count uint32) error {
+	offset := 0
+	for i := uint32(0); i < count; i++ {
+		if offset+4 > len(data) {
+			return fmt.Errorf("data too short for datum %d of %d", i+1, count)
+		}
+		datumID := binary.LittleEndian.Uint32(data[offset:])
+		offset += 4
+
+		field, ok := fieldByDatumID(fields, datumID)
+		if !ok {
+			return fmt.Errorf("received unknown datum ID %d", datumID)
+		}
+
+		n, err := decodeField(data[offset:], value.Field(field.fieldIndex), field.dataType)
+		if err != nil {
+			return fmt.Errorf("error decoding field %s: %v", field.fieldName, err)
+		}
+		offset += n
+	}
+
+	return nil
+}
+
+func fieldByDatumID(fields []definitionField, datumID uint32) (definitionField, bool) {
+	for _, field := range fields {
+		if field.datumID == datumID {
+			return field, true
+		}
+	}
+	return definitionField{}, false
+}
+
+// encodeDefinition encodes value as the data of its definition, registered with fields, for SetDataOnSimObject,
+// leaving the fields in skip out.
+func encodeDefinition(value reflect.Value, fields []definitionField, skip map[int]*FieldError) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	for _, field := range fields {
+		if _, ok := skip[field.fieldIndex]; ok {
+			continue
+		}
+
+		if err := encodeField(buf, value.Field(field.fieldIndex), field.dataType); err != nil {
+			return nil, fmt.Errorf("error encoding field %s: %v", field.fieldName, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// DataDefinitions returns every data definition registered so far, ordered by ID.
+func (instance *SimconnectInstance) DataDefinitions() []DataDefinition {
+	instance.definitionMapMutex.Lock()
+	defer instance.definitionMapMutex.Unlock()
+
+	definitions := make([]DataDefinition, 0, len(instance.definitionMap))
+	for definitionType, definitionID := range instance.definitionMap {
+		definitions = append(definitions, instance.dataDefinition(definitionType, definitionID))
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].ID < definitions[j].ID })
+
+	return definitions
+}
+
+// DataDefinition returns the data definition registered for definitionType, the struct type rather than a pointer to
+// it.
+func (instance *SimconnectInstance) DataDefinition(definitionType reflect.Type) (DataDefinition, bool) {
+	instance.definitionMapMutex.Lock()
+	defer instance.definitionMapMutex.Unlock()
+
+	definitionID, ok := instance.definitionMap[definitionType]
+	if !ok {
+		return DataDefinition{}, false
+	}
+	return instance.dataDefinition(definitionType, definitionID), true
+}
+
+// dataDefinition must be called with definitionMapMutex held.
+func (instance *SimconnectInstance) dataDefinition(definitionType reflect.Type, definitionID uint32) DataDefinition {
+	definition := DataDefinition{ID: definitionID, Type: definitionType}
+	for _, field := range instance.definitionFields[definitionID] {
+		definition.Fields = append(definition.Fields, DataDefinitionField{
+			Field:    field.fieldName,
+			Name:     field.name,
+			Unit:     field.unit,
+			DataType: field.dataType,
+			Err:      instance.rejectedFields[definitionID][field.fieldIndex],
+		})
+	}
+	return definition
+}
+
+// ClearDataDefinition removes the data definition registered for definitionType from the simulator. It is
+// registered again, under a new ID, the next time it is used.
+func (instance *SimconnectInstance) ClearDataDefinition(definitionType reflect.Type) error {
+	instance.definitionMapMutex.Lock()
+	definitionID, ok := instance.definitionMap[definitionType]
+	instance.definitionMapMutex.Unlock()
+	if !ok {
+		return fmt.Errorf("no data definition registered for %s", definitionType)
+	}
+
+	err := instance.send(sentPacket{method: "ClearDataDefinition"}, func(transport Transport) error {
+		return transport.ClearDataDefinition(definitionID)
+	})
+	if err != nil {
+		return err
+	}
+
+	instance.definitionMapMutex.Lock()
+	defer instance.definitionMapMutex.Unlock()
+
+	delete(instance.definitionMap, definitionType)
+	delete(instance.definitionFields, definitionID)
+	delete(instance.definitionTypes, definitionID)
+	delete(instance.rejectedFields, definitionID)
+	delete(instance.settableChecked, definitionID)
+	return nil
+}
@@ -0,0 +1,391 @@
+package simconnect
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+type misspeltReport struct {
+	Altitude      float64 `name:"Plane Altitude" unit:"feet"`
+	APSelectedAlt float64 `name:"AUTOPILOT ALTITUDE LOCK VR:3" unit:"feet"`
+	Heading       float64 `name:"Plane Heading Degrees True" unit:"degrees"`
+}
+
+// newMisspeltFake returns a FakeTransport rejecting the misspelt simvar of misspeltReport and leaving it out of the
+// data it returns, as the simulator does.
+func newMisspeltFake() *FakeTransport {
+	fake := NewFakeTransport()
+	fake.On("AddToDataDefinition", func(fake *FakeTransport, call FakeCall) error {
+		if call.Args[1] != "AUTOPILOT ALTITUDE LOCK VR:3" {
+			return nil
+		}
+		return fake.Queue(simconnect_data.RECV_ID_EXCEPTION, &simconnect_data.RecvException{
+			Exception: simconnect_data.EXCEPTION_NAME_UNRECOGNIZED,
+			SendID:    call.SendID,
+			Index:     2,
+		})
+	})
+	fake.On("RequestDataOnSimObjectType", func(fake *FakeTransport, call FakeCall) error {
+		data := struct {
+			simconnect_data.RecvSimobjectDataByType
+			Altitude float64
+			Heading  float64
+		}{Altitude: 3500, Heading: 270}
+		data.RequestID = call.Args[0].(uint32)
+		data.DefineID = call.Args[1].(uint32)
+		return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, &data)
+	})
+	return fake
+}
+
+func requestMisspeltReport(t *testing.T, instance *SimconnectInstance) (*misspeltReport, error) {
+	report := &misspeltReport{}
+	require.NoError(t, instance.registerDataDefinition(report))
+	definitionID, _ := instance.getDefinitionID(report)
+
+	requestID := instance.newRequestID()
+	data, err := instance.request(context.Background(), requestID, func() error {
+		return instance.requestDataOnSimObjectType(requestID, definitionID, 0, simconnect_data.SIMOBJECT_TYPE_USER)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.(*misspeltReport), nil
+}
+
+func TestRejectedField(t *testing.T) {
+	instance, err := NewSimConnectWithTransport(t.Name(), newMisspeltFake())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	_, err = requestMisspeltReport(t, instance)
+	assert.EqualError(t, err, "field APSelectedAlt (tag AUTOPILOT ALTITUDE LOCK VR:3) rejected: NAME_UNRECOGNIZED")
+	assert.True(t, errors.Is(err, ErrNameUnrecognized))
+
+	var fieldErr *FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "APSelectedAlt", fieldErr.Field)
+	assert.Equal(t, "AddToDataDefinition", fieldErr.Err.Method)
+}
+
+func TestDropRejectedField(t *testing.T) {
+	instance, err := NewSimConnectWithTransport(t.Name(), newMisspeltFake(), WithDropRejectedFields())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	report, err := requestMisspeltReport(t, instance)
+	require.NoError(t, err)
+	assert.Equal(t, float64(3500), report.Altitude)
+	assert.Equal(t, float64(0), report.APSelectedAlt)
+	assert.Equal(t, float64(270), report.Heading)
+}
+
+func TestRejectedFirstField(t *testing.T) {
+	// Without an embedded header the first simvar is field 0
+	type firstMisspelt struct {
+		Altitude float64 `name:"Plane Altitde" unit:"feet"`
+		Heading  float64 `name:"Plane Heading Degrees True" unit:"degrees"`
+	}
+
+	newFake := func() *FakeTransport {
+		fake := NewFakeTransport()
+		fake.On("AddToDataDefinition", func(fake *FakeTransport, call FakeCall) error {
+			if call.Args[1] != "Plane Altitde" {
+				return nil
+			}
+			return fake.Queue(simconnect_data.RECV_ID_EXCEPTION, &simconnect_data.RecvException{
+				Exception: simconnect_data.EXCEPTION_NAME_UNRECOGNIZED,
+				SendID:    call.SendID,
+				Index:     1,
+			})
+		})
+		fake.On("RequestDataOnSimObject", func(fake *FakeTransport, call FakeCall) error {
+			data := struct {
+				simconnect_data.RecvSimobjectDataByType
+				Heading float64
+			}{Heading: 270}
+			data.RequestID = call.Args[0].(uint32)
+			data.DefineID = call.Args[1].(uint32)
+			return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA, &data)
+		})
+		return fake
+	}
+
+	instance, err := NewSimConnectWithTransport(t.Name(), newFake())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	_, err = Get[firstMisspelt](instance, simconnect_data.OBJECT_ID_USER)
+	assert.EqualError(t, err, "field Altitude (tag Plane Altitde) rejected: NAME_UNRECOGNIZED")
+	definition, ok := instance.DataDefinition(reflect.TypeOf(firstMisspelt{}))
+	require.True(t, ok)
+	require.NotNil(t, definition.Fields[0].Err)
+	assert.Equal(t, "Altitude", definition.Fields[0].Err.Field)
+	assert.Nil(t, definition.Fields[1].Err)
+
+	dropping, err := NewSimConnectWithTransport(t.Name(), newFake(), WithDropRejectedFields())
+	require.NoError(t, err)
+	defer dropping.Close()
+
+	report, err := Get[firstMisspelt](dropping, simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), report.Altitude)
+	assert.Equal(t, float64(270), report.Heading)
+}
+
+func TestDefinitionsKeyedByType(t *testing.T) {
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	// Shares its name with the package Report but not its fields
+	type Report struct {
+		simconnect_data.RecvSimobjectDataByType
+		Latitude float64 `name:"Plane Latitude" unit:"degrees"`
+		Altitude float64 `name:"Plane Altitude" unit:"feet"`
+	}
+
+	local, err := GetByType[Report](instance, simconnect_data.SIMOBJECT_TYPE_USER)
+	require.NoError(t, err)
+	report, err := instance.GetReport()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, local.DefineID, report.DefineID)
+	assert.Equal(t, report.Altitude, local.Altitude)
+	assert.Equal(t, report.Latitude, local.Latitude)
+}
+
+func TestDataDefinitionRegistry(t *testing.T) {
+	fake := newMisspeltFake()
+	instance, err := NewSimConnectWithTransport(t.Name(), fake, WithDropRejectedFields())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	_, err = requestMisspeltReport(t, instance)
+	require.NoError(t, err)
+
+	reportType := reflect.TypeOf(misspeltReport{})
+	definition, ok := instance.DataDefinition(reportType)
+	require.True(t, ok)
+	assert.Equal(t, reportType, definition.Type)
+	require.Len(t, definition.Fields, 3)
+	assert.Equal(t, DataDefinitionField{
+		Field:    "Altitude",
+		Name:     "Plane Altitude",
+		Unit:     "feet",
+		DataType: simconnect_data.DATATYPE_FLOAT64,
+	}, definition.Fields[0])
+	if assert.NotNil(t, definition.Fields[1].Err) {
+		assert.Equal(t, "APSelectedAlt", definition.Fields[1].Err.Field)
+	}
+	assert.Equal(t, []DataDefinition{definition}, instance.DataDefinitions())
+
+	require.NoError(t, instance.ClearDataDefinition(reportType))
+	calls := fake.CallsTo("ClearDataDefinition")
+	require.Len(t, calls, 1)
+	assert.Equal(t, definition.ID, calls[0].Args[0])
+	assert.Empty(t, instance.DataDefinitions())
+	assert.Error(t, instance.ClearDataDefinition(reportType))
+
+	// The next use registers it again under a new ID
+	_, err = requestMisspeltReport(t, instance)
+	require.NoError(t, err)
+	registered, ok := instance.DataDefinition(reportType)
+	require.True(t, ok)
+	assert.NotEqual(t, definition.ID, registered.ID)
+}
+
+type headingReport struct {
+	Altitude float64 `name:"Plane Altitude" unit:"feet"`
+	Heading  float64 `name:"Plane Heading Degrees True" unit:"degrees"`
+	Latitude float64 `name:"Plane Latitude" unit:"degrees"`
+}
+
+func newHeadingFake() *FakeTransport {
+	fake := NewFakeTransport()
+	fake.On("RequestDataOnSimObjectType", func(fake *FakeTransport, call FakeCall) error {
+		data := struct {
+			simconnect_data.RecvSimobjectDataByType
+			headingReport
+		}{headingReport: headingReport{Altitude: 3500, Heading: 270, Latitude: 53}}
+		data.RequestID = call.Args[0].(uint32)
+		data.DefineID = call.Args[1].(uint32)
+		return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, &data)
+	})
+	return fake
+}
+
+func TestDefinitionRegisteredOnce(t *testing.T) {
+	fake := newHeadingFake()
+	fake.On("AddToDataDefinition", func(fake *FakeTransport, call FakeCall) error {
+		// Leaves time for the other calls to find the definition half registered
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	respond := fake.handlers["RequestDataOnSimObjectType"]
+	fake.On("RequestDataOnSimObjectType", func(fake *FakeTransport, call FakeCall) error {
+		fields := 0
+		for _, added := range fake.CallsTo("AddToDataDefinition") {
+			if added.Args[0] == call.Args[1] {
+				fields++
+			}
+		}
+		if fields != 3 {
+			return fmt.Errorf("request made with %d fields of definition %d", fields, call.Args[1])
+		}
+		return respond(fake, call)
+	})
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report, err := GetByType[headingReport](instance, simconnect_data.SIMOBJECT_TYPE_USER)
+			if assert.NoError(t, err) {
+				assert.Equal(t, headingReport{Altitude: 3500, Heading: 270, Latitude: 53}, *report)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, fake.CallsTo("AddToDataDefinition"), 3)
+}
+
+func TestDefinitionRegistrationFailed(t *testing.T) {
+	fake := newHeadingFake()
+	failed := false
+	fake.On("AddToDataDefinition", func(fake *FakeTransport, call FakeCall) error {
+		if call.Args[1] == "Plane Heading Degrees True" && !failed {
+			failed = true
+			return errors.New("add failed")
+		}
+		return nil
+	})
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	_, err = GetByType[headingReport](instance, simconnect_data.SIMOBJECT_TYPE_USER)
+	assert.EqualError(t, err, "error adding data definition: add failed")
+
+	// The half registered definition is cleared rather than used
+	calls := fake.CallsTo("ClearDataDefinition")
+	require.Len(t, calls, 1)
+	assert.Equal(t, fake.CallsTo("AddToDataDefinition")[0].Args[0], calls[0].Args[0])
+	assert.Empty(t, instance.DataDefinitions())
+
+	// and registered again in full by the next call
+	report, err := GetByType[headingReport](instance, simconnect_data.SIMOBJECT_TYPE_USER)
+	require.NoError(t, err)
+	assert.Equal(t, headingReport{Altitude: 3500, Heading: 270, Latitude: 53}, *report)
+	definitions := instance.DataDefinitions()
+	require.Len(t, definitions, 1)
+	assert.Len(t, definitions[0].Fields, 3)
+	assert.Len(t, fake.CallsTo("AddToDataDefinition"), 5)
+}
+
+type taggedReport struct {
+	Altitude float64 `name:"Plane Altitude" unit:"feet" epsilon:"0.5" datum:"12" type:"float32"`
+	Heading  float64 `name:"Plane Heading Degrees True" unit:"degrees" datum:"13"`
+	Engines  int     `name:"Number Of Engines" unit:"number" datum:"14" type:"int32"`
+	ATCID    string  `name:"ATC ID" datum:"15" type:"string8"`
+}
+
+func TestDefinitionTags(t *testing.T) {
+	fake := NewFakeTransport()
+	fake.On("RequestDataOnSimObject", func(fake *FakeTransport, call FakeCall) error {
+		data := struct {
+			simconnect_data.RecvSimobjectDataByType
+			Altitude float32
+			Heading  float64
+			Engines  int32
+			ATCID    [8]byte
+		}{Altitude: 3500.5, Heading: 270, Engines: 2}
+		copy(data.ATCID[:], "N172SP")
+		data.RequestID = call.Args[0].(uint32)
+		data.DefineID = call.Args[1].(uint32)
+		return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA, &data)
+	})
+
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	report, err := Get[taggedReport](instance, simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	assert.Equal(t, taggedReport{Altitude: 3500.5, Heading: 270, Engines: 2, ATCID: "N172SP"}, *report)
+
+	calls := fake.CallsTo("AddToDataDefinition")
+	require.Len(t, calls, 4)
+	assert.Equal(t, []interface{}{calls[0].Args[0], "Plane Altitude", "feet", simconnect_data.DATATYPE_FLOAT32, float32(0.5), uint32(12)}, calls[0].Args)
+	assert.Equal(t, []interface{}{calls[0].Args[0], "Plane Heading Degrees True", "degrees", simconnect_data.DATATYPE_FLOAT64, float32(0), uint32(13)}, calls[1].Args)
+	assert.Equal(t, simconnect_data.DATATYPE_INT32, calls[2].Args[3])
+	assert.Equal(t, simconnect_data.DATATYPE_STRING8, calls[3].Args[3])
+}
+
+func TestDecodeTagged(t *testing.T) {
+	fields, err := parseDefinitionFields(reflect.TypeOf(taggedReport{}))
+	require.NoError(t, err)
+
+	// Only the heading and engines are sent, in the order the simulator chooses
+	data := struct {
+		simconnect_data.RecvSimobjectDataByType
+		EnginesDatum uint32
+		Engines      int32
+		HeadingDatum uint32
+		Heading      float64
+	}{EnginesDatum: 14, Engines: 4, HeadingDatum: 13, Heading: 90}
+	data.Size = uint32(binary.Size(data))
+	data.ID = simconnect_data.RECV_ID_SIMOBJECT_DATA
+	data.Flags = simconnect_data.DATA_REQUEST_FLAG_TAGGED
+	data.DefineCount = 2
+	buf := &bytes.Buffer{}
+	require.NoError(t, binary.Write(buf, binary.LittleEndian, &data))
+
+	report := taggedReport{Altitude: 1000}
+	require.NoError(t, decodeDefinition(buf.Bytes(), reflect.ValueOf(&report).Elem(), fields, nil))
+	assert.Equal(t, taggedReport{Altitude: 1000, Heading: 90, Engines: 4}, report)
+
+	data.HeadingDatum = 99
+	buf.Reset()
+	require.NoError(t, binary.Write(buf, binary.LittleEndian, &data))
+	assert.EqualError(t, decodeDefinition(buf.Bytes(), reflect.ValueOf(&report).Elem(), fields, nil), "received unknown datum ID 99")
+}
+
+func TestInvalidDefinitionTags(t *testing.T) {
+	_, err := parseDefinitionFields(reflect.TypeOf(struct {
+		Altitude float64 `name:"Plane Altitude" unit:"feet" type:"float16"`
+	}{}))
+	assert.EqualError(t, err, `unknown type tag "float16" on field Altitude`)
+
+	_, err = parseDefinitionFields(reflect.TypeOf(struct {
+		Title string `name:"Title" type:"float32"`
+	}{}))
+	assert.EqualError(t, err, "field Title of type string can not hold type float32")
+
+	_, err = parseDefinitionFields(reflect.TypeOf(struct {
+		Altitude float64 `name:"Plane Altitude" unit:"feet" epsilon:"small"`
+	}{}))
+	assert.Error(t, err)
+
+	_, err = parseDefinitionFields(reflect.TypeOf(struct {
+		Altitude float64 `name:"Plane Altitude" unit:"feet" datum:"-1"`
+	}{}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,498 @@
+package simconnect
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// dispatchPollInterval is how long the dispatcher sleeps when GetNextDispatch has nothing to return.
+const dispatchPollInterval = 10 * time.Millisecond
+
+// DefaultTimeout is how long a caller waits for the response to a request when neither the context passed in nor
+// SetDefaultTimeout say otherwise.
+const DefaultTimeout = 2 * time.Second
+
+// firstInternalRequestID is the first request ID handed out by newRequestID. Request IDs chosen by callers, such as
+// those passed to LoadParkedATCAircraft, are expected to be below it.
+const firstInternalRequestID uint32 = 0x10000
+
+// firstInternalEventID is the first client event ID handed out by newEventID. Event IDs chosen by callers, such as
+// those passed to SubscribeToSystemEvent, are expected to be below it.
+const firstInternalEventID uint32 = 0x10000
+
+// maxSentPackets is the number of sent packets remembered to match exceptions against.
+const maxSentPackets = 1024
+
+// listenerBufferSize is the number of messages buffered for a listener before further messages are dropped.
+const listenerBufferSize = 64
+
+// objectsBufferSize is the number of messages buffered for a request returning several objects, enough for the
+// objects within the largest radius the simulator allows.
+const objectsBufferSize = 1024
+
+var errConnectionClosed = errors.New("connection closed")
+
+// dispatchResult is a message delivered by the dispatcher, or the error which stopped it.
+type dispatchResult struct {
+	data []byte
+	err  error
+}
+
+// listener receives messages matching either an event ID (when events is set) or a receive ID, or every message
+// when all is set.
+type listener struct {
+	all       bool
+	events    bool
+	allEvents bool
+	id        uint32
+	results   chan dispatchResult
+	// dropped counts the messages not delivered as results was full, guarded by dispatchMutex
+	dropped uint64
+}
+
+// sentPacket describes a packet sent to the simulator, so the exception it may cause can be matched to it. Packets
+// adding a field to a data definition have field set, fieldIndex being the index of the struct field, which may be 0.
+type sentPacket struct {
+	method    string
+	request   bool
+	requestID uint32
+
+	field        bool
+	definitionID uint32
+	fieldIndex   int
+	fieldName    string
+	tag          string
+}
+
+// recvRequestIDs lists the messages whose body starts with the request ID they answer.
+var recvRequestIDs = map[uint32]bool{
+	simconnect_data.RECV_ID_SIMOBJECT_DATA:        true,
+	simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE: true,
+	simconnect_data.RECV_ID_ASSIGNED_OBJECT_ID:    true,
+	simconnect_data.RECV_ID_SYSTEM_STATE:          true,
+	simconnect_data.RECV_ID_CLIENT_DATA:           true,
+	simconnect_data.RECV_ID_WEATHER_OBSERVATION:   true,
+	simconnect_data.RECV_ID_CLOUD_STATE:           true,
+	simconnect_data.RECV_ID_AIRPORT_LIST:          true,
+	simconnect_data.RECV_ID_VOR_LIST:              true,
+	simconnect_data.RECV_ID_NDB_LIST:              true,
+	simconnect_data.RECV_ID_WAYPOINT_LIST:         true,
+}
+
+// call runs fn with exclusive use of the transport, which is shared with the dispatcher.
+func (instance *SimconnectInstance) call(fn func(transport Transport) error) error {
+	instance.transportMutex.Lock()
+	defer instance.transportMutex.Unlock()
+
+	return fn(instance.transport)
+}
+
+// unlessStopped wraps fn so it is skipped, returning the error which stopped the instance, once the instance has
+// stopped. Close only closes the transport once the dispatcher has stopped, so run with the transport locked by call
+// or send, fn is only called while the transport is still open.
+func (instance *SimconnectInstance) unlessStopped(fn func(transport Transport) error) func(transport Transport) error {
+	return func(transport Transport) error {
+		instance.dispatchMutex.Lock()
+		stopped, err := instance.stopped, instance.dispatchErr
+		instance.dispatchMutex.Unlock()
+		if stopped {
+			return err
+		}
+
+		return fn(transport)
+	}
+}
+
+// send runs fn like call and remembers the packet it sent under the send ID reported by the transport. fn is skipped,
+// returning the error which stopped or interrupted the instance, while it is stopped or disconnected, as the transport
+// may then be closed or being replaced.
+func (instance *SimconnectInstance) send(packet sentPacket, fn func(transport Transport) error) error {
+	instance.transportMutex.Lock()
+	defer instance.transportMutex.Unlock()
+
+	instance.dispatchMutex.Lock()
+	err := instance.dispatchErr
+	instance.dispatchMutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return instance.sendOn(instance.transport, packet, fn)
+}
+
+// sendOn is send on a transport already locked, whatever the state of the instance.
+func (instance *SimconnectInstance) sendOn(transport Transport, packet sentPacket, fn func(transport Transport) error) error {
+	if err := fn(transport); err != nil {
+		return err
+	}
+
+	sendID, err := transport.GetLastSentPacketID()
+	if err != nil {
+		// Exceptions caused by this packet will not be matched to it, which is no reason to fail the call
+		return nil
+	}
+
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	instance.sentPackets[sendID] = packet
+	delete(instance.sentPackets, sendID-maxSentPackets)
+	return nil
+}
+
+// newRequestID returns a request ID not used by any other pending request.
+func (instance *SimconnectInstance) newRequestID() uint32 {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	requestID := instance.nextRequestID
+	instance.nextRequestID++
+	return requestID
+}
+
+// startDispatcher starts the goroutine reading every message from the transport.
+func (instance *SimconnectInstance) startDispatcher() {
+	instance.dispatcherDone = make(chan struct{})
+	instance.stopDispatcher = make(chan struct{})
+
+	go instance.dispatch()
+}
+
+// newEventID returns a client event ID not used by the library for anything else.
+func (instance *SimconnectInstance) newEventID() uint32 {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	eventID := instance.nextEventID
+	instance.nextEventID++
+	return eventID
+}
+
+func (instance *SimconnectInstance) dispatch() {
+	defer close(instance.dispatcherDone)
+
+	for {
+		select {
+		case <-instance.stopDispatcher:
+			instance.fail(errConnectionClosed)
+			return
+		default:
+		}
+
+		var data []byte
+		err := instance.call(func(transport Transport) error {
+			message, err := transport.GetNextDispatch()
+			// The message may live in a buffer owned by the transport which is reused by the next call
+			data = append([]byte(nil), message...)
+			return err
+		})
+		if err != nil {
+			if !instance.disconnected(err) {
+				return
+			}
+			continue
+		}
+
+		if len(data) == 0 {
+			time.Sleep(dispatchPollInterval)
+			continue
+		}
+
+		data, err = checkRecvSize(data)
+		if err != nil {
+			// Nothing can be routed without a whole message
+			continue
+		}
+
+		if !instance.route(data) && !instance.disconnected(errSimulatorQuit) {
+			return
+		}
+	}
+}
+
+// recvID returns the ID of the message in data, which must hold at least a Recv header.
+func recvID(data []byte) uint32 {
+	return binary.LittleEndian.Uint32(data[8:])
+}
+
+// checkRecvSize returns the message in data cut to the size given in its Recv header, or an error when data does not
+// hold all of it.
+func checkRecvSize(data []byte) ([]byte, error) {
+	recv, err := simconnect_data.DecodeRecv(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:recv.Size], nil
+}
+
+// route delivers a message to whoever is waiting for it. It returns false once the simulator has quit.
+func (instance *SimconnectInstance) route(data []byte) bool {
+	id := recvID(data)
+	instance.routeAll(data)
+
+	switch {
+	case id == simconnect_data.RECV_ID_EXCEPTION:
+		instance.routeException(data)
+	case id == simconnect_data.RECV_ID_QUIT:
+		instance.routeListeners(id, false, data)
+		return false
+	case recvRequestIDs[id] && len(data) >= 16:
+		instance.routeRequest(binary.LittleEndian.Uint32(data[12:]), data)
+	case simconnect_data.IsRecvEvent(id) && len(data) >= 20:
+		instance.routeListeners(binary.LittleEndian.Uint32(data[16:]), true, data)
+	default:
+		instance.routeListeners(id, false, data)
+	}
+
+	return true
+}
+
+func (instance *SimconnectInstance) routeRequest(requestID uint32, data []byte) {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	if waiter, ok := instance.requestWaiters[requestID]; ok && !deliver(waiter, dispatchResult{data: data}) {
+		instance.overflowed[requestID] = true
+	}
+}
+
+func (instance *SimconnectInstance) routeListeners(id uint32, events bool, data []byte) {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	for l := range instance.listeners {
+		if l.all || l.events != events {
+			continue
+		}
+		if l.allEvents || l.id == id {
+			deliver(l.results, dispatchResult{data: data})
+		}
+	}
+}
+
+// routeAll passes every message on to the listeners for all of them.
+func (instance *SimconnectInstance) routeAll(data []byte) {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	for l := range instance.listeners {
+		if l.all && !deliver(l.results, dispatchResult{data: data}) {
+			l.dropped++
+		}
+	}
+}
+
+// routeException fails the pending request whose packet caused the exception, if any, and passes the exception on to
+// the listeners for RECV_ID_EXCEPTION.
+func (instance *SimconnectInstance) routeException(data []byte) {
+	exception := simconnect_data.RecvException{}
+	if decoded, err := simconnect_data.DecodeRecvException(data); err == nil {
+		exception = *decoded
+	}
+	err := newSimConnectError(exception)
+
+	instance.dispatchMutex.Lock()
+	packet, ok := instance.sentPackets[exception.SendID]
+	if ok {
+		err.Method = packet.method
+		if waiter, waiting := instance.requestWaiters[packet.requestID]; waiting && packet.request {
+			deliver(waiter, dispatchResult{err: err})
+		}
+	}
+	instance.dispatchMutex.Unlock()
+
+	if ok && packet.field {
+		instance.rejectField(packet, err)
+	}
+
+	instance.routeListeners(simconnect_data.RECV_ID_EXCEPTION, false, data)
+}
+
+// exceptionError returns the error for exception, naming the call which caused it while that is still remembered.
+func (instance *SimconnectInstance) exceptionError(exception simconnect_data.RecvException) *SimConnectError {
+	err := newSimConnectError(exception)
+
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	if packet, ok := instance.sentPackets[exception.SendID]; ok {
+		err.Method = packet.method
+	}
+	return err
+}
+
+// fail stops every pending request and listener with err as the dispatcher stops, closing their channels once err is
+// delivered so their receivers are sure to end. Later requests fail immediately.
+func (instance *SimconnectInstance) fail(err error) {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	if instance.stopped {
+		return
+	}
+	instance.stopped = true
+	instance.dispatchErr = err
+
+	for requestID, waiter := range instance.requestWaiters {
+		stopResults(waiter, err)
+		delete(instance.requestWaiters, requestID)
+	}
+	for l := range instance.listeners {
+		stopResults(l.results, err)
+		delete(instance.listeners, l)
+	}
+
+	instance.notifyState(instance.finalState())
+	for watcher := range instance.stateWatchers {
+		close(watcher)
+		delete(instance.stateWatchers, watcher)
+	}
+}
+
+// stopResults makes err the last result on results, dropping the oldest message when there is no room left so the
+// receiver is sure to see it, and closes results. Nothing is delivered to results once it is no longer registered.
+func stopResults(results chan dispatchResult, err error) {
+	for !deliver(results, dispatchResult{err: err}) {
+		select {
+		case <-results:
+		default:
+		}
+	}
+	close(results)
+}
+
+// deliver sends result without blocking the dispatcher, dropping it if the receiver is not keeping up.
+func deliver(results chan dispatchResult, result dispatchResult) bool {
+	select {
+	case results <- result:
+		return true
+	default:
+		return false
+	}
+}
+
+// withTimeout applies the instance default timeout to ctx unless ctx already carries a deadline.
+func (instance *SimconnectInstance) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+
+	instance.dispatchMutex.Lock()
+	timeout := instance.timeout
+	instance.dispatchMutex.Unlock()
+
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// awaitRequest registers for the response to requestID, calls send and waits for the first message answering it or
+// for ctx to be done.
+func (instance *SimconnectInstance) awaitRequest(ctx context.Context, requestID uint32, send func() error) ([]byte, error) {
+	ctx, cancel := instance.withTimeout(ctx)
+	defer cancel()
+
+	results, err := instance.watchRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	defer instance.unwatchRequest(requestID)
+
+	if err := send(); err != nil {
+		return nil, fmt.Errorf("error sending request %d: %w", requestID, err)
+	}
+
+	select {
+	case result := <-results:
+		return result.data, result.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("error waiting for response to request %d: %w", requestID, ctx.Err())
+	}
+}
+
+// watchRequest registers for the messages answering requestID, and the exceptions caused by it, until unwatchRequest
+// is called.
+func (instance *SimconnectInstance) watchRequest(requestID uint32) (chan dispatchResult, error) {
+	return instance.watchRequestSize(requestID, listenerBufferSize)
+}
+
+// watchRequestSize is watchRequest buffering size messages before further messages are dropped.
+func (instance *SimconnectInstance) watchRequestSize(requestID uint32, size int) (chan dispatchResult, error) {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	if instance.dispatchErr != nil {
+		return nil, instance.dispatchErr
+	}
+	results := make(chan dispatchResult, size)
+	instance.requestWaiters[requestID] = results
+	return results, nil
+}
+
+func (instance *SimconnectInstance) unwatchRequest(requestID uint32) {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	delete(instance.requestWaiters, requestID)
+	delete(instance.overflowed, requestID)
+}
+
+// requestOverflowed reports whether a message answering requestID was dropped because its buffer was full.
+func (instance *SimconnectInstance) requestOverflowed(requestID uint32) bool {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	return instance.overflowed[requestID]
+}
+
+// listen registers a listener for the messages with the given receive ID, or event ID when events is set. Once the
+// instance has stopped, the listener only receives the error which stopped it.
+func (instance *SimconnectInstance) listen(id uint32, events, allEvents bool) *listener {
+	l := &listener{
+		events:    events,
+		allEvents: allEvents,
+		id:        id,
+		results:   make(chan dispatchResult, listenerBufferSize),
+	}
+
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	if instance.stopped {
+		stopResults(l.results, instance.dispatchErr)
+		return l
+	}
+	instance.listeners[l] = struct{}{}
+	return l
+}
+
+// listenAll registers a listener for every message, with room for as many as a request for several objects returns.
+func (instance *SimconnectInstance) listenAll() *listener {
+	l := &listener{
+		all:     true,
+		results: make(chan dispatchResult, objectsBufferSize),
+	}
+
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	if instance.stopped {
+		stopResults(l.results, instance.dispatchErr)
+		return l
+	}
+	instance.listeners[l] = struct{}{}
+	return l
+}
+
+func (instance *SimconnectInstance) unlisten(l *listener) {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	delete(instance.listeners, l)
+}
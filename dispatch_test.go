@@ -0,0 +1,284 @@
+package simconnect
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+func newDispatchFake(t *testing.T) (*FakeTransport, *SimconnectInstance) {
+	fake := NewFakeTransport()
+	fake.On("RequestDataOnSimObjectType", func(fake *FakeTransport, call FakeCall) error {
+		// Events arriving ahead of a response must not be consumed by the request waiting on it
+		event := simconnect_data.RecvEvent{EventID: 7, Data: call.Args[0].(uint32)}
+		if err := fake.Queue(simconnect_data.RECV_ID_EVENT, &event); err != nil {
+			return err
+		}
+
+		header := simconnect_data.RecvSimobjectDataByType{}
+		header.RequestID = call.Args[0].(uint32)
+		header.DefineID = call.Args[1].(uint32)
+
+		apReport := APReport{RecvSimobjectDataByType: header, APSelectedAlt: float64(header.RequestID)}
+		if header.DefineID == 2 {
+			return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, &apReport)
+		}
+
+		report := Report{RecvSimobjectDataByType: header, Altitude: float64(header.RequestID)}
+		return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, &report)
+	})
+
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	t.Cleanup(func() { instance.Close() })
+
+	// Register the definitions up front so their IDs are predictable
+	_, err = instance.GetReport()
+	require.NoError(t, err)
+	_, err = instance.GetAPReport()
+	require.NoError(t, err)
+
+	return fake, instance
+}
+
+func TestDispatchConcurrentRequests(t *testing.T) {
+	_, instance := newDispatchFake(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := instance.Events(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			report, err := instance.GetReport()
+			if assert.NoError(t, err) {
+				assert.Equal(t, instance.definitionMap[reflect.TypeOf(Report{})], report.DefineID)
+				assert.Equal(t, float64(report.RequestID), report.Altitude)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			report, err := instance.GetAPReport()
+			if assert.NoError(t, err) {
+				assert.Equal(t, instance.definitionMap[reflect.TypeOf(APReport{})], report.DefineID)
+				assert.Equal(t, float64(report.RequestID), report.APSelectedAlt)
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case event, ok := <-events:
+		require.True(t, ok)
+		assert.Equal(t, uint32(7), event.EventID)
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered")
+	}
+}
+
+func TestDispatchClose(t *testing.T) {
+	fake, instance := newDispatchFake(t)
+	require.NoError(t, instance.Close())
+
+	_, err := instance.GetReport()
+	assert.Equal(t, errConnectionClosed, err)
+	assert.Len(t, fake.CallsTo("Close"), 1)
+
+	// Calls which only send are not made on the closed transport either
+	assert.Equal(t, errConnectionClosed, instance.MapClientEventToSimEvent(10, "AP_MASTER"))
+	assert.Equal(t, errConnectionClosed, instance.TransmitClientID(10, 0))
+	assert.Equal(t, errConnectionClosed, instance.SendText(0, 1, "hi"))
+	assert.Empty(t, fake.CallsTo("MapClientEventToSimEvent"))
+	assert.Empty(t, fake.CallsTo("TransmitClientEvent"))
+	assert.Empty(t, fake.CallsTo("Text"))
+}
+
+func TestDispatchCloseWithFullListener(t *testing.T) {
+	fake := NewFakeTransport()
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+
+	events := instance.Events(context.Background())
+	for i := uint32(0); i < 4*listenerBufferSize; i++ {
+		require.NoError(t, fake.Queue(simconnect_data.RECV_ID_EVENT, &simconnect_data.RecvEvent{EventID: 7, Data: i}))
+	}
+	require.Eventually(t, func() bool {
+		fake.mutex.Lock()
+		defer fake.mutex.Unlock()
+		return len(fake.queue) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The stop error is delivered even though the listener has no room left, ending the channel
+	require.NoError(t, instance.Close())
+	closed := make(chan struct{})
+	go func() {
+		for range events {
+		}
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "events channel not closed")
+	}
+
+	l := instance.listen(simconnect_data.RECV_ID_QUIT, false, false)
+	result, ok := <-l.results
+	assert.True(t, ok)
+	assert.Equal(t, errConnectionClosed, result.err)
+	select {
+	case _, ok = <-l.results:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		assert.Fail(t, "listener not closed")
+	}
+}
+
+func TestDispatchContext(t *testing.T) {
+	fake, instance := newDispatchFake(t)
+	fake.On("RequestDataOnSimObject", func(fake *FakeTransport, call FakeCall) error {
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := instance.GetReportOnObjectIDContext(ctx, 2)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+	assert.Less(t, int64(time.Since(start)), int64(DefaultTimeout))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = instance.GetReportOnObjectIDContext(ctx, 2)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+
+	instance.SetDefaultTimeout(50 * time.Millisecond)
+	_, err = instance.GetReportOnObjectID(2)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+}
+
+func TestDispatchSendError(t *testing.T) {
+	fake, instance := newDispatchFake(t)
+	sendErr := errors.New("pipe closed")
+	fake.On("AICreateParkedATCAircraft", func(fake *FakeTransport, call FakeCall) error {
+		return sendErr
+	})
+
+	_, err := instance.LoadParkedATCAircraftContext(context.Background(), "Boeing 747-8i Asobo", "G-4210", "EGLL", 10)
+	assert.True(t, errors.Is(err, sendErr), err)
+}
+
+func TestDispatchException(t *testing.T) {
+	fake, instance := newDispatchFake(t)
+	fake.On("RequestDataOnSimObject", func(fake *FakeTransport, call FakeCall) error {
+		exception := simconnect_data.RecvException{
+			Exception: simconnect_data.EXCEPTION_UNRECOGNIZED_ID,
+			SendID:    call.SendID,
+			Index:     4,
+		}
+		return fake.Queue(simconnect_data.RECV_ID_EXCEPTION, &exception)
+	})
+
+	_, err := instance.GetReportOnObjectID(2)
+	assert.True(t, errors.Is(err, ErrUnrecognizedID), err)
+
+	var simConnectErr *SimConnectError
+	require.True(t, errors.As(err, &simConnectErr))
+	assert.Equal(t, "RequestDataOnSimObject", simConnectErr.Method)
+	assert.Equal(t, uint32(4), simConnectErr.Index)
+
+	// Exceptions for other packets leave pending requests alone
+	require.NoError(t, instance.TransmitClientID(1, 0))
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_EXCEPTION, &simconnect_data.RecvException{
+		Exception: simconnect_data.EXCEPTION_UNRECOGNIZED_ID,
+		SendID:    fake.CallsTo("TransmitClientEvent")[0].SendID,
+	}))
+	_, err = instance.GetReport()
+	assert.NoError(t, err)
+}
+
+// reusingTransport returns every message in the same buffer, as SimConnect.dll does, so anything still referring to
+// an earlier message sees it overwritten.
+type reusingTransport struct {
+	*FakeTransport
+	buffer [4096]byte
+}
+
+func (transport *reusingTransport) GetNextDispatch() ([]byte, error) {
+	data, err := transport.FakeTransport.GetNextDispatch()
+	if len(data) == 0 || err != nil {
+		return data, err
+	}
+	for i := range transport.buffer {
+		transport.buffer[i] = 0xff
+	}
+	n := copy(transport.buffer[:], data)
+	return transport.buffer[:n], nil
+}
+
+func TestDispatchOwnsData(t *testing.T) {
+	fake := NewFakeTransport()
+	fake.On("RequestDataOnSimObjectType", func(fake *FakeTransport, call FakeCall) error {
+		report := Report{Altitude: float64(call.Args[0].(uint32))}
+		report.RequestID = call.Args[0].(uint32)
+		report.DefineID = call.Args[1].(uint32)
+		copy(report.Title[:], "Fake Aircraft")
+		return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, &report)
+	})
+
+	instance, err := NewSimConnectWithTransport(t.Name(), &reusingTransport{FakeTransport: fake})
+	require.NoError(t, err)
+	defer instance.Close()
+
+	first, err := instance.GetReport()
+	require.NoError(t, err)
+	firstCopy := *first
+
+	// Each of these overwrites the buffer the first report was received in
+	for i := 0; i < 5; i++ {
+		_, err := instance.GetReport()
+		require.NoError(t, err)
+	}
+	event := simconnect_data.RecvEvent{EventID: 1}
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_EVENT, &event))
+	time.Sleep(5 * dispatchPollInterval)
+
+	assert.Equal(t, firstCopy, *first)
+	assert.Equal(t, float64(first.RequestID), first.Altitude)
+}
+
+func TestCheckRecvSize(t *testing.T) {
+	header := func(size uint32, extra int) []byte {
+		data := make([]byte, 12+extra)
+		binary.LittleEndian.PutUint32(data, size)
+		return data
+	}
+
+	data, err := checkRecvSize(header(16, 8))
+	require.NoError(t, err)
+	assert.Len(t, data, 16)
+
+	_, err = checkRecvSize(header(24, 8))
+	assert.EqualError(t, err, "invalid message size: size 24 given for a message of 20 bytes")
+	_, err = checkRecvSize(header(4, 8))
+	assert.ErrorIs(t, err, simconnect_data.ErrInvalidSize)
+	_, err = checkRecvSize(make([]byte, 8))
+	assert.EqualError(t, err, "invalid message size: 8 bytes is shorter than the header")
+
+	short := header(16, 4)
+	binary.LittleEndian.PutUint32(short[8:], simconnect_data.RECV_ID_SIMOBJECT_DATA)
+	_, err = decodeHeader(short)
+	assert.ErrorIs(t, err, simconnect_data.ErrInvalidSize)
+}
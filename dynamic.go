@@ -0,0 +1,300 @@
+package simconnect
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// SimVarSpec is a simvar of a DynamicDefinition. Name and Unit are those of the name and unit tags, Unit defaulting
+// to that of the catalog, and Type one of the values of the type tag, float64 if left empty.
+type SimVarSpec struct {
+	Name string
+	Unit string
+	Type string
+}
+
+// DynamicDefinition is a data definition built from simvar names known only at runtime, such as ones read from a
+// config file. It is registered as a struct type with a field tagged for each simvar, so it behaves like the tagged
+// structs of Get and Set: registered again after reconnecting and listed by DataDefinitions.
+//
+// Every definition made from equal specs has the same Type and shares one data definition with the simulator. Clear
+// removes it once the last of them is cleared, whereas ClearDataDefinition(definition.Type) removes it from under
+// all of them, which then register it again under a new ID the next time they are used.
+type DynamicDefinition struct {
+	instance *SimconnectInstance
+	specs    []SimVarSpec
+	// Type is the struct type registered, with a field for each simvar in order
+	Type reflect.Type
+
+	clearOnce sync.Once
+}
+
+// Values holds the simvars of a DynamicDefinition keyed by name. Numbers are held as the Go type of their data type,
+// such as float64 for float64 and int32 for int32, and strings as string.
+type Values map[string]interface{}
+
+// dynamicFieldTypes is the Go type of the field registered for each value of SimVarSpec.Type.
+var dynamicFieldTypes = map[string]reflect.Type{
+	"int32":        reflect.TypeOf(int32(0)),
+	"int64":        reflect.TypeOf(int64(0)),
+	"float32":      reflect.TypeOf(float32(0)),
+	"float64":      reflect.TypeOf(float64(0)),
+	"string8":      reflect.TypeOf(""),
+	"string32":     reflect.TypeOf(""),
+	"string64":     reflect.TypeOf(""),
+	"string128":    reflect.TypeOf(""),
+	"string256":    reflect.TypeOf(""),
+	"string260":    reflect.TypeOf(""),
+	"stringv":      reflect.TypeOf(""),
+	"initposition": reflect.TypeOf(simconnect_data.SimconnectDataInitPosition{}),
+	"markerstate":  reflect.TypeOf(simconnect_data.MarkerState{}),
+	"waypoint":     reflect.TypeOf(simconnect_data.Waypoint{}),
+	"latlonalt":    reflect.TypeOf(simconnect_data.LatLonAlt{}),
+	"xyz":          reflect.TypeOf(simconnect_data.XYZ{}),
+}
+
+// DefineDynamic registers a data definition requesting specs, each simvar being given once.
+func (instance *SimconnectInstance) DefineDynamic(specs []SimVarSpec) (*DynamicDefinition, error) {
+	t, err := dynamicType(specs)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := instance.definitionFor(t); err != nil {
+		return nil, err
+	}
+
+	instance.definitionMapMutex.Lock()
+	instance.dynamicRefs[t]++
+	instance.definitionMapMutex.Unlock()
+
+	return &DynamicDefinition{
+		instance: instance,
+		specs:    append([]SimVarSpec(nil), specs...),
+		Type:     t,
+	}, nil
+}
+
+// dynamicType returns the struct type with a field tagged for each of specs. Equal specs give the same type, so
+// share their data definition.
+func dynamicType(specs []SimVarSpec) (reflect.Type, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no simvars to define")
+	}
+
+	fields := make([]reflect.StructField, 0, len(specs))
+	seen := map[string]bool{}
+	for i, spec := range specs {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("simvar %d has no name", i)
+		}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("simvar %s given more than once", spec.Name)
+		}
+		seen[spec.Name] = true
+
+		typeTag := strings.ToLower(spec.Type)
+		if typeTag == "" {
+			typeTag = "float64"
+		}
+		fieldType, ok := dynamicFieldTypes[typeTag]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q for simvar %s", spec.Type, spec.Name)
+		}
+
+		// Leaving the unit tag out requests the simvar in the unit of the catalog
+		tag := fmt.Sprintf("name:%s type:%s", strconv.Quote(spec.Name), strconv.Quote(typeTag))
+		if spec.Unit != "" {
+			tag += fmt.Sprintf(" unit:%s", strconv.Quote(spec.Unit))
+		}
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("SimVar%d", i),
+			Type: fieldType,
+			Tag:  reflect.StructTag(tag),
+		})
+	}
+	return reflect.StructOf(fields), nil
+}
+
+// Clear releases the definition, removing its data definition from the simulator unless another DynamicDefinition
+// made from the same specs still uses it. The definition must not be used afterwards.
+func (definition *DynamicDefinition) Clear() error {
+	var err error
+	definition.clearOnce.Do(func() {
+		instance := definition.instance
+		instance.definitionMapMutex.Lock()
+		instance.dynamicRefs[definition.Type]--
+		last := instance.dynamicRefs[definition.Type] == 0
+		if last {
+			delete(instance.dynamicRefs, definition.Type)
+		}
+		_, registered := instance.definitionMap[definition.Type]
+		instance.definitionMapMutex.Unlock()
+
+		if last && registered {
+			err = instance.ClearDataDefinition(definition.Type)
+		}
+	})
+	return err
+}
+
+// Specs returns the simvars of the definition.
+func (definition *DynamicDefinition) Specs() []SimVarSpec {
+	return append([]SimVarSpec(nil), definition.specs...)
+}
+
+// Get requests the simvars of the definition for objectID, simconnect_data.OBJECT_ID_USER being the user aircraft.
+func (definition *DynamicDefinition) Get(objectID uint32) (Values, error) {
+	return definition.GetContext(context.Background(), objectID)
+}
+
+// GetContext is Get which gives up once ctx is done
+func (definition *DynamicDefinition) GetContext(ctx context.Context, objectID uint32) (Values, error) {
+	instance := definition.instance
+	return definition.get(ctx, func(requestID, definitionID uint32) error {
+		return instance.requestDataOnSimObject(requestID, definitionID, objectID, simconnect_data.SIMCONNECT_PERIOD_ONCE)
+	})
+}
+
+// GetByType requests the simvars of the definition for the user's object of simObjectType, such as
+// simconnect_data.SIMOBJECT_TYPE_USER.
+func (definition *DynamicDefinition) GetByType(simObjectType uint32) (Values, error) {
+	return definition.GetByTypeContext(context.Background(), simObjectType)
+}
+
+// GetByTypeContext is GetByType which gives up once ctx is done
+func (definition *DynamicDefinition) GetByTypeContext(ctx context.Context, simObjectType uint32) (Values, error) {
+	instance := definition.instance
+	return definition.get(ctx, func(requestID, definitionID uint32) error {
+		return instance.requestDataOnSimObjectType(requestID, definitionID, 0, simObjectType)
+	})
+}
+
+func (definition *DynamicDefinition) get(ctx context.Context, send func(requestID, definitionID uint32) error) (Values, error) {
+	data, err := definition.instance.requestData(ctx, definition.Type, send)
+	if err != nil {
+		return nil, err
+	}
+	return definition.Decode(data)
+}
+
+// Decode decodes a message of data received for the definition, such as one passed to an OnSimObjectData handler.
+func (definition *DynamicDefinition) Decode(data []byte) (Values, error) {
+	value := reflect.New(definition.Type).Elem()
+	if _, err := definition.instance.decodeObjectData(data, value); err != nil {
+		return nil, err
+	}
+
+	values := make(Values, len(definition.specs))
+	for i, spec := range definition.specs {
+		values[spec.Name] = value.Field(i).Interface()
+	}
+	return values, nil
+}
+
+// Set writes values to the simvars of the definition on objectID, simconnect_data.OBJECT_ID_USER being the user
+// aircraft. values must hold every simvar of the definition, each of which must be settable, as a number or bool for
+// numbers, a string for strings and the simconnect_data struct of the other data types. See Set for how failures
+// are reported.
+func (definition *DynamicDefinition) Set(objectID uint32, values Values) error {
+	value := reflect.New(definition.Type).Elem()
+	for i, spec := range definition.specs {
+		v, ok := values[spec.Name]
+		if !ok {
+			return fmt.Errorf("no value for simvar %s", spec.Name)
+		}
+		if err := assignValue(value.Field(i), v); err != nil {
+			return fmt.Errorf("invalid value for simvar %s: %v", spec.Name, err)
+		}
+	}
+	for name := range values {
+		if !definition.has(name) {
+			return fmt.Errorf("simvar %s is not in the definition", name)
+		}
+	}
+
+	return definition.instance.set(objectID, value)
+}
+
+func (definition *DynamicDefinition) has(name string) bool {
+	for _, spec := range definition.specs {
+		if spec.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// assignValue sets field to v, converting between the types of numbers.
+func assignValue(field reflect.Value, v interface{}) error {
+	value := reflect.ValueOf(v)
+	switch {
+	case !value.IsValid():
+		return fmt.Errorf("nil can not be sent as %s", field.Type())
+	case value.Type() == field.Type():
+		field.Set(value)
+	case isNumberKind(field.Kind()) && isNumberKind(value.Kind()):
+		field.Set(value.Convert(field.Type()))
+	case isNumberKind(field.Kind()) && value.Kind() == reflect.Bool:
+		var n int64
+		if value.Bool() {
+			n = 1
+		}
+		field.Set(reflect.ValueOf(n).Convert(field.Type()))
+	case field.Kind() == reflect.String && value.Kind() == reflect.String:
+		field.SetString(value.String())
+	default:
+		return fmt.Errorf("%T can not be sent as %s", v, field.Type())
+	}
+	return nil
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// Float64 returns the simvar name as a float64, converting it from any other number.
+func (values Values) Float64(name string) (float64, bool) {
+	value := reflect.ValueOf(values[name])
+	switch value.Kind() {
+	case reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
+
+// Int64 returns the simvar name as an int64, converting it from any other number.
+func (values Values) Int64(name string) (int64, bool) {
+	value := reflect.ValueOf(values[name])
+	switch value.Kind() {
+	case reflect.Int32, reflect.Int64:
+		return value.Int(), true
+	case reflect.Float32, reflect.Float64:
+		return int64(value.Float()), true
+	}
+	return 0, false
+}
+
+// Bool returns whether the simvar name is a non-zero number.
+func (values Values) Bool(name string) (bool, bool) {
+	f, ok := values.Float64(name)
+	return f != 0, ok
+}
+
+// Text returns the simvar name if it is a string.
+func (values Values) Text(name string) (string, bool) {
+	s, ok := values[name].(string)
+	return s, ok
+}
@@ -0,0 +1,185 @@
+package simconnect
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+var dynamicSpecs = []SimVarSpec{
+	{Name: "Title", Type: "stringv"},
+	{Name: "Plane Altitude", Unit: "feet"},
+	{Name: "Plane Latitude", Unit: "degrees", Type: "float64"},
+	{Name: "Sim On Ground", Unit: "bool", Type: "int32"},
+}
+
+func TestDefineDynamic(t *testing.T) {
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	definition, err := instance.DefineDynamic(dynamicSpecs)
+	require.NoError(t, err)
+
+	values, err := definition.Get(simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	assert.Len(t, values, 4)
+	assert.IsType(t, "", values["Title"])
+	assert.IsType(t, int32(0), values["Sim On Ground"])
+
+	report, err := Get[positionReport](instance, simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	altitude, ok := values.Float64("Plane Altitude")
+	assert.True(t, ok)
+	assert.Equal(t, report.Altitude, altitude)
+	title, ok := values.Text("Title")
+	assert.True(t, ok)
+	assert.NotEmpty(t, title)
+	onGround, ok := values.Bool("Sim On Ground")
+	assert.True(t, ok)
+	assert.Equal(t, report.OnGround, onGround)
+
+	byType, err := definition.GetByType(simconnect_data.SIMOBJECT_TYPE_USER)
+	require.NoError(t, err)
+	assert.Equal(t, values["Plane Latitude"], byType["Plane Latitude"])
+
+	// The same simvars share the definition registered by the first
+	again, err := instance.DefineDynamic(dynamicSpecs)
+	require.NoError(t, err)
+	assert.Equal(t, definition.Type, again.Type)
+	registered, ok := instance.DataDefinition(definition.Type)
+	require.True(t, ok)
+	assert.Equal(t, "Plane Altitude", registered.Fields[1].Name)
+	assert.Equal(t, "feet", registered.Fields[1].Unit)
+	assert.Equal(t, simconnect_data.DATATYPE_STRINGV, registered.Fields[0].DataType)
+}
+
+func TestDefineDynamicSet(t *testing.T) {
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	definition, err := instance.DefineDynamic([]SimVarSpec{
+		{Name: "Plane Altitude", Unit: "feet"},
+		{Name: "Plane Latitude", Unit: "degrees", Type: "float32"},
+	})
+	require.NoError(t, err)
+
+	values, err := definition.Get(simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	altitude, _ := values.Float64("Plane Altitude")
+
+	// Numbers of any type are converted to the type of the simvar
+	err = definition.Set(simconnect_data.OBJECT_ID_USER, Values{"Plane Altitude": int(altitude) + 1000, "Plane Latitude": 50})
+	require.NoError(t, err)
+	waitForSim(time.Second)
+
+	updated, err := definition.Get(simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	updatedAltitude, _ := updated.Float64("Plane Altitude")
+	assert.InDelta(t, float64(int(altitude)+1000), updatedAltitude, 1)
+	assert.Equal(t, float32(50), updated["Plane Latitude"])
+
+	err = definition.Set(simconnect_data.OBJECT_ID_USER, Values{"Plane Altitude": 1000})
+	assert.EqualError(t, err, "no value for simvar Plane Latitude")
+	err = definition.Set(simconnect_data.OBJECT_ID_USER, Values{"Plane Altitude": "high", "Plane Latitude": 50})
+	assert.EqualError(t, err, "invalid value for simvar Plane Altitude: string can not be sent as float64")
+	err = definition.Set(simconnect_data.OBJECT_ID_USER, Values{"Plane Altitude": 1000, "Plane Latitude": 50, "Plane Longitude": 0})
+	assert.EqualError(t, err, "simvar Plane Longitude is not in the definition")
+}
+
+func TestDefineDynamicInvalid(t *testing.T) {
+	instance, err := NewSimConnectWithTransport(t.Name(), NewFakeTransport())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	_, err = instance.DefineDynamic(nil)
+	assert.EqualError(t, err, "no simvars to define")
+	_, err = instance.DefineDynamic([]SimVarSpec{{Unit: "feet"}})
+	assert.EqualError(t, err, "simvar 0 has no name")
+	_, err = instance.DefineDynamic([]SimVarSpec{{Name: "Plane Altitude"}, {Name: "Plane Altitude"}})
+	assert.EqualError(t, err, "simvar Plane Altitude given more than once")
+	_, err = instance.DefineDynamic([]SimVarSpec{{Name: "Plane Altitude", Type: "float128"}})
+	assert.EqualError(t, err, `unknown type "float128" for simvar Plane Altitude`)
+	assert.Empty(t, instance.DataDefinitions())
+}
+
+func TestDefineDynamicRejectedFirstSimVar(t *testing.T) {
+	specs := []SimVarSpec{
+		{Name: "Plane Altitde", Unit: "feet"},
+		{Name: "Plane Heading Degrees True", Unit: "degrees"},
+	}
+	newFake := func() *FakeTransport {
+		fake := NewFakeTransport()
+		fake.On("AddToDataDefinition", func(fake *FakeTransport, call FakeCall) error {
+			if call.Args[1] != "Plane Altitde" {
+				return nil
+			}
+			return fake.Queue(simconnect_data.RECV_ID_EXCEPTION, &simconnect_data.RecvException{
+				Exception: simconnect_data.EXCEPTION_NAME_UNRECOGNIZED,
+				SendID:    call.SendID,
+				Index:     1,
+			})
+		})
+		fake.On("RequestDataOnSimObject", func(fake *FakeTransport, call FakeCall) error {
+			data := struct {
+				simconnect_data.RecvSimobjectDataByType
+				Heading float64
+			}{Heading: 270}
+			data.RequestID = call.Args[0].(uint32)
+			data.DefineID = call.Args[1].(uint32)
+			return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA, &data)
+		})
+		return fake
+	}
+
+	instance, err := NewSimConnectWithTransport(t.Name(), newFake())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	definition, err := instance.DefineDynamic(specs)
+	require.NoError(t, err)
+	_, err = definition.Get(simconnect_data.OBJECT_ID_USER)
+	var fieldErr *FieldError
+	require.True(t, errors.As(err, &fieldErr), "%v", err)
+	assert.Equal(t, "Plane Altitde", fieldErr.Tag)
+
+	dropping, err := NewSimConnectWithTransport(t.Name(), newFake(), WithDropRejectedFields())
+	require.NoError(t, err)
+	defer dropping.Close()
+
+	definition, err = dropping.DefineDynamic(specs)
+	require.NoError(t, err)
+	values, err := definition.Get(simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), values["Plane Altitde"])
+	assert.Equal(t, float64(270), values["Plane Heading Degrees True"])
+}
+
+func TestDefineDynamicClear(t *testing.T) {
+	fake := NewFakeTransport()
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	first, err := instance.DefineDynamic(dynamicSpecs)
+	require.NoError(t, err)
+	second, err := instance.DefineDynamic(dynamicSpecs)
+	require.NoError(t, err)
+	require.Len(t, instance.DataDefinitions(), 1)
+
+	// The definition is only removed once neither uses it
+	require.NoError(t, first.Clear())
+	require.NoError(t, first.Clear())
+	assert.Len(t, instance.DataDefinitions(), 1)
+	assert.Empty(t, fake.CallsTo("ClearDataDefinition"))
+
+	require.NoError(t, second.Clear())
+	assert.Empty(t, instance.DataDefinitions())
+	assert.Len(t, fake.CallsTo("ClearDataDefinition"), 1)
+}
@@ -0,0 +1,1004 @@
+// Package emulator implements a fake simulator speaking the SimConnect network protocol. It keeps a simulated user
+// aircraft plus any AI objects created by clients and answers data requests, SetData, client events, system event
+// subscriptions and AI creation the way a running simulator would, so SimConnect clients can be exercised headlessly.
+package emulator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/JRascagneres/Simconnect-Go/internal/protocol"
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// tickInterval is how often the world is stepped and periodic system events are considered.
+const tickInterval = time.Second / 6
+
+// Config controls the behaviour of a Server.
+type Config struct {
+	// ApplicationName is reported to clients in RecvOpen. Defaults to "SimConnect Emulator".
+	ApplicationName string
+
+	// RejectUnknownSimVars makes AddToDataDefinition fail with a NAME_UNRECOGNIZED exception for simvars the emulator
+	// does not model. By default they are accepted and read as zero.
+	RejectUnknownSimVars bool
+
+	// Logger receives a line per request when set.
+	Logger *log.Logger
+}
+
+// Server accepts SimConnect client connections. Every connection shares the same World.
+type Server struct {
+	config Config
+	world  *World
+
+	mutex    sync.Mutex
+	listener net.Listener
+	conns    map[*conn]struct{}
+	closed   bool
+	done     chan struct{}
+}
+
+// NewServer returns a Server with a freshly initialised World.
+func NewServer(config Config) *Server {
+	if config.ApplicationName == "" {
+		config.ApplicationName = "SimConnect Emulator"
+	}
+
+	return &Server{
+		config: config,
+		world:  newWorld(),
+		conns:  map[*conn]struct{}{},
+		done:   make(chan struct{}),
+	}
+}
+
+// Start listens on address and serves connections in the background.
+func Start(address string, config Config) (*Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	server := NewServer(config)
+	server.listener = listener
+	go server.Serve(listener)
+
+	return server, nil
+}
+
+// World returns the simulation served to clients.
+func (server *Server) World() *World {
+	return server.world
+}
+
+// Addr returns the address the server is listening on, or an empty string before Serve is called.
+func (server *Server) Addr() string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if server.listener == nil {
+		return ""
+	}
+	return server.listener.Addr().String()
+}
+
+// Serve accepts connections on listener until Close is called.
+func (server *Server) Serve(listener net.Listener) error {
+	server.mutex.Lock()
+	if server.closed {
+		server.mutex.Unlock()
+		listener.Close()
+		return errors.New("server closed")
+	}
+	server.listener = listener
+	server.mutex.Unlock()
+
+	go server.tick()
+
+	for {
+		netConn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-server.done:
+				return nil
+			default:
+				return err
+			}
+		}
+
+		c := newConn(server, netConn)
+
+		server.mutex.Lock()
+		server.conns[c] = struct{}{}
+		server.mutex.Unlock()
+
+		go c.serve()
+	}
+}
+
+// Close stops the listener and drops every connection.
+func (server *Server) Close() error {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if server.closed {
+		return nil
+	}
+	server.closed = true
+	close(server.done)
+
+	for c := range server.conns {
+		c.netConn.Close()
+	}
+
+	if server.listener == nil {
+		return nil
+	}
+	return server.listener.Close()
+}
+
+func (server *Server) removeConn(c *conn) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	delete(server.conns, c)
+}
+
+// broadcast calls fn for every open connection.
+func (server *Server) broadcast(fn func(c *conn)) {
+	server.mutex.Lock()
+	conns := make([]*conn, 0, len(server.conns))
+	for c := range server.conns {
+		conns = append(conns, c)
+	}
+	server.mutex.Unlock()
+
+	for _, c := range conns {
+		fn(c)
+	}
+}
+
+func (server *Server) tick() {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-server.done:
+			return
+		case now := <-ticker.C:
+			server.world.step(now.Sub(last))
+			last = now
+
+			server.broadcast(func(c *conn) {
+				c.tick(now)
+			})
+		}
+	}
+}
+
+func (server *Server) logf(format string, args ...interface{}) {
+	if server.config.Logger != nil {
+		server.config.Logger.Printf(format, args...)
+	}
+}
+
+// datum is a single entry of a client data definition.
+type datum struct {
+	name     string
+	unit     string
+	dataType uint32
+	epsilon  float32
+	datumID  uint32
+}
+
+// dataSubscription is a periodic RequestDataOnSimObject.
+type dataSubscription struct {
+	defineID uint32
+	objectID uint32
+	period   uint32
+	flags    uint32
+	interval uint32
+	limit    uint32
+
+	wait uint32    // periods left before data is next sent
+	sent uint32    // number of times data was sent
+	next time.Time // start of the next period for SIMCONNECT_PERIOD_SECOND
+	last [][]byte  // values last sent, for DATA_REQUEST_FLAG_CHANGED
+}
+
+// systemEvent is a client subscription to a periodic system event.
+type systemEvent struct {
+	clientEventID uint32
+	interval      time.Duration
+	next          time.Time
+}
+
+// periodicSystemEvents maps the timer system events to their interval.
+var periodicSystemEvents = map[string]time.Duration{
+	"1SEC": time.Second,
+	"4SEC": 4 * time.Second,
+	"6HZ":  time.Second / 6,
+}
+
+// conn is a single client connection.
+type conn struct {
+	server  *Server
+	netConn net.Conn
+
+	writeMutex sync.Mutex
+
+	mutex         sync.Mutex
+	definitions   map[uint32][]datum
+	clientEvents  map[uint32]string
+	systemEvents  map[string]*systemEvent
+	objectAdded   []uint32
+	objectRemoved []uint32
+	subscriptions map[uint32]*dataSubscription
+	eventsOff     map[uint32]bool // system events turned off by SetSystemEventState
+}
+
+func newConn(server *Server, netConn net.Conn) *conn {
+	return &conn{
+		server:        server,
+		netConn:       netConn,
+		definitions:   map[uint32][]datum{},
+		clientEvents:  map[uint32]string{},
+		systemEvents:  map[string]*systemEvent{},
+		subscriptions: map[uint32]*dataSubscription{},
+		eventsOff:     map[uint32]bool{},
+	}
+}
+
+func (c *conn) serve() {
+	defer c.server.removeConn(c)
+	defer c.netConn.Close()
+
+	for {
+		packet, err := protocol.ReadPacket(c.netConn)
+		if err != nil {
+			if err != io.EOF {
+				c.server.logf("read error: %v", err)
+			}
+			return
+		}
+
+		req, err := protocol.DecodeRequest(packet)
+		if err != nil {
+			c.server.logf("decode error: %v", err)
+			return
+		}
+
+		c.server.logf("request %#x send ID %d", req.ID, req.SendID)
+		c.handle(req)
+	}
+}
+
+func (c *conn) send(recvID uint32, payload []byte) {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	if _, err := c.netConn.Write(protocol.EncodeResponse(recvID, payload)); err != nil {
+		c.server.logf("write error: %v", err)
+	}
+}
+
+func (c *conn) sendException(req protocol.Request, exception, index uint32) {
+	payload := (&protocol.Builder{}).
+		Uint32(exception).
+		Uint32(req.SendID).
+		Uint32(index).
+		Payload()
+
+	c.send(simconnect_data.RECV_ID_EXCEPTION, payload)
+}
+
+func (c *conn) sendEvent(recvID, groupID, eventID, data uint32, extra ...uint32) {
+	b := (&protocol.Builder{}).
+		Uint32(groupID).
+		Uint32(eventID).
+		Uint32(data)
+	for _, v := range extra {
+		b.Uint32(v)
+	}
+	c.send(recvID, b.Payload())
+}
+
+func (c *conn) handle(req protocol.Request) {
+	r := protocol.NewReader(req.Payload)
+
+	switch req.ID {
+	case protocol.ID_OPEN:
+		c.handleOpen()
+	case protocol.ID_ADD_TO_DATA_DEFINITION:
+		c.handleAddToDataDefinition(req, r)
+	case protocol.ID_CLEAR_DATA_DEFINITION:
+		c.mutex.Lock()
+		delete(c.definitions, r.Uint32())
+		c.mutex.Unlock()
+	case protocol.ID_REQUEST_DATA_ON_SIM_OBJECT:
+		c.handleRequestDataOnSimObject(req, r)
+	case protocol.ID_REQUEST_DATA_ON_SIM_OBJECT_TYPE:
+		c.handleRequestDataOnSimObjectType(req, r)
+	case protocol.ID_SET_DATA_ON_SIM_OBJECT:
+		c.handleSetDataOnSimObject(req, r)
+	case protocol.ID_MAP_CLIENT_EVENT_TO_SIM_EVENT:
+		eventID, name := r.Uint32(), r.String(protocol.StringSize256)
+		c.mutex.Lock()
+		c.clientEvents[eventID] = name
+		c.mutex.Unlock()
+	case protocol.ID_TRANSMIT_CLIENT_EVENT:
+		c.handleTransmitClientEvent(req, r)
+	case protocol.ID_SUBSCRIBE_TO_SYSTEM_EVENT:
+		c.handleSubscribeToSystemEvent(r)
+	case protocol.ID_UNSUBSCRIBE_FROM_SYSTEM_EVENT:
+		c.handleUnsubscribeFromSystemEvent(r)
+	case protocol.ID_SET_SYSTEM_EVENT_STATE:
+		c.handleSetSystemEventState(r)
+	case protocol.ID_AI_CREATE_PARKED_ATC_AIRCRAFT:
+		c.handleAICreateParkedATCAircraft(r)
+	case protocol.ID_AI_CREATE_NON_ATC_AIRCRAFT:
+		c.handleAICreateNonATCAircraft(r)
+	case protocol.ID_AI_CREATE_ENROUTE_ATC_AIRCRAFT:
+		c.handleAICreateEnrouteATCAircraft(r)
+	case protocol.ID_AI_REMOVE_OBJECT:
+		c.handleAIRemoveObject(req, r)
+	case protocol.ID_AI_SET_AIRCRAFT_FLIGHT_PLAN, protocol.ID_FLIGHT_PLAN_LOAD, protocol.ID_TEXT:
+		// Accepted without any visible effect.
+	default:
+		c.server.logf("unsupported request %#x", req.ID)
+		c.sendException(req, simconnect_data.EXCEPTION_UNRECOGNIZED_ID, 0)
+	}
+}
+
+func (c *conn) handleOpen() {
+	payload := (&protocol.Builder{}).
+		String(c.server.config.ApplicationName, protocol.StringSize256).
+		Uint32(11).Uint32(0).    // application version
+		Uint32(0).Uint32(0).     // application build
+		Uint32(10).Uint32(0).    // SimConnect version
+		Uint32(61259).Uint32(0). // SimConnect build
+		Uint32(0).Uint32(0).     // reserved
+		Payload()
+
+	c.send(simconnect_data.RECV_ID_OPEN, payload)
+}
+
+func (c *conn) handleAddToDataDefinition(req protocol.Request, r *protocol.Reader) {
+	defineID := r.Uint32()
+	d := datum{
+		name: r.String(protocol.StringSize256),
+		unit: r.String(protocol.StringSize256),
+	}
+	d.dataType = r.Uint32()
+	d.epsilon = r.Float32()
+	d.datumID = r.Uint32()
+
+	if _, ok := dataSize(d.dataType); !ok {
+		c.sendException(req, simconnect_data.EXCEPTION_INVALID_DATA_TYPE, 4)
+		return
+	}
+
+	if c.server.config.RejectUnknownSimVars && !isKnownSimVar(d.name) {
+		c.sendException(req, simconnect_data.EXCEPTION_NAME_UNRECOGNIZED, 2)
+		return
+	}
+
+	c.mutex.Lock()
+	c.definitions[defineID] = append(c.definitions[defineID], d)
+	c.mutex.Unlock()
+}
+
+func (c *conn) definition(defineID uint32) ([]datum, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	d, ok := c.definitions[defineID]
+	return d, ok
+}
+
+// simObjectData encodes the RECV_SIMOBJECT_DATA body for object.
+func (c *conn) simObjectData(requestID, defineID, entryNumber, outOf uint32, definition []datum, object *Object) []byte {
+	values := encodeValues(definition, object)
+	return objectData(requestID, defineID, object.ID, 0, entryNumber, outOf, definition, values, allValues(values))
+}
+
+// encodeValues encodes every datum of definition for object separately.
+func encodeValues(definition []datum, object *Object) [][]byte {
+	values := make([][]byte, len(definition))
+	for i, d := range definition {
+		b := &protocol.Builder{}
+		encodeDatum(b, d, object)
+		values[i] = b.Payload()
+	}
+	return values
+}
+
+func allValues(values [][]byte) []int {
+	send := make([]int, len(values))
+	for i := range send {
+		send[i] = i
+	}
+	return send
+}
+
+// objectData encodes a RECV_SIMOBJECT_DATA body holding the values listed in send, each preceded by its datum ID
+// when flags has DATA_REQUEST_FLAG_TAGGED.
+func objectData(requestID, defineID, objectID, flags, entryNumber, outOf uint32, definition []datum, values [][]byte, send []int) []byte {
+	b := (&protocol.Builder{}).
+		Uint32(requestID).
+		Uint32(objectID).
+		Uint32(defineID).
+		Uint32(flags).
+		Uint32(entryNumber).
+		Uint32(outOf).
+		Uint32(uint32(len(send)))
+
+	for _, i := range send {
+		if flags&simconnect_data.DATA_REQUEST_FLAG_TAGGED != 0 {
+			b.Uint32(definition[i].datumID)
+		}
+		b.Raw(values[i])
+	}
+
+	return b.Payload()
+}
+
+func (c *conn) handleRequestDataOnSimObject(req protocol.Request, r *protocol.Reader) {
+	requestID, defineID, objectID, period := r.Uint32(), r.Uint32(), r.Uint32(), r.Uint32()
+	flags, origin, interval, limit := r.Uint32(), r.Uint32(), r.Uint32(), r.Uint32()
+
+	c.mutex.Lock()
+	delete(c.subscriptions, requestID)
+	c.mutex.Unlock()
+
+	definition, ok := c.definition(defineID)
+	if !ok {
+		c.sendException(req, simconnect_data.EXCEPTION_UNRECOGNIZED_ID, 2)
+		return
+	}
+
+	if period == simconnect_data.SIMCONNECT_PERIOD_NEVER {
+		return
+	}
+
+	world := c.server.world
+	world.mutex.Lock()
+	object := world.object(objectID)
+	var payload []byte
+	if object != nil {
+		values := encodeValues(definition, object)
+		payload = objectData(requestID, defineID, object.ID, flags, 1, 1, definition, values, allValues(values))
+	}
+	world.mutex.Unlock()
+
+	if object == nil {
+		c.sendException(req, simconnect_data.EXCEPTION_UNRECOGNIZED_ID, 3)
+		return
+	}
+
+	if period == simconnect_data.SIMCONNECT_PERIOD_ONCE {
+		c.send(simconnect_data.RECV_ID_SIMOBJECT_DATA, payload)
+		return
+	}
+
+	c.mutex.Lock()
+	c.subscriptions[requestID] = &dataSubscription{
+		defineID: defineID,
+		objectID: objectID,
+		period:   period,
+		flags:    flags,
+		interval: interval,
+		limit:    limit,
+		wait:     origin,
+		next:     time.Now(),
+	}
+	c.mutex.Unlock()
+}
+
+func (c *conn) handleRequestDataOnSimObjectType(req protocol.Request, r *protocol.Reader) {
+	requestID, defineID, radius, objectType := r.Uint32(), r.Uint32(), r.Uint32(), r.Uint32()
+
+	definition, ok := c.definition(defineID)
+	if !ok {
+		c.sendException(req, simconnect_data.EXCEPTION_UNRECOGNIZED_ID, 2)
+		return
+	}
+
+	world := c.server.world
+	world.mutex.Lock()
+	user := world.object(UserObjectID)
+
+	var matches []*Object
+	if objectType == simconnect_data.SIMOBJECT_TYPE_USER || radius == 0 {
+		matches = append(matches, user)
+	} else {
+		for _, object := range world.objects {
+			if objectType != simconnect_data.SIMOBJECT_TYPE_ALL && object.Type != objectType {
+				continue
+			}
+			if distance(user, object) <= float64(radius) {
+				matches = append(matches, object)
+			}
+		}
+	}
+
+	payloads := make([][]byte, len(matches))
+	for i, object := range matches {
+		payloads[i] = c.simObjectData(requestID, defineID, uint32(i+1), uint32(len(matches)), definition, object)
+	}
+	world.mutex.Unlock()
+
+	// An empty message tells the client nothing was found
+	if len(matches) == 0 {
+		payloads = append(payloads, objectData(requestID, defineID, 0, 0, 0, 0, nil, nil, nil))
+	}
+
+	for _, payload := range payloads {
+		c.send(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, payload)
+	}
+}
+
+func (c *conn) handleSetDataOnSimObject(req protocol.Request, r *protocol.Reader) {
+	defineID, objectID, _, arrayCount, unitSize := r.Uint32(), r.Uint32(), r.Uint32(), r.Uint32(), r.Uint32()
+	data := r.Remaining()
+
+	definition, ok := c.definition(defineID)
+	if !ok {
+		c.sendException(req, simconnect_data.EXCEPTION_UNRECOGNIZED_ID, 1)
+		return
+	}
+
+	if arrayCount == 0 {
+		arrayCount = 1
+	}
+	if uint64(len(data)) < uint64(arrayCount)*uint64(unitSize) {
+		c.sendException(req, simconnect_data.EXCEPTION_SIZE_MISMATCH, 6)
+		return
+	}
+
+	world := c.server.world
+	world.mutex.Lock()
+	defer world.mutex.Unlock()
+
+	object := world.object(objectID)
+	if object == nil {
+		c.sendException(req, simconnect_data.EXCEPTION_UNRECOGNIZED_ID, 2)
+		return
+	}
+
+	for i := uint32(0); i < arrayCount; i++ {
+		element := protocol.NewReader(data[i*unitSize : (i+1)*unitSize])
+		for _, d := range definition {
+			decodeDatum(element, d, object)
+		}
+	}
+}
+
+func (c *conn) handleTransmitClientEvent(req protocol.Request, r *protocol.Reader) {
+	objectID, eventID, data := r.Uint32(), r.Uint32(), r.Uint32()
+
+	c.mutex.Lock()
+	name, ok := c.clientEvents[eventID]
+	c.mutex.Unlock()
+
+	if !ok {
+		c.sendException(req, simconnect_data.EXCEPTION_UNRECOGNIZED_ID, 2)
+		return
+	}
+
+	apply, ok := clientEvents[normalizeName(name)]
+	if !ok {
+		c.server.logf("ignoring unsupported event %s", name)
+		return
+	}
+
+	world := c.server.world
+	world.mutex.Lock()
+	defer world.mutex.Unlock()
+
+	if object := world.object(objectID); object != nil {
+		apply(object, data)
+	}
+}
+
+func (c *conn) handleSubscribeToSystemEvent(r *protocol.Reader) {
+	clientEventID, name := r.Uint32(), normalizeName(r.String(protocol.StringSize256))
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch name {
+	case "OBJECTADDED":
+		c.objectAdded = append(c.objectAdded, clientEventID)
+	case "OBJECTREMOVED":
+		c.objectRemoved = append(c.objectRemoved, clientEventID)
+	default:
+		if interval, ok := periodicSystemEvents[name]; ok {
+			c.systemEvents[name] = &systemEvent{
+				clientEventID: clientEventID,
+				interval:      interval,
+				next:          time.Now().Add(interval),
+			}
+		}
+	}
+}
+
+func (c *conn) handleUnsubscribeFromSystemEvent(r *protocol.Reader) {
+	clientEventID := r.Uint32()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for name, event := range c.systemEvents {
+		if event.clientEventID == clientEventID {
+			delete(c.systemEvents, name)
+		}
+	}
+	c.objectAdded = removeID(c.objectAdded, clientEventID)
+	c.objectRemoved = removeID(c.objectRemoved, clientEventID)
+	delete(c.eventsOff, clientEventID)
+}
+
+func (c *conn) handleSetSystemEventState(r *protocol.Reader) {
+	clientEventID, state := r.Uint32(), r.Uint32()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if state == simconnect_data.SIMCONNECT_STATE_OFF {
+		c.eventsOff[clientEventID] = true
+	} else {
+		delete(c.eventsOff, clientEventID)
+	}
+}
+
+func removeID(ids []uint32, id uint32) []uint32 {
+	kept := ids[:0]
+	for _, v := range ids {
+		if v != id {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// tick sends the periodic system events and data subscriptions which are due. Each tick counts as a frame.
+func (c *conn) tick(now time.Time) {
+	c.mutex.Lock()
+	var due []uint32
+	for _, event := range c.systemEvents {
+		if !now.Before(event.next) {
+			if !c.eventsOff[event.clientEventID] {
+				due = append(due, event.clientEventID)
+			}
+			event.next = event.next.Add(event.interval)
+		}
+	}
+
+	var payloads [][]byte
+	for requestID, sub := range c.subscriptions {
+		payload, done := c.subscriptionData(requestID, sub, now)
+		if payload != nil {
+			payloads = append(payloads, payload)
+		}
+		if done {
+			delete(c.subscriptions, requestID)
+		}
+	}
+	c.mutex.Unlock()
+
+	for _, eventID := range due {
+		c.sendEvent(simconnect_data.RECV_ID_EVENT, simconnect_data.UNUSED, eventID, 0)
+	}
+	for _, payload := range payloads {
+		c.send(simconnect_data.RECV_ID_SIMOBJECT_DATA, payload)
+	}
+}
+
+// subscriptionData returns the data due for sub at now, if any, and whether sub is finished. It must be called with
+// the connection mutex held.
+func (c *conn) subscriptionData(requestID uint32, sub *dataSubscription, now time.Time) ([]byte, bool) {
+	if sub.period == simconnect_data.SIMCONNECT_PERIOD_SECOND {
+		if now.Before(sub.next) {
+			return nil, false
+		}
+		sub.next = sub.next.Add(time.Second)
+	}
+	if sub.wait > 0 {
+		sub.wait--
+		return nil, false
+	}
+	sub.wait = sub.interval
+
+	definition := c.definitions[sub.defineID]
+	world := c.server.world
+	world.mutex.Lock()
+	object := world.object(sub.objectID)
+	var values [][]byte
+	if object != nil {
+		values = encodeValues(definition, object)
+	}
+	world.mutex.Unlock()
+	if object == nil {
+		return nil, true
+	}
+
+	send := allValues(values)
+	if sub.flags&simconnect_data.DATA_REQUEST_FLAG_CHANGED != 0 && sub.last != nil {
+		var changes []int
+		for i, d := range definition {
+			if i < len(sub.last) && datumChanged(d, sub.last[i], values[i]) {
+				changes = append(changes, i)
+			}
+		}
+		if len(changes) == 0 {
+			return nil, false
+		}
+		if sub.flags&simconnect_data.DATA_REQUEST_FLAG_TAGGED != 0 {
+			send = changes
+		}
+	}
+
+	// Values within the epsilon are compared with what was last sent rather than last seen, so slow drift is sent
+	if sub.last == nil {
+		sub.last = values
+	}
+	for _, i := range send {
+		sub.last[i] = values[i]
+	}
+	sub.sent++
+	payload := objectData(requestID, sub.defineID, sub.objectID, sub.flags, 1, 1, definition, values, send)
+	return payload, sub.limit > 0 && sub.sent >= sub.limit
+}
+
+// datumChanged reports whether a value of d changed from old by more than its epsilon.
+func datumChanged(d datum, old, new []byte) bool {
+	var before, after float64
+	switch d.dataType {
+	case simconnect_data.DATATYPE_INT32:
+		before, after = float64(int32(binary.LittleEndian.Uint32(old))), float64(int32(binary.LittleEndian.Uint32(new)))
+	case simconnect_data.DATATYPE_INT64:
+		before, after = float64(int64(binary.LittleEndian.Uint64(old))), float64(int64(binary.LittleEndian.Uint64(new)))
+	case simconnect_data.DATATYPE_FLOAT32:
+		before = float64(math.Float32frombits(binary.LittleEndian.Uint32(old)))
+		after = float64(math.Float32frombits(binary.LittleEndian.Uint32(new)))
+	case simconnect_data.DATATYPE_FLOAT64:
+		before = math.Float64frombits(binary.LittleEndian.Uint64(old))
+		after = math.Float64frombits(binary.LittleEndian.Uint64(new))
+	default:
+		return !bytes.Equal(old, new)
+	}
+	return math.Abs(after-before) > float64(d.epsilon)
+}
+
+// notifyObject sends the ObjectAdded or ObjectRemoved system event to every subscribed connection.
+func (server *Server) notifyObject(added bool, object *Object) {
+	server.broadcast(func(c *conn) {
+		c.mutex.Lock()
+		subscribed := c.objectRemoved
+		if added {
+			subscribed = c.objectAdded
+		}
+		var eventIDs []uint32
+		for _, eventID := range subscribed {
+			if !c.eventsOff[eventID] {
+				eventIDs = append(eventIDs, eventID)
+			}
+		}
+		c.mutex.Unlock()
+
+		for _, eventID := range eventIDs {
+			c.sendEvent(simconnect_data.RECV_ID_EVENT_OBJECT_ADDREMOVE, simconnect_data.UNUSED, eventID, object.ID, object.Type)
+		}
+	})
+}
+
+func (c *conn) createAircraft(title, tailNumber string, requestID uint32, place func(object *Object)) {
+	world := c.server.world
+	world.mutex.Lock()
+	object := world.createAircraft(title, tailNumber)
+	place(object)
+	world.mutex.Unlock()
+
+	payload := (&protocol.Builder{}).
+		Uint32(requestID).
+		Uint32(object.ID).
+		Payload()
+	c.send(simconnect_data.RECV_ID_ASSIGNED_OBJECT_ID, payload)
+
+	c.server.notifyObject(true, object)
+}
+
+func (c *conn) handleAICreateParkedATCAircraft(r *protocol.Reader) {
+	title := r.String(protocol.StringSize256)
+	tailNumber := r.String(protocol.TailNumberSize)
+	r.String(protocol.AirportICAOSize)
+	requestID := r.Uint32()
+
+	c.createAircraft(title, tailNumber, requestID, func(object *Object) {
+		user := c.server.world.object(UserObjectID)
+		object.values["PLANE LATITUDE"] = user.values["PLANE LATITUDE"]
+		object.values["PLANE LONGITUDE"] = user.values["PLANE LONGITUDE"]
+		object.values["PLANE ALTITUDE"] = user.values["PLANE ALTITUDE"] - user.values["PLANE ALT ABOVE GROUND"]
+		object.values["PLANE ALT ABOVE GROUND"] = 0
+		object.values["SIM ON GROUND"] = 1
+		object.values["PLANE IN PARKING STATE"] = 1
+		object.values["GROUND VELOCITY"] = 0
+		object.values["AIRSPEED INDICATED"] = 0
+	})
+}
+
+func (c *conn) handleAICreateNonATCAircraft(r *protocol.Reader) {
+	title := r.String(protocol.StringSize256)
+	tailNumber := r.String(protocol.TailNumberSize)
+	latitude, longitude, altitude := r.Float64(), r.Float64(), r.Float64()
+	pitch, bank, heading := r.Float64(), r.Float64(), r.Float64()
+	onGround, airspeed := r.Uint32(), r.Uint32()
+	requestID := r.Uint32()
+
+	c.createAircraft(title, tailNumber, requestID, func(object *Object) {
+		object.values["PLANE LATITUDE"] = latitude
+		object.values["PLANE LONGITUDE"] = longitude
+		object.values["PLANE ALTITUDE"] = altitude
+		object.set("PLANE PITCH DEGREES", "degrees", pitch)
+		object.set("PLANE BANK DEGREES", "degrees", bank)
+		object.set("PLANE HEADING DEGREES TRUE", "degrees", heading)
+		object.set("PLANE HEADING DEGREES MAGNETIC", "degrees", heading)
+		object.values["SIM ON GROUND"] = float64(onGround)
+		object.values["AIRSPEED INDICATED"] = float64(airspeed)
+		object.values["GROUND VELOCITY"] = float64(airspeed)
+	})
+}
+
+func (c *conn) handleAICreateEnrouteATCAircraft(r *protocol.Reader) {
+	title := r.String(protocol.StringSize256)
+	tailNumber := r.String(protocol.TailNumberSize)
+	flightNumber := r.Uint32()
+	r.String(protocol.StringSize260)
+	r.Float64()
+	r.Uint32()
+	requestID := r.Uint32()
+
+	c.createAircraft(title, tailNumber, requestID, func(object *Object) {
+		user := c.server.world.object(UserObjectID)
+		object.values["PLANE LATITUDE"] = user.values["PLANE LATITUDE"]
+		object.values["PLANE LONGITUDE"] = user.values["PLANE LONGITUDE"]
+		object.setString("ATC FLIGHT NUMBER", fmt.Sprint(flightNumber))
+	})
+}
+
+func (c *conn) handleAIRemoveObject(req protocol.Request, r *protocol.Reader) {
+	objectID := r.Uint32()
+
+	world := c.server.world
+	world.mutex.Lock()
+	object, ok := world.objects[objectID]
+	if ok && objectID != UserObjectID {
+		delete(world.objects, objectID)
+	}
+	world.mutex.Unlock()
+
+	if !ok || objectID == UserObjectID {
+		c.sendException(req, simconnect_data.EXCEPTION_UNRECOGNIZED_ID, 1)
+		return
+	}
+
+	c.server.notifyObject(false, object)
+}
+
+// dataSize returns the number of bytes used by a datum of dataType.
+func dataSize(dataType uint32) (int, bool) {
+	switch dataType {
+	case simconnect_data.DATATYPE_INT32, simconnect_data.DATATYPE_FLOAT32:
+		return 4, true
+	case simconnect_data.DATATYPE_INT64, simconnect_data.DATATYPE_FLOAT64:
+		return 8, true
+	case simconnect_data.DATATYPE_STRING8:
+		return 8, true
+	case simconnect_data.DATATYPE_STRING32:
+		return 32, true
+	case simconnect_data.DATATYPE_STRING64:
+		return 64, true
+	case simconnect_data.DATATYPE_STRING128:
+		return 128, true
+	case simconnect_data.DATATYPE_STRING256:
+		return 256, true
+	case simconnect_data.DATATYPE_STRING260:
+		return 260, true
+	case simconnect_data.DATATYPE_STRINGV:
+		return 0, true
+	case simconnect_data.DATATYPE_INITPOSITION:
+		return 56, true
+	case simconnect_data.DATATYPE_MARKERSTATE:
+		return 68, true
+	case simconnect_data.DATATYPE_WAYPOINT:
+		return 44, true
+	case simconnect_data.DATATYPE_LATLONALT, simconnect_data.DATATYPE_XYZ:
+		return 24, true
+	default:
+		return 0, false
+	}
+}
+
+func encodeDatum(b *protocol.Builder, d datum, object *Object) {
+	switch d.dataType {
+	case simconnect_data.DATATYPE_INT32:
+		b.Uint32(uint32(int32(object.get(d.name, d.unit))))
+	case simconnect_data.DATATYPE_INT64:
+		var p [8]byte
+		binary.LittleEndian.PutUint64(p[:], uint64(int64(object.get(d.name, d.unit))))
+		b.Raw(p[:])
+	case simconnect_data.DATATYPE_FLOAT32:
+		b.Float32(float32(object.get(d.name, d.unit)))
+	case simconnect_data.DATATYPE_FLOAT64:
+		b.Float64(object.get(d.name, d.unit))
+	case simconnect_data.DATATYPE_STRINGV:
+		b.StringV(object.getString(d.name))
+	case simconnect_data.DATATYPE_LATLONALT, simconnect_data.DATATYPE_XYZ:
+		for _, c := range structVars[normalizeName(d.name)] {
+			b.Float64(object.get(c.name, c.unit))
+		}
+		if _, ok := structVars[normalizeName(d.name)]; !ok {
+			b.Raw(make([]byte, 24))
+		}
+	case simconnect_data.DATATYPE_INITPOSITION:
+		for _, c := range initPosition {
+			b.Float64(object.get(c.name, c.unit))
+		}
+		b.Uint32(uint32(object.get("SIM ON GROUND", "bool")))
+		b.Uint32(uint32(object.get("AIRSPEED INDICATED", "knots")))
+	case simconnect_data.DATATYPE_MARKERSTATE, simconnect_data.DATATYPE_WAYPOINT:
+		// Not modelled
+		size, _ := dataSize(d.dataType)
+		b.Raw(make([]byte, size))
+	default:
+		size, _ := dataSize(d.dataType)
+		b.String(object.getString(d.name), size)
+	}
+}
+
+func decodeDatum(r *protocol.Reader, d datum, object *Object) {
+	switch d.dataType {
+	case simconnect_data.DATATYPE_INT32:
+		object.set(d.name, d.unit, float64(int32(r.Uint32())))
+	case simconnect_data.DATATYPE_INT64:
+		object.set(d.name, d.unit, float64(int64(binary.LittleEndian.Uint64(r.Bytes(8)))))
+	case simconnect_data.DATATYPE_FLOAT32:
+		object.set(d.name, d.unit, float64(r.Float32()))
+	case simconnect_data.DATATYPE_FLOAT64:
+		object.set(d.name, d.unit, r.Float64())
+	case simconnect_data.DATATYPE_STRINGV:
+		object.setString(d.name, r.StringV())
+	case simconnect_data.DATATYPE_LATLONALT, simconnect_data.DATATYPE_XYZ:
+		components := structVars[normalizeName(d.name)]
+		for i := 0; i < 3; i++ {
+			value := r.Float64()
+			if i < len(components) {
+				object.set(components[i].name, components[i].unit, value)
+			}
+		}
+	case simconnect_data.DATATYPE_INITPOSITION:
+		for _, c := range initPosition {
+			object.set(c.name, c.unit, r.Float64())
+		}
+		object.set("SIM ON GROUND", "bool", float64(r.Uint32()))
+		object.set("AIRSPEED INDICATED", "knots", float64(r.Uint32()))
+	case simconnect_data.DATATYPE_MARKERSTATE, simconnect_data.DATATYPE_WAYPOINT:
+		size, _ := dataSize(d.dataType)
+		r.Bytes(size)
+	default:
+		size, _ := dataSize(d.dataType)
+		object.setString(d.name, string(bytes.TrimRight(r.Bytes(size), "\x00")))
+	}
+}
@@ -0,0 +1,141 @@
+package emulator_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect "github.com/JRascagneres/Simconnect-Go"
+	"github.com/JRascagneres/Simconnect-Go/emulator"
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+func startEmulator(t *testing.T) (*emulator.Server, *simconnect.SimconnectInstance) {
+	server, err := emulator.Start("127.0.0.1:0", emulator.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	instance, err := simconnect.NewSimConnectTCP(t.Name(), server.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() { instance.Close() })
+
+	return server, instance
+}
+
+func TestEmulatorReportUnits(t *testing.T) {
+	server, instance := startEmulator(t)
+
+	require.True(t, server.World().SetSimVar(emulator.UserObjectID, "Fuel Total Quantity Weight", 220.462))
+	require.True(t, server.World().SetSimVar(emulator.UserObjectID, "Ambient Wind Direction", 180))
+
+	report, err := instance.GetReport()
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(3500), report.Altitude)
+	assert.InDelta(t, 100, report.FuelTotal, 0.01)
+	assert.InDelta(t, 3.14159, report.WindDirection, 0.0001)
+	assert.Equal(t, 118.575, report.COMActiveFrequency1)
+}
+
+func TestEmulatorClientEvents(t *testing.T) {
+	server, instance := startEmulator(t)
+
+	require.NoError(t, instance.MapClientEventToSimEvent(10, "COM_STBY_RADIO_SET_HZ"))
+	require.NoError(t, instance.MapClientEventToSimEvent(20, "COM_STBY_RADIO_SWAP"))
+	require.NoError(t, instance.MapClientEventToSimEvent(30, "AP_ALT_VAR_SET_ENGLISH"))
+	require.NoError(t, instance.TransmitClientID(10, 122800000))
+	require.NoError(t, instance.TransmitClientID(20, 0))
+	require.NoError(t, instance.TransmitClientID(30, 8000))
+
+	report, err := instance.GetAPReport()
+	require.NoError(t, err)
+	assert.Equal(t, float64(8000), report.APSelectedAlt)
+
+	active, ok := server.World().SimVar(emulator.UserObjectID, "COM ACTIVE FREQUENCY:1")
+	require.True(t, ok)
+	assert.InDelta(t, 122.8, active, 0.0001)
+}
+
+func TestEmulatorAIAircraft(t *testing.T) {
+	server, instance := startEmulator(t)
+
+	objectID, err := instance.LoadNonATCAircraft("Boeing 747-8i Asobo", "G-4210", simconnect_data.SimconnectDataInitPosition{
+		Latitude:  53.34974539799793,
+		Longitude: -2.274003348644879,
+		Altitude:  235,
+		Airspeed:  200,
+	}, 10)
+	require.NoError(t, err)
+	assert.Len(t, server.World().Objects(), 2)
+
+	err = instance.SetDataOnSimObject(*objectID, []simconnect.SetSimObjectDataExpose{{Altitude: 400, Airspeed: 180}})
+	require.NoError(t, err)
+
+	report, err := instance.GetReportOnObjectID(*objectID)
+	require.NoError(t, err)
+	assert.Equal(t, float64(400), report.Altitude)
+	assert.Equal(t, float64(180), report.Airspeed)
+
+	title, _ := server.World().StringVar(*objectID, "Title")
+	assert.Equal(t, "Boeing 747-8i Asobo", title)
+
+	require.NoError(t, instance.RemoveAIObject(*objectID, 11))
+	_, err = instance.GetReport()
+	require.NoError(t, err)
+	assert.Len(t, server.World().Objects(), 1)
+
+	_, err = instance.GetReportOnObjectID(*objectID)
+	assert.True(t, errors.Is(err, simconnect.ErrUnrecognizedID), err)
+}
+
+func TestEmulatorReconnect(t *testing.T) {
+	server, err := emulator.Start("127.0.0.1:0", emulator.Config{})
+	require.NoError(t, err)
+	address := server.Addr()
+
+	instance, err := simconnect.NewSimConnectTCP(t.Name(), address, simconnect.WithReconnect(10*time.Millisecond, 100*time.Millisecond))
+	require.NoError(t, err)
+	t.Cleanup(func() { instance.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := instance.ConnectionStateChanges(ctx)
+
+	require.NoError(t, instance.MapClientEventToSimEvent(30, "AP_ALT_VAR_SET_ENGLISH"))
+	_, err = instance.GetAPReport()
+	require.NoError(t, err)
+
+	require.NoError(t, server.Close())
+	change := <-changes
+	assert.Equal(t, simconnect.StateDisconnected, change.State)
+	assert.True(t, errors.Is(change.Err, simconnect.ErrDisconnected))
+
+	_, err = instance.GetAPReport()
+	assert.True(t, errors.Is(err, simconnect.ErrDisconnected), err)
+
+	server, err = emulator.Start(address, emulator.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	select {
+	case change = <-changes:
+		assert.Equal(t, simconnect.StateConnected, change.State)
+	case <-time.After(5 * time.Second):
+		t.Fatal("did not reconnect")
+	}
+
+	// The event mapping and data definition only exist on the new connection if they were replayed
+	require.NoError(t, instance.TransmitClientID(30, 9000))
+	report, err := instance.GetAPReport()
+	require.NoError(t, err)
+	assert.Equal(t, float64(9000), report.APSelectedAlt)
+
+	require.NoError(t, instance.Close())
+	assert.Equal(t, simconnect.StateClosed, (<-changes).State)
+	_, open := <-changes
+	assert.False(t, open)
+}
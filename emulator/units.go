@@ -0,0 +1,85 @@
+package emulator
+
+import (
+	"math"
+	"strings"
+)
+
+// unit describes how a unit name relates to the base unit of its dimension.
+type unit struct {
+	dimension string
+	factor    float64 // value in base unit = value in this unit * factor
+}
+
+// units covers the unit names commonly used with the simvars modelled by the emulator. Unknown units are treated as
+// dimensionless and left unconverted.
+var units = map[string]unit{
+	"feet":   {"length", 1},
+	"foot":   {"length", 1},
+	"ft":     {"length", 1},
+	"meters": {"length", 3.28084},
+	"meter":  {"length", 3.28084},
+	"m":      {"length", 3.28084},
+
+	"knots":               {"speed", 1},
+	"knot":                {"speed", 1},
+	"kts":                 {"speed", 1},
+	"meters per second":   {"speed", 1.943844},
+	"m/s":                 {"speed", 1.943844},
+	"feet per second":     {"speed", 0.592484},
+	"feet/second":         {"speed", 0.592484},
+	"feet per minute":     {"speed", 0.592484 / 60},
+	"feet/minute":         {"speed", 0.592484 / 60},
+	"kilometers per hour": {"speed", 0.539957},
+	"miles per hour":      {"speed", 0.868976},
+
+	"radians": {"angle", 1},
+	"radian":  {"angle", 1},
+	"degrees": {"angle", math.Pi / 180},
+	"degree":  {"angle", math.Pi / 180},
+
+	"pounds":    {"weight", 1},
+	"pound":     {"weight", 1},
+	"lbs":       {"weight", 1},
+	"kg":        {"weight", 2.20462},
+	"kilograms": {"weight", 2.20462},
+	"kilogram":  {"weight", 2.20462},
+
+	"pounds per hour":      {"massflow", 1},
+	"kilograms per second": {"massflow", 2.20462 * 3600},
+	"kilograms per hour":   {"massflow", 2.20462},
+
+	"gallons": {"volume", 1},
+	"gallon":  {"volume", 1},
+	"liters":  {"volume", 0.264172},
+	"liter":   {"volume", 0.264172},
+
+	"inhg":              {"pressure", 1},
+	"inches of mercury": {"pressure", 1},
+	"millibars":         {"pressure", 0.02953},
+	"millibar":          {"pressure", 0.02953},
+	"hectopascals":      {"pressure", 0.02953},
+
+	"mhz": {"frequency", 1},
+	"khz": {"frequency", 0.001},
+	"hz":  {"frequency", 0.000001},
+}
+
+func lookupUnit(name string) (unit, bool) {
+	u, ok := units[strings.ToLower(strings.TrimSpace(name))]
+	return u, ok
+}
+
+// convert converts value expressed in unit from to unit to. Values are returned unchanged when either unit is
+// unknown or the units measure different dimensions.
+func convert(value float64, from, to string) float64 {
+	fromUnit, ok := lookupUnit(from)
+	if !ok {
+		return value
+	}
+	toUnit, ok := lookupUnit(to)
+	if !ok || fromUnit.dimension != toUnit.dimension {
+		return value
+	}
+	return value * fromUnit.factor / toUnit.factor
+}
@@ -0,0 +1,343 @@
+package emulator
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// simVar is a simulation variable known to the emulator along with the unit its value is stored in.
+type simVar struct {
+	unit         string
+	defaultValue float64
+}
+
+// simVars lists the numeric simulation variables modelled for every aircraft, covering the Report and APReport
+// structs of the library. Values are stored in the unit given here and converted to the requested unit on read.
+var simVars = map[string]simVar{
+	"KOHLSMAN SETTING HG":             {"inHg", 29.92},
+	"PLANE ALTITUDE":                  {"feet", 3500},
+	"PLANE ALT ABOVE GROUND":          {"feet", 3250},
+	"PLANE LATITUDE":                  {"degrees", 53.3537},
+	"PLANE LONGITUDE":                 {"degrees", -2.2750},
+	"AIRSPEED INDICATED":              {"knots", 110},
+	"AIRSPEED TRUE":                   {"knots", 116},
+	"AIRSPEED BARBER POLE":            {"knots", 163},
+	"GROUND VELOCITY":                 {"knots", 115},
+	"SIM ON GROUND":                   {"bool", 0},
+	"PLANE HEADING DEGREES TRUE":      {"radians", 0},
+	"PLANE HEADING DEGREES MAGNETIC":  {"radians", 0},
+	"PLANE PITCH DEGREES":             {"radians", 0},
+	"PLANE BANK DEGREES":              {"radians", 0},
+	"G FORCE":                         {"gforce", 1},
+	"VELOCITY WORLD X":                {"feet per second", 0},
+	"VELOCITY WORLD Y":                {"feet per second", 0},
+	"VELOCITY WORLD Z":                {"feet per second", 0},
+	"VERTICAL SPEED":                  {"feet per second", 0},
+	"PLANE TOUCHDOWN NORMAL VELOCITY": {"feet per second", 0},
+	"AIRCRAFT WIND X":                 {"knots", 0},
+	"AIRCRAFT WIND Z":                 {"knots", 0},
+	"FUEL TOTAL QUANTITY WEIGHT":      {"pounds", 318},
+	"FUEL TOTAL CAPACITY":             {"gallons", 56},
+	"FUEL WEIGHT PER GALLON":          {"pounds", 6},
+	"ESTIMATED FUEL FLOW":             {"pounds per hour", 50},
+	"AMBIENT WIND VELOCITY":           {"knots", 0},
+	"AMBIENT WIND DIRECTION":          {"degrees", 0},
+	"AMBIENT TEMPERATURE":             {"celsius", 15},
+	"AMBIENT PRESSURE":                {"inHg", 29.92},
+	"PLANE IN PARKING STATE":          {"bool", 0},
+	"GENERAL ENG COMBUSTION:1":        {"bool", 1},
+	"GENERAL ENG COMBUSTION:2":        {"bool", 0},
+	"GENERAL ENG COMBUSTION:3":        {"bool", 0},
+	"GENERAL ENG COMBUSTION:4":        {"bool", 0},
+	"NUMBER OF ENGINES":               {"number", 1},
+	"ADF STANDBY FREQUENCY:1":         {"MHz", 0.350},
+	"ADF ACTIVE FREQUENCY:1":          {"MHz", 0.334},
+	"ADF STANDBY FREQUENCY:2":         {"MHz", 0.350},
+	"ADF ACTIVE FREQUENCY:2":          {"MHz", 0.334},
+	"COM STANDBY FREQUENCY:1":         {"MHz", 121.500},
+	"COM ACTIVE FREQUENCY:1":          {"MHz", 118.575},
+	"COM STANDBY FREQUENCY:2":         {"MHz", 121.500},
+	"COM ACTIVE FREQUENCY:2":          {"MHz", 124.125},
+	"NAV STANDBY FREQUENCY:1":         {"MHz", 110.500},
+	"NAV ACTIVE FREQUENCY:1":          {"MHz", 113.550},
+	"NAV STANDBY FREQUENCY:2":         {"MHz", 110.500},
+	"NAV ACTIVE FREQUENCY:2":          {"MHz", 113.550},
+	"AUTOPILOT ALTITUDE LOCK VAR":     {"feet", 0},
+	"AUTOPILOT ALTITUDE LOCK VAR:1":   {"feet", 0},
+	"AUTOPILOT ALTITUDE LOCK VAR:2":   {"feet", 0},
+	"AUTOPILOT ALTITUDE LOCK VAR:3":   {"feet", 0},
+	"AUTOPILOT ALTITUDE SLOT INDEX":   {"number", 1},
+}
+
+// stringVars lists the string simulation variables modelled for every aircraft.
+var stringVars = map[string]string{
+	"TITLE":             "Cessna Skyhawk G1000 Asobo",
+	"ATC AIRLINE":       "",
+	"ATC FLIGHT NUMBER": "",
+	"ATC ID":            "N172SP",
+	"ATC MODEL":         "C172",
+	"ATC TYPE":          "Cessna",
+}
+
+// component is a numeric simvar making up part of a struct simvar, read and written in unit.
+type component struct {
+	name string
+	unit string
+}
+
+// structVars lists the simvars returned as a SIMCONNECT_DATA_LATLONALT or SIMCONNECT_DATA_XYZ, made up of the numeric
+// simvars modelled for every aircraft.
+var structVars = map[string][]component{
+	"STRUCT LATLONALT":     {{"PLANE LATITUDE", "degrees"}, {"PLANE LONGITUDE", "degrees"}, {"PLANE ALTITUDE", "feet"}},
+	"STRUCT WORLDVELOCITY": {{"VELOCITY WORLD X", "feet per second"}, {"VELOCITY WORLD Y", "feet per second"}, {"VELOCITY WORLD Z", "feet per second"}},
+}
+
+// initPosition lists the components of the SIMCONNECT_DATA_INITPOSITION written to INITIAL POSITION, ahead of its
+// OnGround and Airspeed DWORDs.
+var initPosition = []component{
+	{"PLANE LATITUDE", "degrees"},
+	{"PLANE LONGITUDE", "degrees"},
+	{"PLANE ALTITUDE", "feet"},
+	{"PLANE PITCH DEGREES", "degrees"},
+	{"PLANE BANK DEGREES", "degrees"},
+	{"PLANE HEADING DEGREES TRUE", "degrees"},
+}
+
+// normalizeName returns the key used to store a simvar, simvar names being case insensitive.
+func normalizeName(name string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(name), " "))
+}
+
+func isKnownSimVar(name string) bool {
+	name = normalizeName(name)
+	_, numeric := simVars[name]
+	_, str := stringVars[name]
+	_, structure := structVars[name]
+	return numeric || str || structure || name == "INITIAL POSITION"
+}
+
+// Object is a simulated object: the user aircraft or an AI object.
+type Object struct {
+	ID   uint32
+	Type uint32 // SIMOBJECT_TYPE_*
+
+	values  map[string]float64
+	strings map[string]string
+}
+
+func newObject(id, objectType uint32) *Object {
+	object := &Object{
+		ID:      id,
+		Type:    objectType,
+		values:  map[string]float64{},
+		strings: map[string]string{},
+	}
+	for name, v := range simVars {
+		object.values[name] = v.defaultValue
+	}
+	for name, v := range stringVars {
+		object.strings[name] = v
+	}
+	return object
+}
+
+// get returns the value of a numeric simvar converted to unit.
+func (object *Object) get(name, unit string) float64 {
+	name = normalizeName(name)
+	value := object.values[name]
+	if v, ok := simVars[name]; ok {
+		value = convert(value, v.unit, unit)
+	}
+	return value
+}
+
+// set stores value, expressed in unit, in a numeric simvar.
+func (object *Object) set(name, unit string, value float64) {
+	name = normalizeName(name)
+	if v, ok := simVars[name]; ok {
+		value = convert(value, unit, v.unit)
+	}
+	object.values[name] = value
+}
+
+func (object *Object) getString(name string) string {
+	return object.strings[normalizeName(name)]
+}
+
+func (object *Object) setString(name, value string) {
+	object.strings[normalizeName(name)] = value
+}
+
+// World is the simulation shared by every connection to a Server.
+type World struct {
+	mutex        sync.Mutex
+	objects      map[uint32]*Object
+	nextObjectID uint32
+}
+
+// UserObjectID is the object ID of the user aircraft.
+const UserObjectID uint32 = 1
+
+func newWorld() *World {
+	world := &World{
+		objects:      map[uint32]*Object{},
+		nextObjectID: UserObjectID + 1,
+	}
+	world.objects[UserObjectID] = newObject(UserObjectID, simconnect_data.SIMOBJECT_TYPE_AIRCRAFT)
+	return world
+}
+
+// object resolves objectID, 0 meaning the user aircraft. Must be called with the mutex held.
+func (world *World) object(objectID uint32) *Object {
+	if objectID == 0 {
+		objectID = UserObjectID
+	}
+	return world.objects[objectID]
+}
+
+// SimVar returns the value of a numeric simvar of objectID in its stored unit.
+func (world *World) SimVar(objectID uint32, name string) (float64, bool) {
+	world.mutex.Lock()
+	defer world.mutex.Unlock()
+
+	object := world.object(objectID)
+	if object == nil {
+		return 0, false
+	}
+	value, ok := object.values[normalizeName(name)]
+	return value, ok
+}
+
+// SetSimVar sets a numeric simvar of objectID, value being in the stored unit.
+func (world *World) SetSimVar(objectID uint32, name string, value float64) bool {
+	world.mutex.Lock()
+	defer world.mutex.Unlock()
+
+	object := world.object(objectID)
+	if object == nil {
+		return false
+	}
+	object.values[normalizeName(name)] = value
+	return true
+}
+
+// StringVar returns the value of a string simvar of objectID.
+func (world *World) StringVar(objectID uint32, name string) (string, bool) {
+	world.mutex.Lock()
+	defer world.mutex.Unlock()
+
+	object := world.object(objectID)
+	if object == nil {
+		return "", false
+	}
+	value, ok := object.strings[normalizeName(name)]
+	return value, ok
+}
+
+// Objects returns the IDs of every object in the world.
+func (world *World) Objects() []uint32 {
+	world.mutex.Lock()
+	defer world.mutex.Unlock()
+
+	ids := make([]uint32, 0, len(world.objects))
+	for id := range world.objects {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// createAircraft adds an AI aircraft to the world and returns it. Must be called with the mutex held.
+func (world *World) createAircraft(title, tailNumber string) *Object {
+	object := newObject(world.nextObjectID, simconnect_data.SIMOBJECT_TYPE_AIRCRAFT)
+	world.nextObjectID++
+
+	object.setString("TITLE", title)
+	object.setString("ATC ID", tailNumber)
+	world.objects[object.ID] = object
+	return object
+}
+
+// step advances every airborne object along its heading at its ground speed.
+func (world *World) step(elapsed time.Duration) {
+	world.mutex.Lock()
+	defer world.mutex.Unlock()
+
+	for _, object := range world.objects {
+		if object.values["SIM ON GROUND"] != 0 {
+			continue
+		}
+
+		// Knots to degrees of latitude per second: 1 knot is 1/60 of a degree of latitude per hour.
+		distance := object.values["GROUND VELOCITY"] / 60 / 3600 * elapsed.Seconds()
+		heading := object.values["PLANE HEADING DEGREES TRUE"]
+		latitude := object.values["PLANE LATITUDE"]
+
+		object.values["PLANE LATITUDE"] = latitude + distance*math.Cos(heading)
+		object.values["PLANE LONGITUDE"] += distance * math.Sin(heading) / math.Cos(latitude*math.Pi/180)
+		object.values["PLANE ALTITUDE"] += object.values["VERTICAL SPEED"] * elapsed.Seconds()
+	}
+}
+
+// distance returns the great circle distance in meters between two objects.
+func distance(a, b *Object) float64 {
+	const earthRadius = 6371000
+
+	lat1 := a.values["PLANE LATITUDE"] * math.Pi / 180
+	lat2 := b.values["PLANE LATITUDE"] * math.Pi / 180
+	deltaLat := lat2 - lat1
+	deltaLon := (b.values["PLANE LONGITUDE"] - a.values["PLANE LONGITUDE"]) * math.Pi / 180
+
+	h := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// clientEvents applies the sim events understood by the emulator to the target object.
+var clientEvents = map[string]func(object *Object, data uint32){
+	"COM_STBY_RADIO_SET_HZ": func(object *Object, data uint32) {
+		object.set("COM STANDBY FREQUENCY:1", "Hz", float64(data))
+	},
+	"COM2_STBY_RADIO_SET_HZ": func(object *Object, data uint32) {
+		object.set("COM STANDBY FREQUENCY:2", "Hz", float64(data))
+	},
+	"COM_RADIO_SET_HZ": func(object *Object, data uint32) {
+		object.set("COM ACTIVE FREQUENCY:1", "Hz", float64(data))
+	},
+	"COM2_RADIO_SET_HZ": func(object *Object, data uint32) {
+		object.set("COM ACTIVE FREQUENCY:2", "Hz", float64(data))
+	},
+	"COM_STBY_RADIO_SWAP": func(object *Object, data uint32) {
+		swap(object, "COM ACTIVE FREQUENCY:1", "COM STANDBY FREQUENCY:1")
+	},
+	"COM2_RADIO_SWAP": func(object *Object, data uint32) {
+		swap(object, "COM ACTIVE FREQUENCY:2", "COM STANDBY FREQUENCY:2")
+	},
+	"NAV1_RADIO_SWAP": func(object *Object, data uint32) {
+		swap(object, "NAV ACTIVE FREQUENCY:1", "NAV STANDBY FREQUENCY:1")
+	},
+	"NAV2_RADIO_SWAP": func(object *Object, data uint32) {
+		swap(object, "NAV ACTIVE FREQUENCY:2", "NAV STANDBY FREQUENCY:2")
+	},
+	"COM_RADIO_FRACT_INC": func(object *Object, data uint32) {
+		object.values["COM ACTIVE FREQUENCY:1"] += 0.025
+	},
+	"COM_RADIO_FRACT_DEC": func(object *Object, data uint32) {
+		object.values["COM ACTIVE FREQUENCY:1"] -= 0.025
+	},
+	"AP_ALT_VAR_SET_ENGLISH": func(object *Object, data uint32) {
+		for _, name := range []string{"AUTOPILOT ALTITUDE LOCK VAR", "AUTOPILOT ALTITUDE LOCK VAR:1", "AUTOPILOT ALTITUDE LOCK VAR:2", "AUTOPILOT ALTITUDE LOCK VAR:3"} {
+			object.values[name] = float64(data)
+		}
+	},
+	"KOHLSMAN_SET": func(object *Object, data uint32) {
+		// Data is the pressure in millibars * 16.
+		object.set("KOHLSMAN SETTING HG", "millibars", float64(data)/16)
+	},
+}
+
+func swap(object *Object, a, b string) {
+	object.values[a], object.values[b] = object.values[b], object.values[a]
+}
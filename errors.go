@@ -0,0 +1,144 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// Sentinels matching a SimConnectError with the corresponding exception through errors.Is.
+var (
+	ErrNone                          = errors.New("no exception")
+	ErrException                     = errors.New("SimConnect error")
+	ErrSizeMismatch                  = errors.New("size mismatch")
+	ErrUnrecognizedID                = errors.New("unrecognized ID")
+	ErrUnopened                      = errors.New("connection not opened")
+	ErrVersionMismatch               = errors.New("version mismatch")
+	ErrTooManyGroups                 = errors.New("too many groups")
+	ErrNameUnrecognized              = errors.New("name unrecognized")
+	ErrTooManyEventNames             = errors.New("too many event names")
+	ErrEventIDDuplicate              = errors.New("event ID duplicate")
+	ErrTooManyMaps                   = errors.New("too many maps")
+	ErrTooManyObjects                = errors.New("too many objects")
+	ErrTooManyRequests               = errors.New("too many requests")
+	ErrWeatherInvalidPort            = errors.New("weather invalid port")
+	ErrWeatherInvalidMETAR           = errors.New("weather invalid METAR")
+	ErrWeatherUnableToGetObservation = errors.New("weather unable to get observation")
+	ErrWeatherUnableToCreateStation  = errors.New("weather unable to create station")
+	ErrWeatherUnableToRemoveStation  = errors.New("weather unable to remove station")
+	ErrInvalidDataType               = errors.New("invalid data type")
+	ErrInvalidDataSize               = errors.New("invalid data size")
+	ErrDataError                     = errors.New("data error")
+	ErrInvalidArray                  = errors.New("invalid array")
+	ErrCreateObjectFailed            = errors.New("create object failed")
+	ErrLoadFlightPlanFailed          = errors.New("load flight plan failed")
+	ErrOperationInvalidForObjectType = errors.New("operation invalid for object type")
+	ErrIllegalOperation              = errors.New("illegal operation")
+	ErrAlreadySubscribed             = errors.New("already subscribed")
+	ErrInvalidEnum                   = errors.New("invalid enum")
+	ErrDefinitionError               = errors.New("definition error")
+	ErrDuplicateID                   = errors.New("duplicate ID")
+	ErrDatumID                       = errors.New("datum ID")
+	ErrOutOfBounds                   = errors.New("out of bounds")
+	ErrAlreadyCreated                = errors.New("already created")
+	ErrObjectOutsideRealityBubble    = errors.New("object outside reality bubble")
+	ErrObjectContainer               = errors.New("object container")
+	ErrObjectAI                      = errors.New("object AI")
+	ErrObjectATC                     = errors.New("object ATC")
+	ErrObjectSchedule                = errors.New("object schedule")
+	ErrJetwayData                    = errors.New("jetway data")
+	ErrActionNotFound                = errors.New("action not found")
+	ErrNotAnAction                   = errors.New("not an action")
+	ErrIncorrectActionParams         = errors.New("incorrect action params")
+	ErrGetInputEventFailed           = errors.New("get input event failed")
+	ErrSetInputEventFailed           = errors.New("set input event failed")
+)
+
+var exceptionErrors = map[uint32]error{
+	simconnect_data.EXCEPTION_NONE:                              ErrNone,
+	simconnect_data.EXCEPTION_ERROR:                             ErrException,
+	simconnect_data.EXCEPTION_SIZE_MISMATCH:                     ErrSizeMismatch,
+	simconnect_data.EXCEPTION_UNRECOGNIZED_ID:                   ErrUnrecognizedID,
+	simconnect_data.EXCEPTION_UNOPENED:                          ErrUnopened,
+	simconnect_data.EXCEPTION_VERSION_MISMATCH:                  ErrVersionMismatch,
+	simconnect_data.EXCEPTION_TOO_MANY_GROUPS:                   ErrTooManyGroups,
+	simconnect_data.EXCEPTION_NAME_UNRECOGNIZED:                 ErrNameUnrecognized,
+	simconnect_data.EXCEPTION_TOO_MANY_EVENT_NAMES:              ErrTooManyEventNames,
+	simconnect_data.EXCEPTION_EVENT_ID_DUPLICATE:                ErrEventIDDuplicate,
+	simconnect_data.EXCEPTION_TOO_MANY_MAPS:                     ErrTooManyMaps,
+	simconnect_data.EXCEPTION_TOO_MANY_OBJECTS:                  ErrTooManyObjects,
+	simconnect_data.EXCEPTION_TOO_MANY_REQUESTS:                 ErrTooManyRequests,
+	simconnect_data.EXCEPTION_WEATHER_INVALID_PORT:              ErrWeatherInvalidPort,
+	simconnect_data.EXCEPTION_WEATHER_INVALID_METAR:             ErrWeatherInvalidMETAR,
+	simconnect_data.EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION: ErrWeatherUnableToGetObservation,
+	simconnect_data.EXCEPTION_WEATHER_UNABLE_TO_CREATE_STATION:  ErrWeatherUnableToCreateStation,
+	simconnect_data.EXCEPTION_WEATHER_UNABLE_TO_REMOVE_STATION:  ErrWeatherUnableToRemoveStation,
+	simconnect_data.EXCEPTION_INVALID_DATA_TYPE:                 ErrInvalidDataType,
+	simconnect_data.EXCEPTION_INVALID_DATA_SIZE:                 ErrInvalidDataSize,
+	simconnect_data.EXCEPTION_DATA_ERROR:                        ErrDataError,
+	simconnect_data.EXCEPTION_INVALID_ARRAY:                     ErrInvalidArray,
+	simconnect_data.EXCEPTION_CREATE_OBJECT_FAILED:              ErrCreateObjectFailed,
+	simconnect_data.EXCEPTION_LOAD_FLIGHTPLAN_FAILED:            ErrLoadFlightPlanFailed,
+	simconnect_data.EXCEPTION_OPERATION_INVALID_FOR_OBJECT_TYPE: ErrOperationInvalidForObjectType,
+	simconnect_data.EXCEPTION_ILLEGAL_OPERATION:                 ErrIllegalOperation,
+	simconnect_data.EXCEPTION_ALREADY_SUBSCRIBED:                ErrAlreadySubscribed,
+	simconnect_data.EXCEPTION_INVALID_ENUM:                      ErrInvalidEnum,
+	simconnect_data.EXCEPTION_DEFINITION_ERROR:                  ErrDefinitionError,
+	simconnect_data.EXCEPTION_DUPLICATE_ID:                      ErrDuplicateID,
+	simconnect_data.EXCEPTION_DATUM_ID:                          ErrDatumID,
+	simconnect_data.EXCEPTION_OUT_OF_BOUNDS:                     ErrOutOfBounds,
+	simconnect_data.EXCEPTION_ALREADY_CREATED:                   ErrAlreadyCreated,
+	simconnect_data.EXCEPTION_OBJECT_OUTSIDE_REALITY_BUBBLE:     ErrObjectOutsideRealityBubble,
+	simconnect_data.EXCEPTION_OBJECT_CONTAINER:                  ErrObjectContainer,
+	simconnect_data.EXCEPTION_OBJECT_AI:                         ErrObjectAI,
+	simconnect_data.EXCEPTION_OBJECT_ATC:                        ErrObjectATC,
+	simconnect_data.EXCEPTION_OBJECT_SCHEDULE:                   ErrObjectSchedule,
+	simconnect_data.EXCEPTION_JETWAY_DATA:                       ErrJetwayData,
+	simconnect_data.EXCEPTION_ACTION_NOT_FOUND:                  ErrActionNotFound,
+	simconnect_data.EXCEPTION_NOT_AN_ACTION:                     ErrNotAnAction,
+	simconnect_data.EXCEPTION_INCORRECT_ACTION_PARAMS:           ErrIncorrectActionParams,
+	simconnect_data.EXCEPTION_GET_INPUT_EVENT_FAILED:            ErrGetInputEventFailed,
+	simconnect_data.EXCEPTION_SET_INPUT_EVENT_FAILED:            ErrSetInputEventFailed,
+}
+
+// ExceptionName returns the name of a SIMCONNECT_EXCEPTION, such as NAME_UNRECOGNIZED.
+func ExceptionName(exception uint32) string {
+	name := simconnect_data.Exception(exception).String()
+	if !strings.HasPrefix(name, "EXCEPTION_") {
+		return fmt.Sprintf("EXCEPTION_%d", exception)
+	}
+	return strings.TrimPrefix(name, "EXCEPTION_")
+}
+
+// SimConnectError is an exception sent by the simulator in response to a call. Method names the call it was matched
+// to through its SendID, empty when the call could not be identified. Index is the 1-based index of the parameter
+// at fault, where known.
+type SimConnectError struct {
+	Exception uint32
+	SendID    uint32
+	Index     uint32
+	Method    string
+}
+
+func (e *SimConnectError) Error() string {
+	if e.Method == "" {
+		return fmt.Sprintf("received exception %s for send ID %d parameter %d", ExceptionName(e.Exception), e.SendID, e.Index)
+	}
+	return fmt.Sprintf("%s rejected with exception %s for parameter %d", e.Method, ExceptionName(e.Exception), e.Index)
+}
+
+// Is reports whether target is the sentinel error of the exception, for example ErrNameUnrecognized.
+func (e *SimConnectError) Is(target error) bool {
+	sentinel, ok := exceptionErrors[e.Exception]
+	return ok && sentinel == target
+}
+
+func newSimConnectError(exception simconnect_data.RecvException) *SimConnectError {
+	return &SimConnectError{
+		Exception: exception.Exception,
+		SendID:    exception.SendID,
+		Index:     exception.Index,
+	}
+}
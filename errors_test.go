@@ -0,0 +1,101 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+func TestSimConnectError(t *testing.T) {
+	err := &SimConnectError{Exception: simconnect_data.EXCEPTION_NAME_UNRECOGNIZED, SendID: 12, Index: 2}
+	assert.EqualError(t, err, "received exception NAME_UNRECOGNIZED for send ID 12 parameter 2")
+
+	err.Method = "AddToDataDefinition"
+	assert.EqualError(t, err, "AddToDataDefinition rejected with exception NAME_UNRECOGNIZED for parameter 2")
+
+	wrapped := fmt.Errorf("registering Report: %w", err)
+	assert.True(t, errors.Is(wrapped, ErrNameUnrecognized))
+	assert.False(t, errors.Is(wrapped, ErrUnrecognizedID))
+
+	var simConnectErr *SimConnectError
+	assert.True(t, errors.As(wrapped, &simConnectErr))
+	assert.Equal(t, uint32(12), simConnectErr.SendID)
+}
+
+func TestExceptionName(t *testing.T) {
+	assert.Equal(t, "UNRECOGNIZED_ID", ExceptionName(simconnect_data.EXCEPTION_UNRECOGNIZED_ID))
+	assert.Equal(t, "OBJECT_SCHEDULE", ExceptionName(simconnect_data.EXCEPTION_OBJECT_SCHEDULE))
+	assert.Equal(t, "SET_INPUT_EVENT_FAILED", ExceptionName(simconnect_data.EXCEPTION_SET_INPUT_EVENT_FAILED))
+	assert.Equal(t, "EXCEPTION_99", ExceptionName(99))
+}
+
+func TestExceptionSentinels(t *testing.T) {
+	sentinels := map[uint32]error{
+		simconnect_data.EXCEPTION_NONE:                              ErrNone,
+		simconnect_data.EXCEPTION_ERROR:                             ErrException,
+		simconnect_data.EXCEPTION_SIZE_MISMATCH:                     ErrSizeMismatch,
+		simconnect_data.EXCEPTION_UNRECOGNIZED_ID:                   ErrUnrecognizedID,
+		simconnect_data.EXCEPTION_UNOPENED:                          ErrUnopened,
+		simconnect_data.EXCEPTION_VERSION_MISMATCH:                  ErrVersionMismatch,
+		simconnect_data.EXCEPTION_TOO_MANY_GROUPS:                   ErrTooManyGroups,
+		simconnect_data.EXCEPTION_NAME_UNRECOGNIZED:                 ErrNameUnrecognized,
+		simconnect_data.EXCEPTION_TOO_MANY_EVENT_NAMES:              ErrTooManyEventNames,
+		simconnect_data.EXCEPTION_EVENT_ID_DUPLICATE:                ErrEventIDDuplicate,
+		simconnect_data.EXCEPTION_TOO_MANY_MAPS:                     ErrTooManyMaps,
+		simconnect_data.EXCEPTION_TOO_MANY_OBJECTS:                  ErrTooManyObjects,
+		simconnect_data.EXCEPTION_TOO_MANY_REQUESTS:                 ErrTooManyRequests,
+		simconnect_data.EXCEPTION_WEATHER_INVALID_PORT:              ErrWeatherInvalidPort,
+		simconnect_data.EXCEPTION_WEATHER_INVALID_METAR:             ErrWeatherInvalidMETAR,
+		simconnect_data.EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION: ErrWeatherUnableToGetObservation,
+		simconnect_data.EXCEPTION_WEATHER_UNABLE_TO_CREATE_STATION:  ErrWeatherUnableToCreateStation,
+		simconnect_data.EXCEPTION_WEATHER_UNABLE_TO_REMOVE_STATION:  ErrWeatherUnableToRemoveStation,
+		simconnect_data.EXCEPTION_INVALID_DATA_TYPE:                 ErrInvalidDataType,
+		simconnect_data.EXCEPTION_INVALID_DATA_SIZE:                 ErrInvalidDataSize,
+		simconnect_data.EXCEPTION_DATA_ERROR:                        ErrDataError,
+		simconnect_data.EXCEPTION_INVALID_ARRAY:                     ErrInvalidArray,
+		simconnect_data.EXCEPTION_CREATE_OBJECT_FAILED:              ErrCreateObjectFailed,
+		simconnect_data.EXCEPTION_LOAD_FLIGHTPLAN_FAILED:            ErrLoadFlightPlanFailed,
+		simconnect_data.EXCEPTION_OPERATION_INVALID_FOR_OBJECT_TYPE: ErrOperationInvalidForObjectType,
+		simconnect_data.EXCEPTION_ILLEGAL_OPERATION:                 ErrIllegalOperation,
+		simconnect_data.EXCEPTION_ALREADY_SUBSCRIBED:                ErrAlreadySubscribed,
+		simconnect_data.EXCEPTION_INVALID_ENUM:                      ErrInvalidEnum,
+		simconnect_data.EXCEPTION_DEFINITION_ERROR:                  ErrDefinitionError,
+		simconnect_data.EXCEPTION_DUPLICATE_ID:                      ErrDuplicateID,
+		simconnect_data.EXCEPTION_DATUM_ID:                          ErrDatumID,
+		simconnect_data.EXCEPTION_OUT_OF_BOUNDS:                     ErrOutOfBounds,
+		simconnect_data.EXCEPTION_ALREADY_CREATED:                   ErrAlreadyCreated,
+		simconnect_data.EXCEPTION_OBJECT_OUTSIDE_REALITY_BUBBLE:     ErrObjectOutsideRealityBubble,
+		simconnect_data.EXCEPTION_OBJECT_CONTAINER:                  ErrObjectContainer,
+		simconnect_data.EXCEPTION_OBJECT_AI:                         ErrObjectAI,
+		simconnect_data.EXCEPTION_OBJECT_ATC:                        ErrObjectATC,
+		simconnect_data.EXCEPTION_OBJECT_SCHEDULE:                   ErrObjectSchedule,
+		simconnect_data.EXCEPTION_JETWAY_DATA:                       ErrJetwayData,
+		simconnect_data.EXCEPTION_ACTION_NOT_FOUND:                  ErrActionNotFound,
+		simconnect_data.EXCEPTION_NOT_AN_ACTION:                     ErrNotAnAction,
+		simconnect_data.EXCEPTION_INCORRECT_ACTION_PARAMS:           ErrIncorrectActionParams,
+		simconnect_data.EXCEPTION_GET_INPUT_EVENT_FAILED:            ErrGetInputEventFailed,
+		simconnect_data.EXCEPTION_SET_INPUT_EVENT_FAILED:            ErrSetInputEventFailed,
+	}
+	// Every exception of the header has a sentinel
+	exceptions := 0
+	for simconnect_data.Exception(exceptions).String() != fmt.Sprintf("Exception(%d)", exceptions) {
+		exceptions++
+	}
+	require.Len(t, sentinels, exceptions)
+
+	for exception, sentinel := range sentinels {
+		err := fmt.Errorf("wrapped: %w", &SimConnectError{Exception: exception})
+		assert.True(t, errors.Is(err, sentinel), "%s is not %v", ExceptionName(exception), sentinel)
+		for other, otherSentinel := range sentinels {
+			if other != exception {
+				assert.False(t, errors.Is(err, otherSentinel), "%s is %v", ExceptionName(exception), otherSentinel)
+			}
+		}
+	}
+	assert.False(t, errors.Is(&SimConnectError{Exception: uint32(exceptions)}, ErrException))
+}
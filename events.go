@@ -0,0 +1,150 @@
+package simconnect
+
+import (
+	"context"
+	"fmt"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// Event is a client or system event received from the simulator. The messages extending RecvEvent, such as
+// RECV_ID_EVENT_OBJECT_ADDREMOVE and RECV_ID_EVENT_FILENAME, are events too and are kept whole in Message.
+type Event struct {
+	simconnect_data.RecvEvent
+	// Name is the system event subscribed to, or sim event mapped to, with the event ID, if any
+	Name    string
+	Message []byte
+}
+
+// SubscribeToSystemEvent asks the simulator to send the system event eventName, such as "1sec", "Pause" or
+// "ObjectAdded", with eventID. The events are received through Events.
+func (instance *SimconnectInstance) SubscribeToSystemEvent(eventID uint32, eventName string) error {
+	err := instance.send(sentPacket{method: "SubscribeToSystemEvent"}, func(transport Transport) error {
+		return transport.SubscribeToSystemEvent(eventID, eventName)
+	})
+	if err != nil {
+		return err
+	}
+
+	instance.recordEvent(instance.systemEvents, eventID, eventName)
+	return nil
+}
+
+// UnsubscribeFromSystemEvent stops the system event subscribed to with eventID, by SubscribeToSystemEvent or
+// OnSystemEvent.
+func (instance *SimconnectInstance) UnsubscribeFromSystemEvent(eventID uint32) error {
+	instance.eventMapMutex.Lock()
+	stop := instance.eventHandlers[eventID]
+	delete(instance.eventHandlers, eventID)
+	delete(instance.systemEvents, eventID)
+	delete(instance.systemEventStates, eventID)
+	instance.eventMapMutex.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+
+	return instance.send(sentPacket{method: "UnsubscribeFromSystemEvent"}, func(transport Transport) error {
+		return transport.UnsubscribeFromSystemEvent(eventID)
+	})
+}
+
+// SetSystemEventState turns the system event subscribed to with eventID off or back on, state being
+// simconnect_data.SIMCONNECT_STATE_OFF or SIMCONNECT_STATE_ON.
+func (instance *SimconnectInstance) SetSystemEventState(eventID, state uint32) error {
+	err := instance.send(sentPacket{method: "SetSystemEventState"}, func(transport Transport) error {
+		return transport.SetSystemEventState(eventID, state)
+	})
+	if err != nil {
+		return err
+	}
+
+	instance.eventMapMutex.Lock()
+	defer instance.eventMapMutex.Unlock()
+
+	if _, ok := instance.systemEvents[eventID]; ok {
+		instance.systemEventStates[eventID] = state
+	}
+	return nil
+}
+
+// Events returns a channel receiving every event sent by the simulator until ctx is done or the instance stops, when
+// it is closed. Events arriving faster than they are received from the channel are dropped.
+func (instance *SimconnectInstance) Events(ctx context.Context) <-chan Event {
+	return instance.events(ctx, 0, true)
+}
+
+// OnSystemEvent subscribes to the system event eventName and calls handler with each of its events, one at a time
+// from a goroutine of its own, until UnsubscribeFromSystemEvent is called with the event ID returned or the instance
+// stops.
+func (instance *SimconnectInstance) OnSystemEvent(eventName string, handler func(Event)) (uint32, error) {
+	eventID := instance.newEventID()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := instance.events(ctx, eventID, false)
+
+	instance.eventMapMutex.Lock()
+	instance.eventHandlers[eventID] = cancel
+	instance.eventMapMutex.Unlock()
+
+	if err := instance.SubscribeToSystemEvent(eventID, eventName); err != nil {
+		instance.eventMapMutex.Lock()
+		delete(instance.eventHandlers, eventID)
+		instance.eventMapMutex.Unlock()
+		cancel()
+		return 0, fmt.Errorf("error subscribing to %s: %w", eventName, err)
+	}
+
+	go func() {
+		for event := range events {
+			handler(event)
+		}
+	}()
+
+	return eventID, nil
+}
+
+// events delivers the events with eventID, or all of them, on the channel returned until ctx is done.
+func (instance *SimconnectInstance) events(ctx context.Context, eventID uint32, all bool) <-chan Event {
+	l := instance.listen(eventID, true, all)
+	events := make(chan Event, listenerBufferSize)
+
+	go func() {
+		defer close(events)
+		defer instance.unlisten(l)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case result := <-l.results:
+				if result.err != nil {
+					return
+				}
+				recvEvent, err := simconnect_data.DecodeRecvEvent(result.data)
+				if err != nil {
+					continue
+				}
+
+				event := Event{RecvEvent: *recvEvent, Name: instance.eventName(recvEvent.EventID), Message: append([]byte(nil), result.data...)}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events
+}
+
+func (instance *SimconnectInstance) eventName(eventID uint32) string {
+	instance.eventMapMutex.Lock()
+	defer instance.eventMapMutex.Unlock()
+
+	if name, ok := instance.systemEvents[eventID]; ok {
+		return name
+	}
+	return instance.clientEvents[eventID]
+}
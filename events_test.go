@@ -0,0 +1,108 @@
+package simconnect
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+func TestOnSystemEvent(t *testing.T) {
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	var count int32
+	received := make(chan Event, 100)
+	eventID, err := instance.OnSystemEvent("6Hz", func(event Event) {
+		atomic.AddInt32(&count, 1)
+		received <- event
+	})
+	require.NoError(t, err)
+
+	// Data requests keep working alongside the events
+	for i := 0; i < 3; i++ {
+		_, err := Get[positionReport](instance, simconnect_data.OBJECT_ID_USER)
+		require.NoError(t, err)
+
+		select {
+		case event := <-received:
+			assert.Equal(t, eventID, event.EventID)
+			assert.Equal(t, "6Hz", event.Name)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "no event received")
+		}
+	}
+
+	require.NoError(t, instance.SetSystemEventState(eventID, simconnect_data.SIMCONNECT_STATE_OFF))
+	time.Sleep(200 * time.Millisecond)
+	off := atomic.LoadInt32(&count)
+	time.Sleep(500 * time.Millisecond)
+	assert.Equal(t, off, atomic.LoadInt32(&count))
+
+	require.NoError(t, instance.SetSystemEventState(eventID, simconnect_data.SIMCONNECT_STATE_ON))
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&count) > off }, 5*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, instance.UnsubscribeFromSystemEvent(eventID))
+	time.Sleep(200 * time.Millisecond)
+	unsubscribed := atomic.LoadInt32(&count)
+	time.Sleep(500 * time.Millisecond)
+	assert.Equal(t, unsubscribed, atomic.LoadInt32(&count))
+}
+
+func TestEvents(t *testing.T) {
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := instance.Events(ctx)
+
+	require.NoError(t, instance.SubscribeToSystemEvent(20, "ObjectAdded"))
+	objectID, err := instance.LoadParkedATCAircraft("Boeing 747-8i Asobo", "G-EVNT", "EGCC", 1200)
+	require.NoError(t, err)
+	defer instance.RemoveAIObject(*objectID, 1201)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, simconnect_data.RECV_ID_EVENT_OBJECT_ADDREMOVE, event.ID)
+		assert.Equal(t, "ObjectAdded", event.Name)
+		assert.Equal(t, *objectID, event.Data)
+
+		added, err := simconnect_data.DecodeRecvEventObjectAddRemove(event.Message)
+		require.NoError(t, err)
+		assert.Equal(t, simconnect_data.SIMOBJECT_TYPE_AIRCRAFT, added.ObjType)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no event received")
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestUnsubscribeFromSystemEvent(t *testing.T) {
+	fake := NewFakeTransport()
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+
+	require.NoError(t, instance.SubscribeToSystemEvent(30, "Pause"))
+	require.NoError(t, instance.SetSystemEventState(30, simconnect_data.SIMCONNECT_STATE_OFF))
+	assert.Equal(t, map[uint32]uint32{30: simconnect_data.SIMCONNECT_STATE_OFF}, instance.systemEventStates)
+
+	require.NoError(t, instance.UnsubscribeFromSystemEvent(30))
+	assert.Equal(t, []interface{}{uint32(30)}, fake.CallsTo("UnsubscribeFromSystemEvent")[0].Args)
+	assert.Empty(t, instance.systemEvents)
+	assert.Empty(t, instance.systemEventStates)
+
+	// Nothing is sent once the connection is closed
+	require.NoError(t, instance.Close())
+	assert.Error(t, instance.UnsubscribeFromSystemEvent(30))
+	assert.Len(t, fake.CallsTo("UnsubscribeFromSystemEvent"), 1)
+}
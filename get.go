@@ -0,0 +1,231 @@
+package simconnect
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// ObjectData is the data received for a struct of type T along with the object it describes. EntryNumber counts
+// from 1 up to OutOf when a request returns several objects.
+type ObjectData[T any] struct {
+	ObjectID    uint32
+	EntryNumber uint32
+	OutOf       uint32
+	Data        T
+}
+
+// Get requests the data described by the tags of T for objectID, simconnect_data.OBJECT_ID_USER being the user
+// aircraft. T is registered as a data definition on first use and must be a struct of fields tagged like those of
+// Report. It may embed simconnect_data.RecvSimobjectDataByType first to receive the header of the data too.
+func Get[T any](instance *SimconnectInstance, objectID uint32) (*T, error) {
+	return GetContext[T](context.Background(), instance, objectID)
+}
+
+// GetContext is Get which gives up once ctx is done
+func GetContext[T any](ctx context.Context, instance *SimconnectInstance, objectID uint32) (*T, error) {
+	object, err := GetObjectContext[T](ctx, instance, objectID)
+	if err != nil {
+		return nil, err
+	}
+	return &object.Data, nil
+}
+
+// GetByType requests the data described by the tags of T for the user's object of simObjectType, such as
+// simconnect_data.SIMOBJECT_TYPE_USER. See Get for the requirements on T.
+func GetByType[T any](instance *SimconnectInstance, simObjectType uint32) (*T, error) {
+	return GetByTypeContext[T](context.Background(), instance, simObjectType)
+}
+
+// GetByTypeContext is GetByType which gives up once ctx is done
+func GetByTypeContext[T any](ctx context.Context, instance *SimconnectInstance, simObjectType uint32) (*T, error) {
+	object, err := GetObjectByTypeContext[T](ctx, instance, simObjectType)
+	if err != nil {
+		return nil, err
+	}
+	return &object.Data, nil
+}
+
+// GetObject is Get which also returns the ID of the object the data describes.
+func GetObject[T any](instance *SimconnectInstance, objectID uint32) (*ObjectData[T], error) {
+	return GetObjectContext[T](context.Background(), instance, objectID)
+}
+
+// GetObjectContext is GetObject which gives up once ctx is done
+func GetObjectContext[T any](ctx context.Context, instance *SimconnectInstance, objectID uint32) (*ObjectData[T], error) {
+	return get[T](ctx, instance, func(requestID, definitionID uint32) error {
+		return instance.requestDataOnSimObject(requestID, definitionID, objectID, simconnect_data.SIMCONNECT_PERIOD_ONCE)
+	})
+}
+
+// GetObjectByType is GetByType which also returns the ID of the object the data describes.
+func GetObjectByType[T any](instance *SimconnectInstance, simObjectType uint32) (*ObjectData[T], error) {
+	return GetObjectByTypeContext[T](context.Background(), instance, simObjectType)
+}
+
+// GetObjectByTypeContext is GetObjectByType which gives up once ctx is done
+func GetObjectByTypeContext[T any](ctx context.Context, instance *SimconnectInstance, simObjectType uint32) (*ObjectData[T], error) {
+	return get[T](ctx, instance, func(requestID, definitionID uint32) error {
+		return instance.requestDataOnSimObjectType(requestID, definitionID, 0, simObjectType)
+	})
+}
+
+// GetObjectsInRadius requests the data described by the tags of T for every object of simObjectType, such as
+// simconnect_data.SIMOBJECT_TYPE_AIRCRAFT, within radiusMeters of the user's aircraft, keyed by object ID. The user's
+// aircraft is included when it is of simObjectType, and the simulator caps the radius at 200 km. An error is returned
+// when more objects are sent than can be buffered while they are decoded, rather than a partial map. See Get for the
+// requirements on T.
+func GetObjectsInRadius[T any](instance *SimconnectInstance, radiusMeters, simObjectType uint32) (map[uint32]T, error) {
+	return GetObjectsInRadiusContext[T](context.Background(), instance, radiusMeters, simObjectType)
+}
+
+// GetObjectsInRadiusContext is GetObjectsInRadius which gives up once ctx is done
+func GetObjectsInRadiusContext[T any](ctx context.Context, instance *SimconnectInstance, radiusMeters, simObjectType uint32) (map[uint32]T, error) {
+	definitionID, err := definitionFor[T](instance)
+	if err != nil {
+		return nil, err
+	}
+	requestID := instance.newRequestID()
+
+	ctx, cancel := instance.withTimeout(ctx)
+	defer cancel()
+
+	// Every object is sent in its own message, all at once
+	results, err := instance.watchRequestSize(requestID, objectsBufferSize)
+	if err != nil {
+		return nil, err
+	}
+	defer instance.unwatchRequest(requestID)
+
+	if err := instance.requestDataOnSimObjectType(requestID, definitionID, radiusMeters, simObjectType); err != nil {
+		return nil, fmt.Errorf("error sending request %d: %w", requestID, err)
+	}
+
+	objects := map[uint32]T{}
+	entries := map[uint32]bool{}
+	for {
+		select {
+		case result := <-results:
+			if result.err != nil {
+				return nil, result.err
+			}
+
+			object := &ObjectData[T]{}
+			header, err := decodeHeader(result.data)
+			if err != nil {
+				return nil, err
+			}
+			// Nothing was found
+			if header.OutOf == 0 {
+				return objects, nil
+			}
+			if err := object.decode(instance, result.data); err != nil {
+				return nil, err
+			}
+
+			// The buffer was full when the dispatcher had a message to deliver, and the full buffer means this check
+			// runs again after the message was dropped
+			if instance.requestOverflowed(requestID) {
+				return nil, fmt.Errorf("error receiving request %d: %d objects found, more than the %d which can be buffered", requestID, object.OutOf, objectsBufferSize)
+			}
+
+			objects[object.ObjectID] = object.Data
+			entries[object.EntryNumber] = true
+			if uint32(len(entries)) >= object.OutOf {
+				return objects, nil
+			}
+		case <-ctx.Done():
+			return nil, fmt.Errorf("error waiting for response to request %d after %d objects: %w", requestID, len(objects), ctx.Err())
+		}
+	}
+}
+
+func get[T any](ctx context.Context, instance *SimconnectInstance, send func(requestID, definitionID uint32) error) (*ObjectData[T], error) {
+	data, err := instance.requestData(ctx, reflect.TypeOf(new(T)).Elem(), send)
+	if err != nil {
+		return nil, err
+	}
+
+	object := &ObjectData[T]{}
+	if err := object.decode(instance, data); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// requestData sends the request made by send for the data definition of t, registering it on first use, and returns
+// the data received in response.
+func (instance *SimconnectInstance) requestData(ctx context.Context, t reflect.Type, send func(requestID, definitionID uint32) error) ([]byte, error) {
+	definitionID, err := instance.definitionFor(t)
+	if err != nil {
+		return nil, err
+	}
+	requestID := instance.newRequestID()
+
+	return instance.awaitRequest(ctx, requestID, func() error {
+		return send(requestID, definitionID)
+	})
+}
+
+// decode decodes data received for T into object. Fields missing from tagged data are left as they were.
+func (object *ObjectData[T]) decode(instance *SimconnectInstance, data []byte) error {
+	header, err := instance.decodeObjectData(data, reflect.ValueOf(&object.Data).Elem())
+	if err != nil {
+		return err
+	}
+	object.ObjectID = header.ObjectID
+	object.EntryNumber = header.EntryNumber
+	object.OutOf = header.OutOf
+	return nil
+}
+
+// decodeObjectData decodes the data received for a sim object into value and returns its header.
+func (instance *SimconnectInstance) decodeObjectData(data []byte, value reflect.Value) (simconnect_data.RecvSimobjectDataByType, error) {
+	data, err := checkRecvSize(data)
+	if err != nil {
+		return simconnect_data.RecvSimobjectDataByType{}, err
+	}
+	switch recvID(data) {
+	case simconnect_data.RECV_ID_SIMOBJECT_DATA, simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE:
+	default:
+		return simconnect_data.RecvSimobjectDataByType{}, fmt.Errorf("unexpected message %d received for %s", recvID(data), value.Type())
+	}
+
+	if err := instance.decodeData(data, value); err != nil {
+		return simconnect_data.RecvSimobjectDataByType{}, err
+	}
+	return decodeHeader(data)
+}
+
+// definitionFor returns the ID of the data definition of T, registering it on first use.
+func definitionFor[T any](instance *SimconnectInstance) (uint32, error) {
+	return instance.definitionFor(reflect.TypeOf(new(T)).Elem())
+}
+
+// definitionFor returns the ID of the data definition of the struct type t, registering it on first use.
+func (instance *SimconnectInstance) definitionFor(t reflect.Type) (uint32, error) {
+	if err := checkDefinitionType(t); err != nil {
+		return 0, err
+	}
+
+	value := reflect.New(t).Interface()
+	err := instance.registerDataDefinition(value)
+	if err != nil {
+		return 0, err
+	}
+	definitionID, _ := instance.getDefinitionID(value)
+	return definitionID, nil
+}
+
+// checkDefinitionType returns an error unless t can be registered as a data definition.
+func checkDefinitionType(t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("%s is not a struct", t)
+	}
+	if t.NumField() == firstDefinitionField(t) {
+		return fmt.Errorf("%s has no fields to request", t)
+	}
+	return nil
+}
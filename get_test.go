@@ -0,0 +1,225 @@
+package simconnect
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+type positionReport struct {
+	Title     [256]byte `name:"Title"`
+	Altitude  float64   `name:"Plane Altitude" unit:"feet"`
+	Latitude  float64   `name:"Plane Latitude" unit:"degrees"`
+	Longitude float64   `name:"Plane Longitude" unit:"degrees"`
+	OnGround  bool      `name:"Sim On Ground" unit:"bool"`
+}
+
+func TestGet(t *testing.T) {
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	report, err := Get[positionReport](instance, simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	assert.NotEmpty(t, bytes.TrimRight(report.Title[:], "\x00"))
+
+	// Both requests share the definition registered by the first
+	byType, err := GetObjectByType[positionReport](instance, simconnect_data.SIMOBJECT_TYPE_USER)
+	require.NoError(t, err)
+	assert.Len(t, instance.DataDefinitions(), 1)
+	assert.Equal(t, uint32(1), byType.ObjectID)
+	assert.Equal(t, uint32(1), byType.OutOf)
+	assert.Equal(t, report.Altitude, byType.Data.Altitude)
+	assert.Equal(t, report.Latitude, byType.Data.Latitude)
+
+	builtIn, err := Get[APReport](instance, simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	assert.Equal(t, report.Title, builtIn.Title)
+}
+
+func TestGetInvalidType(t *testing.T) {
+	instance, err := NewSimConnectWithTransport(t.Name(), NewFakeTransport())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	_, err = Get[float64](instance, simconnect_data.OBJECT_ID_USER)
+	assert.EqualError(t, err, "float64 is not a struct")
+
+	_, err = Get[struct {
+		simconnect_data.RecvSimobjectDataByType
+	}](instance, simconnect_data.OBJECT_ID_USER)
+	assert.EqualError(t, err, "struct { simconnect_data.RecvSimobjectDataByType } has no fields to request")
+}
+
+func TestGetPacked(t *testing.T) {
+	type mixedReport struct {
+		Bank     float32 `name:"Plane Bank Degrees" unit:"degrees"`
+		OnGround bool    `name:"Sim On Ground" unit:"bool"`
+		Altitude float64 `name:"Plane Altitude" unit:"feet"`
+		Gear     int32   `name:"Gear Handle Position" unit:"bool"`
+		Heading  float64 `name:"Plane Heading Degrees True" unit:"degrees"`
+	}
+
+	fake := NewFakeTransport()
+	fake.On("RequestDataOnSimObject", func(fake *FakeTransport, call FakeCall) error {
+		// Laid out back to back, so Altitude and Heading are not 8 byte aligned
+		data := struct {
+			simconnect_data.RecvSimobjectDataByType
+			Bank     float32
+			OnGround int32
+			Altitude float64
+			Gear     int32
+			Heading  float64
+		}{Bank: -12.5, OnGround: 1, Altitude: 3500, Gear: 1, Heading: 270}
+		data.RequestID = call.Args[0].(uint32)
+		data.DefineID = call.Args[1].(uint32)
+		data.ObjectID = call.Args[2].(uint32)
+		data.EntryNumber = 1
+		data.OutOf = 1
+		return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA, &data)
+	})
+
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	object, err := GetObject[mixedReport](instance, 42)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(42), object.ObjectID)
+	assert.Equal(t, mixedReport{Bank: -12.5, OnGround: true, Altitude: 3500, Gear: 1, Heading: 270}, object.Data)
+}
+
+func TestGetObjectsInRadius(t *testing.T) {
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	user, err := GetObject[positionReport](instance, simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+
+	near, err := instance.LoadNonATCAircraft("Boeing 747-8i Asobo", "G-NEAR", simconnect_data.SimconnectDataInitPosition{
+		Latitude:  user.Data.Latitude + 0.01,
+		Longitude: user.Data.Longitude,
+		Altitude:  3000,
+		Airspeed:  200,
+	}, 1000)
+	require.NoError(t, err)
+	defer instance.RemoveAIObject(*near, 1001)
+
+	far, err := instance.LoadNonATCAircraft("Boeing 747-8i Asobo", "G-FAR", simconnect_data.SimconnectDataInitPosition{
+		Latitude:  user.Data.Latitude + 1,
+		Longitude: user.Data.Longitude,
+		Altitude:  3000,
+		Airspeed:  200,
+	}, 1002)
+	require.NoError(t, err)
+	defer instance.RemoveAIObject(*far, 1003)
+	waitForSim(5 * time.Second)
+
+	objects, err := GetObjectsInRadius[positionReport](instance, 10000, simconnect_data.SIMOBJECT_TYPE_AIRCRAFT)
+	require.NoError(t, err)
+	assert.Contains(t, objects, user.ObjectID)
+	assert.Contains(t, objects, *near)
+	assert.NotContains(t, objects, *far)
+	assert.InDelta(t, user.Data.Latitude+0.01, objects[*near].Latitude, 0.01)
+
+	boats, err := GetObjectsInRadius[positionReport](instance, 10000, simconnect_data.SIMOBJECT_TYPE_BOAT)
+	require.NoError(t, err)
+	assert.Empty(t, boats)
+}
+
+func TestGetObjectsInRadiusEntries(t *testing.T) {
+	const count = 200
+
+	fake := NewFakeTransport()
+	fake.On("RequestDataOnSimObjectType", func(fake *FakeTransport, call FakeCall) error {
+		for i := uint32(1); i <= count; i++ {
+			data := struct {
+				simconnect_data.RecvSimobjectDataByType
+				Altitude float64
+			}{Altitude: float64(i * 100)}
+			data.RequestID = call.Args[0].(uint32)
+			data.DefineID = call.Args[1].(uint32)
+			data.ObjectID = i + 10
+			data.EntryNumber = i
+			data.OutOf = count
+			data.DefineCount = 1
+			if err := fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, &data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	objects, err := GetObjectsInRadius[altitudeReport](instance, 50000, simconnect_data.SIMOBJECT_TYPE_HELICOPTER)
+	require.NoError(t, err)
+	assert.Len(t, objects, count)
+	assert.Equal(t, altitudeReport{Altitude: 1500}, objects[25])
+
+	calls := fake.CallsTo("RequestDataOnSimObjectType")
+	require.Len(t, calls, 1)
+	assert.Equal(t, uint32(50000), calls[0].Args[2])
+	assert.Equal(t, simconnect_data.SIMOBJECT_TYPE_HELICOPTER, calls[0].Args[3])
+}
+
+func TestGetObjectsInRadiusOverflow(t *testing.T) {
+	const count = objectsBufferSize + 10
+
+	fake := NewFakeTransport()
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	_, err = definitionFor[altitudeReport](instance)
+	require.NoError(t, err)
+
+	fake.On("RequestDataOnSimObjectType", func(fake *FakeTransport, call FakeCall) error {
+		// Decoding is held up until every message was dispatched, so the buffer overflows
+		instance.definitionMapMutex.Lock()
+		go func() {
+			defer instance.definitionMapMutex.Unlock()
+			for {
+				fake.mutex.Lock()
+				queued := len(fake.queue)
+				fake.mutex.Unlock()
+				if queued == 0 {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}()
+
+		for i := uint32(1); i <= count; i++ {
+			data := struct {
+				simconnect_data.RecvSimobjectDataByType
+				Altitude float64
+			}{Altitude: float64(i * 100)}
+			data.RequestID = call.Args[0].(uint32)
+			data.DefineID = call.Args[1].(uint32)
+			data.ObjectID = i + 10
+			data.EntryNumber = i
+			data.OutOf = count
+			data.DefineCount = 1
+			if err := fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, &data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	objects, err := GetObjectsInRadius[altitudeReport](instance, 50000, simconnect_data.SIMOBJECT_TYPE_HELICOPTER)
+	assert.Nil(t, objects)
+	assert.EqualError(t, err, fmt.Sprintf("error receiving request %d: %d objects found, more than the %d which can be buffered",
+		fake.CallsTo("RequestDataOnSimObjectType")[0].Args[0], count, objectsBufferSize))
+}
@@ -0,0 +1,263 @@
+// Package protocol implements the framing used by SimConnect when it is exposed over the network through
+// SimConnect.xml (server side) and SimConnect.cfg (client side).
+//
+// Every packet sent by a client starts with a 16 byte header (size, protocol version, function ID and send ID)
+// followed by the function parameters in the same order as the SimConnect_* C functions. Packets sent by the server
+// have the same layout as the SIMCONNECT_RECV structs: a 12 byte header (size, version, receive ID) followed by the
+// struct body. All values are little endian and strings are fixed-size, null padded byte arrays.
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Version is the protocol version spoken by the client. Version 4 is the FSX SP2 / Acceleration protocol which is
+// also accepted by Prepar3D and Microsoft Flight Simulator 2020.
+const Version uint32 = 4
+
+// RequestHeaderSize is the size of the header which prefixes every client packet.
+const RequestHeaderSize = 16
+
+// ResponseHeaderSize is the size of the header which prefixes every server packet.
+const ResponseHeaderSize = 12
+
+// MaxPacketSize bounds the size of a single packet to protect against corrupt streams.
+const MaxPacketSize = 1 << 20
+
+// requestIDMask is OR'ed into the function ID of every client packet.
+const requestIDMask uint32 = 0xF0000000
+
+// Function IDs, one per SimConnect_* call, as sent by SimConnect.dll. IDs 0x02 and 0x03 are used by calls this
+// package does not implement.
+const (
+	ID_OPEN                             uint32 = 0x01
+	ID_MAP_CLIENT_EVENT_TO_SIM_EVENT    uint32 = 0x04
+	ID_TRANSMIT_CLIENT_EVENT            uint32 = 0x05
+	ID_SET_SYSTEM_EVENT_STATE           uint32 = 0x06
+	ID_ADD_CLIENT_EVENT_TO_NOTIFICATION uint32 = 0x07
+	ID_REMOVE_CLIENT_EVENT              uint32 = 0x08
+	ID_SET_NOTIFICATION_GROUP_PRIORITY  uint32 = 0x09
+	ID_CLEAR_NOTIFICATION_GROUP         uint32 = 0x0A
+	ID_REQUEST_NOTIFICATION_GROUP       uint32 = 0x0B
+	ID_ADD_TO_DATA_DEFINITION           uint32 = 0x0C
+	ID_CLEAR_DATA_DEFINITION            uint32 = 0x0D
+	ID_REQUEST_DATA_ON_SIM_OBJECT       uint32 = 0x0E
+	ID_REQUEST_DATA_ON_SIM_OBJECT_TYPE  uint32 = 0x0F
+	ID_SET_DATA_ON_SIM_OBJECT           uint32 = 0x10
+	ID_SUBSCRIBE_TO_SYSTEM_EVENT        uint32 = 0x17
+	ID_UNSUBSCRIBE_FROM_SYSTEM_EVENT    uint32 = 0x18
+	ID_AI_CREATE_PARKED_ATC_AIRCRAFT    uint32 = 0x27
+	ID_AI_CREATE_ENROUTE_ATC_AIRCRAFT   uint32 = 0x28
+	ID_AI_CREATE_NON_ATC_AIRCRAFT       uint32 = 0x29
+	ID_AI_CREATE_SIMULATED_OBJECT       uint32 = 0x2A
+	ID_AI_RELEASE_CONTROL               uint32 = 0x2B
+	ID_AI_REMOVE_OBJECT                 uint32 = 0x2C
+	ID_AI_SET_AIRCRAFT_FLIGHT_PLAN      uint32 = 0x2D
+	ID_REQUEST_SYSTEM_STATE             uint32 = 0x35
+	ID_SET_SYSTEM_STATE                 uint32 = 0x36
+	ID_FLIGHT_LOAD                      uint32 = 0x3D
+	ID_FLIGHT_SAVE                      uint32 = 0x3E
+	ID_FLIGHT_PLAN_LOAD                 uint32 = 0x3F
+	ID_TEXT                             uint32 = 0x40
+)
+
+// String sizes used by the fixed-width string parameters.
+const (
+	StringSize256   = 256
+	StringSize260   = 260
+	TailNumberSize  = 12
+	AirportICAOSize = 5
+)
+
+// Builder accumulates the parameters of a packet.
+type Builder struct {
+	buf bytes.Buffer
+}
+
+// Uint32 appends a DWORD parameter.
+func (b *Builder) Uint32(v uint32) *Builder {
+	var p [4]byte
+	binary.LittleEndian.PutUint32(p[:], v)
+	b.buf.Write(p[:])
+	return b
+}
+
+// Float32 appends a float parameter.
+func (b *Builder) Float32(v float32) *Builder {
+	return b.Uint32(math.Float32bits(v))
+}
+
+// Float64 appends a double parameter.
+func (b *Builder) Float64(v float64) *Builder {
+	var p [8]byte
+	binary.LittleEndian.PutUint64(p[:], math.Float64bits(v))
+	b.buf.Write(p[:])
+	return b
+}
+
+// String appends s as a null padded string of exactly size bytes, truncating it if required.
+func (b *Builder) String(s string, size int) *Builder {
+	p := make([]byte, size)
+	copy(p[:size-1], s)
+	b.buf.Write(p)
+	return b
+}
+
+// StringV appends s as a null terminated variable length string.
+func (b *Builder) StringV(s string) *Builder {
+	b.buf.WriteString(s)
+	b.buf.WriteByte(0)
+	return b
+}
+
+// Raw appends p unchanged.
+func (b *Builder) Raw(p []byte) *Builder {
+	b.buf.Write(p)
+	return b
+}
+
+// Payload returns the parameters appended so far.
+func (b *Builder) Payload() []byte {
+	return b.buf.Bytes()
+}
+
+// EncodeRequest prefixes payload with a client packet header.
+func EncodeRequest(id, sendID uint32, payload []byte) []byte {
+	p := make([]byte, RequestHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(p[0:], uint32(len(p)))
+	binary.LittleEndian.PutUint32(p[4:], Version)
+	binary.LittleEndian.PutUint32(p[8:], requestIDMask|id)
+	binary.LittleEndian.PutUint32(p[12:], sendID)
+	copy(p[RequestHeaderSize:], payload)
+	return p
+}
+
+// EncodeResponse prefixes payload with a server packet header.
+func EncodeResponse(recvID uint32, payload []byte) []byte {
+	p := make([]byte, ResponseHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(p[0:], uint32(len(p)))
+	binary.LittleEndian.PutUint32(p[4:], Version)
+	binary.LittleEndian.PutUint32(p[8:], recvID)
+	copy(p[ResponseHeaderSize:], payload)
+	return p
+}
+
+// Request is a decoded client packet.
+type Request struct {
+	Version uint32
+	ID      uint32
+	SendID  uint32
+	Payload []byte
+}
+
+// DecodeRequest splits a client packet into its header and payload.
+func DecodeRequest(p []byte) (Request, error) {
+	if len(p) < RequestHeaderSize {
+		return Request{}, fmt.Errorf("request too short: %d bytes", len(p))
+	}
+
+	return Request{
+		Version: binary.LittleEndian.Uint32(p[4:]),
+		ID:      binary.LittleEndian.Uint32(p[8:]) &^ requestIDMask,
+		SendID:  binary.LittleEndian.Uint32(p[12:]),
+		Payload: p[RequestHeaderSize:],
+	}, nil
+}
+
+// ReadPacket reads a single size-prefixed packet from r, returning it including its header.
+func ReadPacket(r io.Reader) ([]byte, error) {
+	var sizeBuf [4]byte
+	if _, err := io.ReadFull(r, sizeBuf[:]); err != nil {
+		return nil, err
+	}
+
+	size := binary.LittleEndian.Uint32(sizeBuf[:])
+	if size < 4 || size > MaxPacketSize {
+		return nil, fmt.Errorf("invalid packet size %d", size)
+	}
+
+	p := make([]byte, size)
+	copy(p, sizeBuf[:])
+	if _, err := io.ReadFull(r, p[4:]); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// ErrShortPayload is returned by Reader once it has been asked for more bytes than the payload holds.
+var ErrShortPayload = errors.New("payload too short")
+
+// Reader decodes parameters from a payload. Once a read fails all subsequent reads return zero values and Err
+// reports ErrShortPayload.
+type Reader struct {
+	buf []byte
+	err error
+}
+
+// NewReader returns a Reader over payload.
+func NewReader(payload []byte) *Reader {
+	return &Reader{buf: payload}
+}
+
+// Bytes returns the next n bytes.
+func (r *Reader) Bytes(n int) []byte {
+	if r.err != nil || n > len(r.buf) {
+		r.err = ErrShortPayload
+		return make([]byte, n)
+	}
+	p := r.buf[:n]
+	r.buf = r.buf[n:]
+	return p
+}
+
+// Uint32 reads a DWORD.
+func (r *Reader) Uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.Bytes(4))
+}
+
+// Float32 reads a float.
+func (r *Reader) Float32() float32 {
+	return math.Float32frombits(r.Uint32())
+}
+
+// Float64 reads a double.
+func (r *Reader) Float64() float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(r.Bytes(8)))
+}
+
+// String reads a null padded string of size bytes.
+func (r *Reader) String(size int) string {
+	p := r.Bytes(size)
+	if i := bytes.IndexByte(p, 0); i >= 0 {
+		p = p[:i]
+	}
+	return string(p)
+}
+
+// StringV reads a null terminated variable length string.
+func (r *Reader) StringV() string {
+	i := bytes.IndexByte(r.buf, 0)
+	if r.err != nil || i == -1 {
+		r.err = ErrShortPayload
+		return ""
+	}
+	return string(r.Bytes(i + 1)[:i])
+}
+
+// Remaining returns every byte not yet read.
+func (r *Reader) Remaining() []byte {
+	p := r.buf
+	r.buf = nil
+	return p
+}
+
+// Err reports whether any read ran past the end of the payload.
+func (r *Reader) Err() error {
+	return r.err
+}
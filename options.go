@@ -0,0 +1,85 @@
+package simconnect
+
+import (
+	"time"
+)
+
+// Option configures how an instance connects to the simulator.
+type Option func(*options)
+
+type options struct {
+	dllPath     string
+	configIndex uint32
+	dllCacheDir string
+
+	reconnectBackoff    time.Duration
+	maxReconnectBackoff time.Duration
+
+	dropRejectedFields bool
+	simVarWarning      func(SimVarWarning)
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		simVarWarning: func(SimVarWarning) {},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithDLLPath loads the SimConnect.dll at path rather than the one shipped with the library. Instances created with
+// different paths can be used side by side, for example to talk to FSX and MSFS from the same process.
+func WithDLLPath(path string) Option {
+	return func(o *options) {
+		o.dllPath = path
+	}
+}
+
+// WithConfigIndex selects the entry of SimConnect.cfg used to reach the simulator, letting SimConnect.dll connect to
+// a simulator running on another machine.
+func WithConfigIndex(index uint32) Option {
+	return func(o *options) {
+		o.configIndex = index
+	}
+}
+
+// WithEmbeddedDLLCacheDir extracts the embedded SimConnect.dll into dir, reusing it across connections and restarts,
+// rather than into a temporary directory which is removed again on Close.
+func WithEmbeddedDLLCacheDir(dir string) Option {
+	return func(o *options) {
+		o.dllCacheDir = dir
+	}
+}
+
+// WithReconnect makes the instance reconnect when the simulator quits or the connection to it breaks, rather than
+// failing every call from then on. Attempts start after backoff and double up to maxBackoff. Once reconnected, every
+// data definition, system event subscription and client event mapping made so far is replayed. Calls made while
+// disconnected fail with ErrDisconnected, see also ConnectionStateChanges.
+func WithReconnect(backoff, maxBackoff time.Duration) Option {
+	return func(o *options) {
+		o.reconnectBackoff = backoff
+		o.maxReconnectBackoff = maxBackoff
+		if maxBackoff < backoff {
+			o.maxReconnectBackoff = backoff
+		}
+	}
+}
+
+// WithDropRejectedFields leaves struct fields whose simvar the simulator rejected, for example because of a misspelt
+// name tag, at their zero value instead of failing every request for the struct with a *FieldError.
+func WithDropRejectedFields() Option {
+	return func(o *options) {
+		o.dropRejectedFields = true
+	}
+}
+
+// WithSimVarWarnings calls handler for every field of a data definition naming a simvar missing from the catalog, see
+// LookupSimVar, and for every field written with SetDataOnSimObject which the catalog says is not settable. Without
+// it the warnings are ignored.
+func WithSimVarWarnings(handler func(warning SimVarWarning)) Option {
+	return func(o *options) {
+		o.simVarWarning = handler
+	}
+}
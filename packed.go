@@ -0,0 +1,325 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// The simulator exchanges data definitions as the values of their fields packed back to back, little endian, with no
+// alignment. bool is sent as a 32-bit integer and string as a null terminated variable length string.
+
+// decodePacked decodes the packed value at the start of data into v, returning the number of bytes used.
+func decodePacked(data []byte, v reflect.Value) (int, error) {
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int32, reflect.Uint32, reflect.Float32:
+		if len(data) < 4 {
+			return 0, fmt.Errorf("%d bytes left for %s", len(data), v.Type())
+		}
+		bits := binary.LittleEndian.Uint32(data)
+		switch v.Kind() {
+		case reflect.Bool:
+			v.SetBool(bits != 0)
+		case reflect.Int32:
+			v.SetInt(int64(int32(bits)))
+		case reflect.Uint32:
+			v.SetUint(uint64(bits))
+		case reflect.Float32:
+			v.SetFloat(float64(math.Float32frombits(bits)))
+		}
+		return 4, nil
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		if len(data) < 8 {
+			return 0, fmt.Errorf("%d bytes left for %s", len(data), v.Type())
+		}
+		bits := binary.LittleEndian.Uint64(data)
+		switch v.Kind() {
+		case reflect.Int64:
+			v.SetInt(int64(bits))
+		case reflect.Uint64:
+			v.SetUint(bits)
+		case reflect.Float64:
+			v.SetFloat(math.Float64frombits(bits))
+		}
+		return 8, nil
+	case reflect.Array:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			break
+		}
+		if len(data) < v.Len() {
+			return 0, fmt.Errorf("%d bytes left for %s", len(data), v.Type())
+		}
+		reflect.Copy(v, reflect.ValueOf(data[:v.Len()]))
+		return v.Len(), nil
+	case reflect.String:
+		end := bytes.IndexByte(data, 0)
+		if end == -1 {
+			return 0, fmt.Errorf("variable length string is not terminated")
+		}
+		v.SetString(string(data[:end]))
+		return end + 1, nil
+	case reflect.Struct:
+		offset := 0
+		for j := 0; j < v.NumField(); j++ {
+			n, err := decodePacked(data[offset:], v.Field(j))
+			if err != nil {
+				return 0, fmt.Errorf("%s: %v", v.Type().Field(j).Name, err)
+			}
+			offset += n
+		}
+		return offset, nil
+	}
+
+	return 0, fmt.Errorf("%s can not be decoded", v.Type())
+}
+
+// encodePacked appends v to buf in the packed layout read by decodePacked.
+func encodePacked(buf *bytes.Buffer, v reflect.Value) error {
+	var scratch [8]byte
+
+	switch v.Kind() {
+	case reflect.Bool:
+		var bits uint32
+		if v.Bool() {
+			bits = 1
+		}
+		binary.LittleEndian.PutUint32(scratch[:], bits)
+		buf.Write(scratch[:4])
+	case reflect.Int32:
+		binary.LittleEndian.PutUint32(scratch[:], uint32(int32(v.Int())))
+		buf.Write(scratch[:4])
+	case reflect.Uint32:
+		binary.LittleEndian.PutUint32(scratch[:], uint32(v.Uint()))
+		buf.Write(scratch[:4])
+	case reflect.Float32:
+		binary.LittleEndian.PutUint32(scratch[:], math.Float32bits(float32(v.Float())))
+		buf.Write(scratch[:4])
+	case reflect.Int64:
+		binary.LittleEndian.PutUint64(scratch[:], uint64(v.Int()))
+		buf.Write(scratch[:])
+	case reflect.Uint64:
+		binary.LittleEndian.PutUint64(scratch[:], v.Uint())
+		buf.Write(scratch[:])
+	case reflect.Float64:
+		binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(v.Float()))
+		buf.Write(scratch[:])
+	case reflect.Array:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("%s can not be encoded", v.Type())
+		}
+		for i := 0; i < v.Len(); i++ {
+			buf.WriteByte(byte(v.Index(i).Uint()))
+		}
+	case reflect.String:
+		buf.WriteString(v.String())
+		buf.WriteByte(0)
+	case reflect.Struct:
+		for j := 0; j < v.NumField(); j++ {
+			if err := encodePacked(buf, v.Field(j)); err != nil {
+				return fmt.Errorf("%s: %v", v.Type().Field(j).Name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("%s can not be encoded", v.Type())
+	}
+
+	return nil
+}
+
+// stringSizes is the size of the fixed length string data types.
+var stringSizes = map[uint32]int{
+	simconnect_data.DATATYPE_STRING8:   8,
+	simconnect_data.DATATYPE_STRING32:  32,
+	simconnect_data.DATATYPE_STRING64:  64,
+	simconnect_data.DATATYPE_STRING128: 128,
+	simconnect_data.DATATYPE_STRING256: 256,
+	simconnect_data.DATATYPE_STRING260: 260,
+}
+
+func isNumericDataType(dataType uint32) bool {
+	switch dataType {
+	case simconnect_data.DATATYPE_INT32, simconnect_data.DATATYPE_INT64,
+		simconnect_data.DATATYPE_FLOAT32, simconnect_data.DATATYPE_FLOAT64:
+		return true
+	}
+	return false
+}
+
+func isStringDataType(dataType uint32) bool {
+	_, fixed := stringSizes[dataType]
+	return fixed || dataType == simconnect_data.DATATYPE_STRINGV
+}
+
+// canHoldDataType reports whether a field of type t can be registered as dataType, converting between the two.
+func canHoldDataType(t reflect.Type, dataType uint32) bool {
+	if natural, err := fieldDataType(t); err == nil && natural == dataType {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return isNumericDataType(dataType)
+	case reflect.String:
+		return isStringDataType(dataType)
+	case reflect.Array:
+		return t.Elem().Kind() == reflect.Uint8 && isStringDataType(dataType)
+	}
+	return false
+}
+
+// decodeField decodes a value of dataType at the start of data into v, converting it to the type of v, and returns
+// the number of bytes used.
+func decodeField(data []byte, v reflect.Value, dataType uint32) (int, error) {
+	if natural, err := fieldDataType(v.Type()); err == nil && natural == dataType {
+		return decodePacked(data, v)
+	}
+
+	switch {
+	case isNumericDataType(dataType):
+		var f float64
+		var i int64
+		n := 4
+		switch dataType {
+		case simconnect_data.DATATYPE_INT64, simconnect_data.DATATYPE_FLOAT64:
+			n = 8
+		}
+		if len(data) < n {
+			return 0, fmt.Errorf("%d bytes left for %s", len(data), v.Type())
+		}
+		switch dataType {
+		case simconnect_data.DATATYPE_INT32:
+			i = int64(int32(binary.LittleEndian.Uint32(data)))
+			f = float64(i)
+		case simconnect_data.DATATYPE_INT64:
+			i = int64(binary.LittleEndian.Uint64(data))
+			f = float64(i)
+		case simconnect_data.DATATYPE_FLOAT32:
+			f = float64(math.Float32frombits(binary.LittleEndian.Uint32(data)))
+			i = int64(f)
+		case simconnect_data.DATATYPE_FLOAT64:
+			f = math.Float64frombits(binary.LittleEndian.Uint64(data))
+			i = int64(f)
+		}
+
+		switch v.Kind() {
+		case reflect.Bool:
+			v.SetBool(f != 0)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v.SetInt(i)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v.SetUint(uint64(i))
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(f)
+		default:
+			return 0, fmt.Errorf("%s can not hold a number", v.Type())
+		}
+		return n, nil
+	case isStringDataType(dataType):
+		n, ok := stringSizes[dataType]
+		if !ok {
+			n = bytes.IndexByte(data, 0) + 1
+			if n == 0 {
+				return 0, fmt.Errorf("variable length string is not terminated")
+			}
+		}
+		if len(data) < n {
+			return 0, fmt.Errorf("%d bytes left for %s", len(data), v.Type())
+		}
+		s := data[:n]
+		if end := bytes.IndexByte(s, 0); end >= 0 {
+			s = s[:end]
+		}
+
+		switch {
+		case v.Kind() == reflect.String:
+			v.SetString(string(s))
+		case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+			reflect.Copy(v, reflect.ValueOf(s))
+		default:
+			return 0, fmt.Errorf("%s can not hold a string", v.Type())
+		}
+		return n, nil
+	}
+
+	return 0, fmt.Errorf("%s can not hold data type %d", v.Type(), dataType)
+}
+
+// encodeField appends v to buf as a value of dataType, converting it from the type of v.
+func encodeField(buf *bytes.Buffer, v reflect.Value, dataType uint32) error {
+	if natural, err := fieldDataType(v.Type()); err == nil && natural == dataType {
+		return encodePacked(buf, v)
+	}
+
+	switch {
+	case isNumericDataType(dataType):
+		var f float64
+		var i int64
+		switch v.Kind() {
+		case reflect.Bool:
+			if v.Bool() {
+				f, i = 1, 1
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i = v.Int()
+			f = float64(i)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			i = int64(v.Uint())
+			f = float64(i)
+		case reflect.Float32, reflect.Float64:
+			f = v.Float()
+			i = int64(f)
+		default:
+			return fmt.Errorf("%s is not a number", v.Type())
+		}
+
+		var scratch [8]byte
+		switch dataType {
+		case simconnect_data.DATATYPE_INT32:
+			binary.LittleEndian.PutUint32(scratch[:], uint32(int32(i)))
+			buf.Write(scratch[:4])
+		case simconnect_data.DATATYPE_INT64:
+			binary.LittleEndian.PutUint64(scratch[:], uint64(i))
+			buf.Write(scratch[:])
+		case simconnect_data.DATATYPE_FLOAT32:
+			binary.LittleEndian.PutUint32(scratch[:], math.Float32bits(float32(f)))
+			buf.Write(scratch[:4])
+		case simconnect_data.DATATYPE_FLOAT64:
+			binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(f))
+			buf.Write(scratch[:])
+		}
+		return nil
+	case isStringDataType(dataType):
+		var s []byte
+		switch {
+		case v.Kind() == reflect.String:
+			s = []byte(v.String())
+		case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+			s = make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(s), v)
+			if end := bytes.IndexByte(s, 0); end >= 0 {
+				s = s[:end]
+			}
+		default:
+			return fmt.Errorf("%s is not a string", v.Type())
+		}
+
+		n, ok := stringSizes[dataType]
+		if !ok {
+			buf.Write(s)
+			buf.WriteByte(0)
+			return nil
+		}
+		// Truncated if required to keep the terminating null
+		padded := make([]byte, n)
+		copy(padded[:n-1], s)
+		buf.Write(padded)
+		return nil
+	}
+
+	return fmt.Errorf("%s can not be sent as data type %d", v.Type(), dataType)
+}
@@ -0,0 +1,90 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+func TestPackedSizes(t *testing.T) {
+	for _, test := range []struct {
+		value interface{}
+		size  int
+	}{
+		{simconnect_data.LatLonAlt{}, 24},
+		{simconnect_data.XYZ{}, 24},
+		{simconnect_data.Waypoint{}, 44},
+		{simconnect_data.SimconnectDataInitPosition{}, 56},
+		{simconnect_data.MarkerState{}, 68},
+		{"ATC ID", 7},
+	} {
+		buf := &bytes.Buffer{}
+		require.NoError(t, encodePacked(buf, reflect.ValueOf(test.value)))
+		assert.Equal(t, test.size, buf.Len(), "%T", test.value)
+	}
+}
+
+func TestPackedRoundTrip(t *testing.T) {
+	type complexReport struct {
+		Title    string                                     `name:"Title"`
+		Position simconnect_data.LatLonAlt                  `name:"STRUCT LATLONALT"`
+		Velocity simconnect_data.XYZ                        `name:"STRUCT WORLDVELOCITY"`
+		Waypoint simconnect_data.Waypoint                   `name:"AI WAYPOINT LIST"`
+		Init     simconnect_data.SimconnectDataInitPosition `name:"Initial Position"`
+		Marker   simconnect_data.MarkerState                `name:"Marker State"`
+		ATCID    string                                     `name:"ATC ID"`
+		OnGround bool                                       `name:"Sim On Ground" unit:"bool"`
+	}
+
+	report := complexReport{
+		Title:    "Cessna Skyhawk",
+		Position: simconnect_data.LatLonAlt{Latitude: 53.35, Longitude: -2.27, Altitude: 3500},
+		Velocity: simconnect_data.XYZ{X: 1, Y: -2, Z: 3},
+		Waypoint: simconnect_data.Waypoint{Latitude: 51.47, Flags: simconnect_data.WAYPOINT_SPEED_REQUESTED, KtsSpeed: 120},
+		Init:     simconnect_data.SimconnectDataInitPosition{Heading: 270, OnGround: true, Airspeed: 60},
+		ATCID:    "N172SP",
+		OnGround: true,
+	}
+	copy(report.Marker.MarkerName[:], "Inner")
+	report.Marker.MarkerState = 1
+
+	fields, err := parseDefinitionFields(reflect.TypeOf(report))
+	require.NoError(t, err)
+	data, err := encodeDefinition(reflect.ValueOf(report), fields, nil)
+	require.NoError(t, err)
+
+	// Decoding expects the data to follow its header
+	data = append(make([]byte, recvSimobjectDataSize), data...)
+	binary.LittleEndian.PutUint32(data[0:], uint32(len(data)))
+	binary.LittleEndian.PutUint32(data[8:], simconnect_data.RECV_ID_SIMOBJECT_DATA)
+	var decoded complexReport
+	require.NoError(t, decodeDefinition(data, reflect.ValueOf(&decoded).Elem(), fields, nil))
+	assert.Equal(t, report, decoded)
+
+	_, err = decodePacked([]byte("unterminated"), reflect.ValueOf(new(string)).Elem())
+	assert.EqualError(t, err, "variable length string is not terminated")
+}
+
+func TestFieldDataType(t *testing.T) {
+	for value, dataType := range map[interface{}]uint32{
+		simconnect_data.LatLonAlt{}:                  simconnect_data.DATATYPE_LATLONALT,
+		simconnect_data.XYZ{}:                        simconnect_data.DATATYPE_XYZ,
+		simconnect_data.Waypoint{}:                   simconnect_data.DATATYPE_WAYPOINT,
+		simconnect_data.SimconnectDataInitPosition{}: simconnect_data.DATATYPE_INITPOSITION,
+		simconnect_data.MarkerState{}:                simconnect_data.DATATYPE_MARKERSTATE,
+		"":                                           simconnect_data.DATATYPE_STRINGV,
+	} {
+		actual, err := fieldDataType(reflect.TypeOf(value))
+		require.NoError(t, err)
+		assert.Equal(t, dataType, actual, "%T", value)
+	}
+
+	_, err := fieldDataType(reflect.TypeOf(struct{ X float64 }{}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,317 @@
+package simconnect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// ErrDisconnected is returned, wrapped with the cause, by calls made while the connection to the simulator is down.
+var ErrDisconnected = errors.New("disconnected from simulator")
+
+var errSimulatorQuit = errors.New("simulator quit")
+
+// ConnectionState describes whether an instance is connected to the simulator.
+type ConnectionState int
+
+const (
+	// StateConnected is reported once a connection has been re-established, see WithReconnect
+	StateConnected ConnectionState = iota
+	// StateDisconnected is reported when the simulator quits or the connection to it breaks
+	StateDisconnected
+	// StateClosed is reported once Close has been called, after which no further changes are reported
+	StateClosed
+)
+
+func (state ConnectionState) String() string {
+	switch state {
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateClosed:
+		return "closed"
+	}
+	return fmt.Sprintf("ConnectionState(%d)", int(state))
+}
+
+// ConnectionStateChange is sent on the channels returned by ConnectionStateChanges. Err is the reason the connection
+// was lost for StateDisconnected.
+type ConnectionStateChange struct {
+	State ConnectionState
+	Err   error
+}
+
+// stateWatcherBufferSize is the number of state changes buffered for a watcher before further changes are dropped.
+const stateWatcherBufferSize = 16
+
+// definitionField is one AddToDataDefinition call, kept so definitions can be registered again after reconnecting.
+type definitionField struct {
+	fieldIndex int
+	fieldName  string
+
+	name     string
+	unit     string
+	dataType uint32
+	epsilon  float32
+	datumID  uint32
+}
+
+// packet describes the AddToDataDefinition call adding the field to definitionID, for exceptions to be matched to it.
+func (field definitionField) packet(definitionID uint32) sentPacket {
+	return sentPacket{
+		method:       "AddToDataDefinition",
+		field:        true,
+		definitionID: definitionID,
+		fieldIndex:   field.fieldIndex,
+		fieldName:    field.fieldName,
+		tag:          field.name,
+	}
+}
+
+// add returns the call adding the field to definitionID.
+func (field definitionField) add(definitionID uint32) func(transport Transport) error {
+	return func(transport Transport) error {
+		return transport.AddToDataDefinition(definitionID, field.name, field.unit, field.dataType, field.epsilon, field.datumID)
+	}
+}
+
+// ConnectionStateChanges returns a channel receiving every change of connection state until ctx is done or the
+// instance is closed, at which point the channel is closed.
+func (instance *SimconnectInstance) ConnectionStateChanges(ctx context.Context) <-chan ConnectionStateChange {
+	changes := make(chan ConnectionStateChange, stateWatcherBufferSize)
+
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	if instance.stopped {
+		changes <- instance.finalState()
+		close(changes)
+		return changes
+	}
+	instance.stateWatchers[changes] = struct{}{}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-instance.dispatcherDone:
+		}
+
+		instance.dispatchMutex.Lock()
+		defer instance.dispatchMutex.Unlock()
+
+		if _, ok := instance.stateWatchers[changes]; ok {
+			delete(instance.stateWatchers, changes)
+			close(changes)
+		}
+	}()
+
+	return changes
+}
+
+// notifyState sends change to every state watcher. It must be called with dispatchMutex held.
+func (instance *SimconnectInstance) notifyState(change ConnectionStateChange) {
+	for watcher := range instance.stateWatchers {
+		select {
+		case watcher <- change:
+		default:
+		}
+	}
+}
+
+// finalState is the state the instance was left in once the dispatcher stopped. It must be called with
+// dispatchMutex held.
+func (instance *SimconnectInstance) finalState() ConnectionStateChange {
+	if instance.dispatchErr == errConnectionClosed {
+		return ConnectionStateChange{State: StateClosed}
+	}
+	return ConnectionStateChange{State: StateDisconnected, Err: instance.dispatchErr}
+}
+
+// recordDefinitionField remembers a field added to definitionID for replay after reconnecting.
+func (instance *SimconnectInstance) recordDefinitionField(definitionID uint32, field definitionField) {
+	instance.definitionMapMutex.Lock()
+	defer instance.definitionMapMutex.Unlock()
+
+	instance.definitionFields[definitionID] = append(instance.definitionFields[definitionID], field)
+}
+
+// recordEvent remembers a system event subscription or client event mapping for replay after reconnecting.
+func (instance *SimconnectInstance) recordEvent(events map[uint32]string, eventID uint32, eventName string) {
+	instance.eventMapMutex.Lock()
+	defer instance.eventMapMutex.Unlock()
+
+	events[eventID] = eventName
+}
+
+// disconnected handles the loss of the connection. It returns true once the connection has been re-established, or
+// false when the dispatcher should stop.
+func (instance *SimconnectInstance) disconnected(cause error) bool {
+	if instance.reconnectBackoff <= 0 {
+		instance.fail(cause)
+		return false
+	}
+
+	instance.interrupt(fmt.Errorf("%w: %v", ErrDisconnected, cause))
+
+	backoff := instance.reconnectBackoff
+	for {
+		select {
+		case <-instance.stopDispatcher:
+			instance.fail(errConnectionClosed)
+			return false
+		case <-time.After(backoff):
+		}
+
+		err := instance.reconnect()
+		if err == nil {
+			instance.resume()
+			return true
+		}
+
+		backoff *= 2
+		if backoff > instance.maxReconnectBackoff {
+			backoff = instance.maxReconnectBackoff
+		}
+	}
+}
+
+// interrupt fails every pending request with err and makes new requests fail with it until resume is called.
+// Listeners are left in place so they carry on receiving messages once reconnected.
+func (instance *SimconnectInstance) interrupt(err error) {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	instance.dispatchErr = err
+	for _, waiter := range instance.requestWaiters {
+		deliver(waiter, dispatchResult{err: err})
+	}
+	instance.notifyState(ConnectionStateChange{State: StateDisconnected, Err: err})
+}
+
+func (instance *SimconnectInstance) resume() {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	instance.dispatchErr = nil
+	instance.notifyState(ConnectionStateChange{State: StateConnected})
+}
+
+// reconnect replaces the transport with a newly opened one and replays every data definition, system event
+// subscription, client event mapping and data subscription made so far. The transport stays locked throughout so
+// calls made meanwhile are not replayed twice.
+func (instance *SimconnectInstance) reconnect() error {
+	instance.transportMutex.Lock()
+	defer instance.transportMutex.Unlock()
+
+	instance.transport.Close()
+
+	transport, err := instance.newTransport()
+	if err != nil {
+		return err
+	}
+	instance.transport = transport
+
+	// Send IDs start again on the new connection
+	instance.dispatchMutex.Lock()
+	instance.sentPackets = map[uint32]sentPacket{}
+	instance.dispatchMutex.Unlock()
+
+	if err := transport.Open(instance.name); err != nil {
+		return err
+	}
+	if err := instance.awaitOpen(transport); err != nil {
+		return err
+	}
+
+	// The simulator reports the fields it rejects afresh, and the catalog is checked again, on the new connection
+	instance.definitionMapMutex.Lock()
+	instance.rejectedFields = map[uint32]map[int]*FieldError{}
+	instance.settableChecked = map[uint32]bool{}
+	definitionIDs := make([]uint32, 0, len(instance.definitionFields))
+	definitionFields := make(map[uint32][]definitionField, len(instance.definitionFields))
+	for definitionID, fields := range instance.definitionFields {
+		definitionIDs = append(definitionIDs, definitionID)
+		definitionFields[definitionID] = fields
+	}
+	instance.definitionMapMutex.Unlock()
+
+	// Sent like any other call so the exceptions they cause are matched to them
+	sort.Slice(definitionIDs, func(i, j int) bool { return definitionIDs[i] < definitionIDs[j] })
+	for _, definitionID := range definitionIDs {
+		for _, field := range definitionFields[definitionID] {
+			if err := instance.sendOn(transport, field.packet(definitionID), field.add(definitionID)); err != nil {
+				return fmt.Errorf("error replaying data definitions: %v", err)
+			}
+		}
+	}
+
+	instance.eventMapMutex.Lock()
+	defer instance.eventMapMutex.Unlock()
+
+	for eventID, eventName := range instance.clientEvents {
+		err := instance.sendOn(transport, sentPacket{method: "MapClientEventToSimEvent"}, func(transport Transport) error {
+			return transport.MapClientEventToSimEvent(eventID, eventName)
+		})
+		if err != nil {
+			return fmt.Errorf("error replaying client event %s: %v", eventName, err)
+		}
+	}
+	for eventID, eventName := range instance.systemEvents {
+		err := instance.sendOn(transport, sentPacket{method: "SubscribeToSystemEvent"}, func(transport Transport) error {
+			return transport.SubscribeToSystemEvent(eventID, eventName)
+		})
+		if err != nil {
+			return fmt.Errorf("error replaying system event %s: %v", eventName, err)
+		}
+		if state, ok := instance.systemEventStates[eventID]; ok {
+			err := instance.sendOn(transport, sentPacket{method: "SetSystemEventState"}, func(transport Transport) error {
+				return transport.SetSystemEventState(eventID, state)
+			})
+			if err != nil {
+				return fmt.Errorf("error replaying state of system event %s: %v", eventName, err)
+			}
+		}
+	}
+	for requestID, sub := range instance.subscriptions {
+		packet := sentPacket{method: "RequestDataOnSimObject", request: true, requestID: requestID}
+		err := instance.sendOn(transport, packet, func(transport Transport) error {
+			return transport.RequestDataOnSimObject(requestID, sub.definitionID, sub.objectID, sub.period, sub.options.Flags, sub.options.Origin, sub.options.Interval, sub.options.Limit)
+		})
+		if err != nil {
+			return fmt.Errorf("error replaying subscription %d: %v", requestID, err)
+		}
+	}
+
+	return nil
+}
+
+// awaitOpen reads messages straight from transport until the simulator acknowledges the connection, as the
+// dispatcher is busy reconnecting.
+func (instance *SimconnectInstance) awaitOpen(transport Transport) error {
+	ctx, cancel := instance.withTimeout(context.Background())
+	defer cancel()
+
+	for {
+		data, err := transport.GetNextDispatch()
+		if err != nil {
+			return err
+		}
+		if len(data) >= 12 && recvID(data) == simconnect_data.RECV_ID_OPEN {
+			return nil
+		}
+		if len(data) > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("error waiting for open connection: %w", ctx.Err())
+		case <-time.After(dispatchPollInterval):
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package simconnect
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// waitForState returns the next change of state sent on changes, failing the test if none arrives in time.
+func waitForState(t *testing.T, changes <-chan ConnectionStateChange) ConnectionStateChange {
+	select {
+	case change := <-changes:
+		return change
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no change of state")
+		return ConnectionStateChange{}
+	}
+}
+
+func TestSendWhileDisconnected(t *testing.T) {
+	fake := NewFakeTransport()
+	instance, err := NewSimConnectWithTransport(t.Name(), fake, WithReconnect(time.Hour, time.Hour))
+	require.NoError(t, err)
+	defer instance.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := instance.ConnectionStateChanges(ctx)
+
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_QUIT, &simconnect_data.RecvQuit{}))
+	assert.Equal(t, StateDisconnected, waitForState(t, changes).State)
+
+	// Calls which only send fail like requests rather than reaching the transport being replaced
+	err = instance.MapClientEventToSimEvent(10, "AP_MASTER")
+	assert.True(t, errors.Is(err, ErrDisconnected), err)
+	err = instance.SendText(0, 1, "hi")
+	assert.True(t, errors.Is(err, ErrDisconnected), err)
+	assert.Empty(t, fake.CallsTo("MapClientEventToSimEvent"))
+	assert.Empty(t, fake.CallsTo("Text"))
+}
+
+func TestReconnectReplaysDefinitionFields(t *testing.T) {
+	// The simulator rejects a different simvar on each connection
+	rejected := "AUTOPILOT ALTITUDE LOCK VR:3"
+	fake := newMisspeltFake()
+	fake.On("AddToDataDefinition", func(fake *FakeTransport, call FakeCall) error {
+		if call.Args[1] != rejected {
+			return nil
+		}
+		return fake.Queue(simconnect_data.RECV_ID_EXCEPTION, &simconnect_data.RecvException{
+			Exception: simconnect_data.EXCEPTION_NAME_UNRECOGNIZED,
+			SendID:    call.SendID,
+			Index:     2,
+		})
+	})
+	instance, err := NewSimConnectWithTransport(t.Name(), fake, WithReconnect(10*time.Millisecond, 10*time.Millisecond))
+	require.NoError(t, err)
+	defer instance.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := instance.ConnectionStateChanges(ctx)
+
+	_, err = requestMisspeltReport(t, instance)
+	var fieldErr *FieldError
+	require.True(t, errors.As(err, &fieldErr), "%v", err)
+	assert.Equal(t, "APSelectedAlt", fieldErr.Field)
+
+	rejected = "Plane Heading Degrees True"
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_QUIT, &simconnect_data.RecvQuit{}))
+	assert.Equal(t, StateDisconnected, waitForState(t, changes).State)
+	assert.Equal(t, StateConnected, waitForState(t, changes).State)
+
+	// The rejection of the first connection is forgotten, that of the replayed field matched to it
+	_, err = requestMisspeltReport(t, instance)
+	require.True(t, errors.As(err, &fieldErr), "%v", err)
+	assert.Equal(t, "Heading", fieldErr.Field)
+	assert.Equal(t, "AddToDataDefinition", fieldErr.Err.Method)
+}
@@ -0,0 +1,185 @@
+package simconnect
+
+import (
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// handler is a function registered with one of the On* methods for the messages with the given receive IDs.
+type handler struct {
+	recvIDs map[uint32]bool
+	fn      func(data []byte)
+}
+
+// OnMessage registers handler to be called with each message of recvID, one of the RECV_ID_* constants, including
+// its Recv header. Handlers see the messages answering the requests of the library too. The function returned removes
+// the handler.
+//
+// The On* methods all share a single goroutine of the instance. It calls the handlers with one message at a time in
+// the order the messages arrive, and for each message in the order the handlers were registered. Handlers never block
+// the dispatcher or the requests of the library, so they may call the methods of the instance, but a slow handler
+// delays the others. The messages arriving meanwhile are buffered, up to as many as a request for every object in the
+// largest radius returns, then dropped and counted by DroppedHandlerMessages.
+func (instance *SimconnectInstance) OnMessage(recvID uint32, handler func(data []byte)) func() {
+	return instance.on(handler, recvID)
+}
+
+// OnEvent registers handler to be called with every event, including the messages extending RecvEvent. See OnMessage.
+func (instance *SimconnectInstance) OnEvent(handler func(event Event)) func() {
+	return instance.on(func(data []byte) {
+		recvEvent, err := simconnect_data.DecodeRecvEvent(data)
+		if err != nil {
+			return
+		}
+		handler(Event{RecvEvent: *recvEvent, Name: instance.eventName(recvEvent.EventID), Message: data})
+	}, simconnect_data.RecvEventIDs...)
+}
+
+// OnSimObjectData registers handler to be called with the header and whole message of all the data received for
+// sim objects, by object or by type. See OnMessage.
+func (instance *SimconnectInstance) OnSimObjectData(handler func(header simconnect_data.RecvSimobjectDataByType, data []byte)) func() {
+	return instance.on(func(data []byte) {
+		header, err := decodeHeader(data)
+		if err != nil {
+			return
+		}
+		handler(header, data)
+	}, simconnect_data.RECV_ID_SIMOBJECT_DATA, simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE)
+}
+
+// OnQuit registers handler to be called when the simulator quits. See OnMessage.
+func (instance *SimconnectInstance) OnQuit(handler func()) func() {
+	return instance.on(func(data []byte) {
+		handler()
+	}, simconnect_data.RECV_ID_QUIT)
+}
+
+// OnException registers handler to be called with every exception sent by the simulator, whether or not it was
+// matched to a request. See OnMessage.
+func (instance *SimconnectInstance) OnException(handler func(err *SimConnectError)) func() {
+	return instance.on(func(data []byte) {
+		exception, err := simconnect_data.DecodeRecvException(data)
+		if err != nil {
+			return
+		}
+		handler(instance.exceptionError(*exception))
+	}, simconnect_data.RECV_ID_EXCEPTION)
+}
+
+// OnAssignedObjectID registers handler to be called with the object ID assigned to each AI object created. See
+// OnMessage.
+func (instance *SimconnectInstance) OnAssignedObjectID(handler func(requestID, objectID uint32)) func() {
+	return instance.on(func(data []byte) {
+		assigned, err := simconnect_data.DecodeRecvAssignedObjectID(data)
+		if err != nil {
+			return
+		}
+		handler(assigned.RequestID, assigned.ObjectID)
+	}, simconnect_data.RECV_ID_ASSIGNED_OBJECT_ID)
+}
+
+// OnObjectAddRemove registers handler to be called for the ObjectAdded and ObjectRemoved system events. See
+// OnMessage.
+func (instance *SimconnectInstance) OnObjectAddRemove(handler func(event simconnect_data.RecvEventObjectAddRemove)) func() {
+	return instance.on(func(data []byte) {
+		event, err := simconnect_data.DecodeRecvEventObjectAddRemove(data)
+		if err != nil {
+			return
+		}
+		handler(*event)
+	}, simconnect_data.RECV_ID_EVENT_OBJECT_ADDREMOVE)
+}
+
+// OnFilename registers handler to be called for the system events carrying a file name, such as FlightLoaded. See
+// OnMessage.
+func (instance *SimconnectInstance) OnFilename(handler func(event simconnect_data.RecvEventFilename)) func() {
+	return instance.on(func(data []byte) {
+		event, err := simconnect_data.DecodeRecvEventFilename(data)
+		if err != nil {
+			return
+		}
+		handler(*event)
+	}, simconnect_data.RECV_ID_EVENT_FILENAME)
+}
+
+// OnFrame registers handler to be called for the Frame and PauseFrame system events. See OnMessage.
+func (instance *SimconnectInstance) OnFrame(handler func(event simconnect_data.RecvEventFrame)) func() {
+	return instance.on(func(data []byte) {
+		event, err := simconnect_data.DecodeRecvEventFrame(data)
+		if err != nil {
+			return
+		}
+		handler(*event)
+	}, simconnect_data.RECV_ID_EVENT_FRAME)
+}
+
+// DroppedHandlerMessages returns the number of messages dropped before reaching the handlers registered with the On*
+// methods, as they were not keeping up. See OnMessage.
+func (instance *SimconnectInstance) DroppedHandlerMessages() uint64 {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	if instance.router == nil {
+		return 0
+	}
+	return instance.router.dropped
+}
+
+// on registers fn for the messages with recvIDs, starting the router on first use. Handlers are called in the order
+// they were registered.
+func (instance *SimconnectInstance) on(fn func(data []byte), recvIDs ...uint32) func() {
+	h := &handler{recvIDs: map[uint32]bool{}, fn: fn}
+	for _, recvID := range recvIDs {
+		h.recvIDs[recvID] = true
+	}
+
+	instance.handlersMutex.Lock()
+	instance.handlers = append(instance.handlers, h)
+	instance.handlersMutex.Unlock()
+
+	instance.routerOnce.Do(func() {
+		router := instance.listenAll()
+		instance.dispatchMutex.Lock()
+		instance.router = router
+		instance.dispatchMutex.Unlock()
+		go instance.runHandlers(router)
+	})
+
+	return func() {
+		instance.handlersMutex.Lock()
+		defer instance.handlersMutex.Unlock()
+
+		for i, registered := range instance.handlers {
+			if registered == h {
+				instance.handlers = append(instance.handlers[:i:i], instance.handlers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// runHandlers calls the registered handlers with each message received by l until the instance stops.
+func (instance *SimconnectInstance) runHandlers(l *listener) {
+	defer instance.unlisten(l)
+
+	for result := range l.results {
+		if result.err != nil {
+			return
+		}
+
+		id := recvID(result.data)
+		var fns []func(data []byte)
+		instance.handlersMutex.Lock()
+		for _, h := range instance.handlers {
+			if h.recvIDs[id] {
+				fns = append(fns, h.fn)
+			}
+		}
+		instance.handlersMutex.Unlock()
+
+		// The message is shared with the other listeners
+		data := append([]byte(nil), result.data...)
+		for _, fn := range fns {
+			fn(data)
+		}
+	}
+}
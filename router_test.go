@@ -0,0 +1,184 @@
+package simconnect
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// received collects what handlers are called with, in order.
+type received struct {
+	mutex  sync.Mutex
+	values []interface{}
+}
+
+func (r *received) add(value interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.values = append(r.values, value)
+}
+
+func (r *received) get() []interface{} {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]interface{}(nil), r.values...)
+}
+
+// waitFor waits until count values have been received and returns them.
+func (r *received) waitFor(t *testing.T, count int) []interface{} {
+	t.Helper()
+	require.Eventually(t, func() bool { return len(r.get()) >= count }, 5*time.Second, 10*time.Millisecond)
+	return r.get()
+}
+
+func TestHandlers(t *testing.T) {
+	fake := NewFakeTransport()
+	fake.On("TransmitClientEvent", func(fake *FakeTransport, call FakeCall) error {
+		return fake.Queue(simconnect_data.RECV_ID_EXCEPTION, &simconnect_data.RecvException{
+			Exception: simconnect_data.EXCEPTION_UNRECOGNIZED_ID,
+			SendID:    call.SendID,
+			Index:     3,
+		})
+	})
+
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+	require.NoError(t, instance.SubscribeToSystemEvent(7, "Pause"))
+
+	var events, frames, exceptions received
+	instance.OnEvent(func(event Event) { events.add(event) })
+	instance.OnFrame(func(event simconnect_data.RecvEventFrame) { frames.add(event) })
+	instance.OnException(func(err *SimConnectError) { exceptions.add(err) })
+
+	pause := simconnect_data.RecvEvent{EventID: 7, Data: 1}
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_EVENT, &pause))
+	frame := simconnect_data.RecvEventFrame{FrameRate: 30, SimSpeed: 1}
+	frame.EventID = 8
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_EVENT_FRAME, &frame))
+	require.NoError(t, instance.TransmitClientID(1, 0))
+
+	got := events.waitFor(t, 2)
+	assert.Equal(t, "Pause", got[0].(Event).Name)
+	assert.Equal(t, uint32(1), got[0].(Event).Data)
+	// Frame events are events too
+	assert.Equal(t, uint32(8), got[1].(Event).EventID)
+	assert.Equal(t, simconnect_data.RECV_ID_EVENT_FRAME, got[1].(Event).ID)
+
+	got = frames.waitFor(t, 1)
+	assert.Equal(t, float32(30), got[0].(simconnect_data.RecvEventFrame).FrameRate)
+
+	got = exceptions.waitFor(t, 1)
+	exception := got[0].(*SimConnectError)
+	assert.ErrorIs(t, exception, ErrUnrecognizedID)
+	assert.Equal(t, "TransmitClientEvent", exception.Method)
+	assert.Equal(t, uint32(3), exception.Index)
+}
+
+func TestHandlersOrderAndRemoval(t *testing.T) {
+	fake := NewFakeTransport()
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	var calls received
+	instance.OnEvent(func(event Event) { calls.add("first") })
+	remove := instance.OnEvent(func(event Event) { calls.add("second") })
+	instance.OnMessage(simconnect_data.RECV_ID_EVENT, func(data []byte) { calls.add("third") })
+
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_EVENT, &simconnect_data.RecvEvent{EventID: 1}))
+	assert.Equal(t, []interface{}{"first", "second", "third"}, calls.waitFor(t, 3))
+
+	remove()
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_EVENT, &simconnect_data.RecvEvent{EventID: 1}))
+	assert.Equal(t, []interface{}{"first", "second", "third", "first", "third"}, calls.waitFor(t, 5))
+}
+
+func TestDroppedHandlerMessages(t *testing.T) {
+	fake := NewFakeTransport()
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	// The handler is stuck on the first frame while the others arrive
+	release := make(chan struct{})
+	var frames received
+	instance.OnFrame(func(event simconnect_data.RecvEventFrame) {
+		if len(frames.get()) == 0 {
+			<-release
+		}
+		frames.add(event.FrameRate)
+	})
+	assert.Zero(t, instance.DroppedHandlerMessages())
+
+	const sent = objectsBufferSize + 100
+	for i := 0; i < sent; i++ {
+		require.NoError(t, fake.Queue(simconnect_data.RECV_ID_EVENT_FRAME, &simconnect_data.RecvEventFrame{FrameRate: float32(i)}))
+	}
+	require.Eventually(t, func() bool { return instance.DroppedHandlerMessages() > 0 }, 5*time.Second, 10*time.Millisecond)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return uint64(len(frames.get()))+instance.DroppedHandlerMessages() == sent
+	}, 5*time.Second, 10*time.Millisecond)
+	// The frames handled are the first ones, in order
+	handled := frames.get()
+	for i, frameRate := range handled {
+		assert.Equal(t, float32(i), frameRate)
+	}
+}
+
+func TestOnSimObjectDataAndQuit(t *testing.T) {
+	fake := NewFakeTransport()
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	var data, quits received
+	instance.OnSimObjectData(func(header simconnect_data.RecvSimobjectDataByType, message []byte) {
+		data.add(header)
+	})
+	instance.OnQuit(func() { quits.add(true) })
+
+	message := simconnect_data.RecvSimobjectDataByType{}
+	message.RequestID = 99
+	message.ObjectID = 4
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA, &message))
+	header := data.waitFor(t, 1)[0].(simconnect_data.RecvSimobjectDataByType)
+	assert.Equal(t, uint32(99), header.RequestID)
+	assert.Equal(t, uint32(4), header.ObjectID)
+
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_QUIT, &simconnect_data.RecvQuit{}))
+	quits.waitFor(t, 1)
+}
+
+func TestHandlersWithEmulator(t *testing.T) {
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	var assigned, data received
+	remove := instance.OnAssignedObjectID(func(requestID, objectID uint32) {
+		assigned.add([2]uint32{requestID, objectID})
+	})
+	defer remove()
+	instance.OnSimObjectData(func(header simconnect_data.RecvSimobjectDataByType, message []byte) {
+		data.add(header.ObjectID)
+	})
+
+	// Handlers see the responses to requests of the library too
+	_, err = Get[positionReport](instance, simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), data.waitFor(t, 1)[0])
+
+	objectID, err := instance.LoadNonATCAircraft("Boeing 747-8i Asobo", "G-HNDL", simconnect_data.SimconnectDataInitPosition{Altitude: 3000}, 1300)
+	require.NoError(t, err)
+	defer instance.RemoveAIObject(*objectID, 1301)
+
+	assert.Equal(t, [2]uint32{1300, *objectID}, assigned.waitFor(t, 1)[0])
+}
@@ -0,0 +1,35 @@
+package simconnect
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Set writes the fields of value to the simvars they are tagged with on objectID, simconnect_data.OBJECT_ID_USER being
+// the user aircraft. T is registered as a data definition on first use, like for Get, and every simvar in it must be
+// settable. The simulator reports failures asynchronously as exceptions, so a nil error only means the data was sent.
+func Set[T any](instance *SimconnectInstance, objectID uint32, value T) error {
+	return instance.set(objectID, reflect.ValueOf(&value).Elem())
+}
+
+// set writes value, a struct registered as a data definition on first use, to objectID.
+func (instance *SimconnectInstance) set(objectID uint32, value reflect.Value) error {
+	definitionID, err := instance.definitionFor(value.Type())
+	if err != nil {
+		return err
+	}
+
+	instance.definitionMapMutex.Lock()
+	rejected := instance.rejectedFields[definitionID]
+	if len(rejected) > 0 && !instance.dropRejectedFields {
+		instance.definitionMapMutex.Unlock()
+		return firstFieldError(rejected)
+	}
+	data, err := encodeDefinition(value, instance.definitionFields[definitionID], rejected)
+	instance.definitionMapMutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %v", value.Type(), err)
+	}
+
+	return instance.setDataOnSimObject(definitionID, objectID, 0, 0, uint32(len(data)), data)
+}
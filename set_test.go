@@ -0,0 +1,57 @@
+package simconnect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+type structReport struct {
+	Position simconnect_data.LatLonAlt `name:"STRUCT LATLONALT"`
+	Velocity simconnect_data.XYZ       `name:"STRUCT WORLDVELOCITY"`
+}
+
+func TestSetStructs(t *testing.T) {
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	report, err := Get[structReport](instance, simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+
+	position, err := Get[positionReport](instance, simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	assert.Equal(t, position.Latitude, report.Position.Latitude)
+	assert.Equal(t, position.Longitude, report.Position.Longitude)
+
+	report.Position.Altitude += 1000
+	report.Velocity = simconnect_data.XYZ{X: 10, Y: 0, Z: 150}
+	require.NoError(t, Set(instance, simconnect_data.OBJECT_ID_USER, *report))
+	waitForSim(time.Second)
+
+	updated, err := Get[structReport](instance, simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	assert.InDelta(t, report.Position.Altitude, updated.Position.Altitude, 1)
+	assert.InDelta(t, report.Velocity.Z, updated.Velocity.Z, 1)
+}
+
+func TestStringV(t *testing.T) {
+	type titleReport struct {
+		Title    string  `name:"Title"`
+		ATCID    string  `name:"ATC ID"`
+		Altitude float64 `name:"Plane Altitude" unit:"feet"`
+	}
+
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	report, err := Get[titleReport](instance, simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+	assert.NotEmpty(t, report.Title)
+	assert.NotZero(t, report.Altitude)
+}
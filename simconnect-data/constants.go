@@ -1,5 +1,7 @@
 package simconnect_data
 
+//go:generate go run ../cmd/sc-headergen -o simconnect_gen.go SimConnect.h
+
 // Exception Fail ID
 const E_FAIL uint32 = 0x80004005
 
@@ -59,6 +61,86 @@ const (
 	RECV_ID_PICK
 )
 
+// Exception IDs, see RecvException
+const (
+	EXCEPTION_NONE uint32 = iota
+	EXCEPTION_ERROR
+	EXCEPTION_SIZE_MISMATCH
+	EXCEPTION_UNRECOGNIZED_ID
+	EXCEPTION_UNOPENED
+	EXCEPTION_VERSION_MISMATCH
+	EXCEPTION_TOO_MANY_GROUPS
+	EXCEPTION_NAME_UNRECOGNIZED
+	EXCEPTION_TOO_MANY_EVENT_NAMES
+	EXCEPTION_EVENT_ID_DUPLICATE
+	EXCEPTION_TOO_MANY_MAPS
+	EXCEPTION_TOO_MANY_OBJECTS
+	EXCEPTION_TOO_MANY_REQUESTS
+	EXCEPTION_WEATHER_INVALID_PORT
+	EXCEPTION_WEATHER_INVALID_METAR
+	EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION
+	EXCEPTION_WEATHER_UNABLE_TO_CREATE_STATION
+	EXCEPTION_WEATHER_UNABLE_TO_REMOVE_STATION
+	EXCEPTION_INVALID_DATA_TYPE
+	EXCEPTION_INVALID_DATA_SIZE
+	EXCEPTION_DATA_ERROR
+	EXCEPTION_INVALID_ARRAY
+	EXCEPTION_CREATE_OBJECT_FAILED
+	EXCEPTION_LOAD_FLIGHTPLAN_FAILED
+	EXCEPTION_OPERATION_INVALID_FOR_OBJECT_TYPE
+	EXCEPTION_ILLEGAL_OPERATION
+	EXCEPTION_ALREADY_SUBSCRIBED
+	EXCEPTION_INVALID_ENUM
+	EXCEPTION_DEFINITION_ERROR
+	EXCEPTION_DUPLICATE_ID
+	EXCEPTION_DATUM_ID
+	EXCEPTION_OUT_OF_BOUNDS
+	EXCEPTION_ALREADY_CREATED
+	EXCEPTION_OBJECT_OUTSIDE_REALITY_BUBBLE
+	EXCEPTION_OBJECT_CONTAINER
+	EXCEPTION_OBJECT_AI
+	EXCEPTION_OBJECT_ATC
+	EXCEPTION_OBJECT_SCHEDULE
+
+	// MSFS only
+	EXCEPTION_JETWAY_DATA
+	EXCEPTION_ACTION_NOT_FOUND
+	EXCEPTION_NOT_AN_ACTION
+	EXCEPTION_INCORRECT_ACTION_PARAMS
+	EXCEPTION_GET_INPUT_EVENT_FAILED
+	EXCEPTION_SET_INPUT_EVENT_FAILED
+)
+
+// UNUSED stands in for an ID which is not given, such as the datum ID of a data definition
+const UNUSED uint32 = 0xffffffff
+
+// MAX_PATH is the size of the file names sent by the simulator
+const MAX_PATH = 260
+
+// Size of the cloud state array, see RecvCloudState
+const (
+	CLOUD_STATE_ARRAY_WIDTH = 64
+	CLOUD_STATE_ARRAY_SIZE  = CLOUD_STATE_ARRAY_WIDTH * CLOUD_STATE_ARRAY_WIDTH
+)
+
+// Flags of a VOR, see FacilityVor
+const (
+	RECV_ID_VOR_LIST_HAS_NAV_SIGNAL  uint32 = 0x1 // has a local navigation frequency
+	RECV_ID_VOR_LIST_HAS_LOCALIZER   uint32 = 0x2 // has a localizer frequency, with a localizer heading
+	RECV_ID_VOR_LIST_HAS_GLIDE_SLOPE uint32 = 0x4 // has a localizer frequency, with a glide slope
+	RECV_ID_VOR_LIST_HAS_DME         uint32 = 0x8 // has DME
+)
+
+// Data Request Flags
+const (
+	DATA_REQUEST_FLAG_DEFAULT uint32 = 0x00
+	DATA_REQUEST_FLAG_CHANGED uint32 = 0x01 // send only when the data has changed
+	DATA_REQUEST_FLAG_TAGGED  uint32 = 0x02 // send each datum preceded by its datum ID, with CHANGED only those changed
+)
+
+// Object ID of the user aircraft, see RequestDataOnSimObject
+const OBJECT_ID_USER uint32 = 0
+
 // SimObject Types
 const (
 	SIMOBJECT_TYPE_USER uint32 = iota
@@ -69,6 +151,12 @@ const (
 	SIMOBJECT_TYPE_GROUND
 )
 
+// States of a system event subscription, see SetSystemEventState
+const (
+	SIMCONNECT_STATE_OFF uint32 = iota
+	SIMCONNECT_STATE_ON
+)
+
 const (
 	SIMCONNECT_PERIOD_NEVER uint32 = iota
 	SIMCONNECT_PERIOD_ONCE
@@ -88,6 +176,48 @@ type SimconnectDataInitPosition struct {
 	Airspeed  uint32
 }
 
+// LatLonAlt is SIMCONNECT_DATA_LATLONALT, returned by simvars such as STRUCT LATLONALT
+type LatLonAlt struct {
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+}
+
+// XYZ is SIMCONNECT_DATA_XYZ, returned by simvars such as STRUCT WORLDVELOCITY
+type XYZ struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+// Waypoint is SIMCONNECT_DATA_WAYPOINT, Flags being a combination of the WAYPOINT_* flags
+type Waypoint struct {
+	Latitude        float64
+	Longitude       float64
+	Altitude        float64
+	Flags           uint32
+	KtsSpeed        float64
+	PercentThrottle float64
+}
+
+// Waypoint Flags
+const (
+	WAYPOINT_NONE                   uint32 = 0x00
+	WAYPOINT_SPEED_REQUESTED        uint32 = 0x04
+	WAYPOINT_THROTTLE_REQUESTED     uint32 = 0x08
+	WAYPOINT_COMPUTE_VERTICAL_SPEED uint32 = 0x10
+	WAYPOINT_ALTITUDE_IS_AGL        uint32 = 0x20
+	WAYPOINT_ON_GROUND              uint32 = 0x00100000
+	WAYPOINT_REVERSE                uint32 = 0x00200000
+	WAYPOINT_WRAP_TO_FIRST          uint32 = 0x00400000
+)
+
+// MarkerState is SIMCONNECT_DATA_MARKERSTATE
+type MarkerState struct {
+	MarkerName  [64]byte
+	MarkerState uint32
+}
+
 // Receive Data Struct - Used to get basic dispatch info which can then be handled later
 type Recv struct {
 	Size    uint32
@@ -127,6 +257,31 @@ type RecvEvent struct {
 	Data    uint32
 }
 
+// Sent for the ObjectAdded and ObjectRemoved system events, Data being the ID of the object
+type RecvEventObjectAddRemove struct {
+	RecvEvent
+	ObjType uint32 // SIMOBJECT_TYPE_*
+}
+
+// Sent for the FlightLoaded, FlightSaved, FlightPlanActivated, AircraftLoaded and similar system events
+type RecvEventFilename struct {
+	RecvEvent
+	FileName [MAX_PATH]byte
+	Flags    uint32
+}
+
+// Sent for the Frame and PauseFrame system events
+type RecvEventFrame struct {
+	RecvEvent
+	FrameRate float32
+	SimSpeed  float32
+}
+
+// Sent when the simulator quits
+type RecvQuit struct {
+	Recv
+}
+
 // Used to store SimObject return data
 type RecvSimobjectData struct {
 	Recv
@@ -152,3 +307,161 @@ type RecvSimobjectDataByType struct {
 type RecvAssignedObjectID struct {
 	RecvAssignedObject
 }
+
+// Sent for the WeatherModeChanged system event, EventID being the new weather mode
+type RecvEventWeatherMode struct {
+	RecvEvent
+}
+
+// Sent to the host when a multiplayer session is started
+type RecvEventMultiplayerServerStarted struct {
+	RecvEvent
+}
+
+// Sent to a client when it joins a multiplayer session
+type RecvEventMultiplayerClientStarted struct {
+	RecvEvent
+}
+
+// Sent when a multiplayer session ends
+type RecvEventMultiplayerSessionEnded struct {
+	RecvEvent
+}
+
+// RaceResult is SIMCONNECT_DATA_RACE_RESULT, the result of one racer in a race or lap
+type RaceResult struct {
+	NumberOfRacers uint32
+	MissionGUID    [16]byte
+	PlayerName     [MAX_PATH]byte
+	SessionType    [MAX_PATH]byte // such as "LAN" or "HOST"
+	Aircraft       [MAX_PATH]byte
+	PlayerRole     [MAX_PATH]byte
+	TotalTime      float64 // seconds
+	PenaltyTime    float64 // seconds
+	IsDisqualified uint32
+}
+
+// Sent for each racer when a race ends
+type RecvEventRaceEnd struct {
+	RecvEvent
+	RacerNumber uint32 // index of the racer the result is for
+	RacerData   RaceResult
+}
+
+// Sent for each racer when a lap is completed
+type RecvEventRaceLap struct {
+	RecvEvent
+	LapIndex  uint32 // index of the lap, 0 being the first
+	RacerData RaceResult
+}
+
+// Sent in reply to RequestSystemState, with the state in whichever of Integer, Float and String applies
+type RecvSystemState struct {
+	Recv
+	RequestID uint32
+	Integer   uint32
+	Float     float32
+	String    [MAX_PATH]byte
+}
+
+// Client data requested with RequestClientData, Data holding the values of the definition
+type RecvClientData struct {
+	RecvSimobjectData
+	Data []byte
+}
+
+// Sent in reply to WeatherRequestObservationAtStation and similar requests, Metar being the observation
+type RecvWeatherObservation struct {
+	Recv
+	RequestID uint32
+	Metar     string
+}
+
+// Sent in reply to WeatherRequestCloudState, Data holding ArraySize bytes of cloud density, normally
+// CLOUD_STATE_ARRAY_SIZE
+type RecvCloudState struct {
+	Recv
+	RequestID uint32
+	ArraySize uint32
+	Data      []byte
+}
+
+// Sent in reply to RequestReservedKey with the key assigned
+type RecvReservedKey struct {
+	Recv
+	ChoiceReserved [30]byte
+	ReservedKey    [50]byte
+}
+
+// Sent when a mission custom action is triggered, with its payload
+type RecvCustomAction struct {
+	RecvEvent
+	InstanceID        [16]byte // GUID of the action
+	WaitForCompletion uint32
+	PayLoad           string
+}
+
+// Header of the facilities lists, which may be split across several messages
+type RecvFacilitiesList struct {
+	Recv
+	RequestID   uint32
+	ArraySize   uint32 // number of facilities in this message
+	EntryNumber uint32 // index of this message, from 0
+	OutOf       uint32 // number of messages
+}
+
+// FacilityAirport is SIMCONNECT_DATA_FACILITY_AIRPORT
+type FacilityAirport struct {
+	Ident     [6]byte
+	Region    [3]byte
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+}
+
+// FacilityWaypoint is SIMCONNECT_DATA_FACILITY_WAYPOINT
+type FacilityWaypoint struct {
+	FacilityAirport
+	MagVar float32 // degrees
+}
+
+// FacilityNdb is SIMCONNECT_DATA_FACILITY_NDB
+type FacilityNdb struct {
+	FacilityWaypoint
+	Frequency uint32 // Hz
+}
+
+// FacilityVor is SIMCONNECT_DATA_FACILITY_VOR, Flags being a combination of the RECV_ID_VOR_LIST_* flags
+type FacilityVor struct {
+	FacilityNdb
+	Flags           uint32
+	Localizer       float32 // degrees
+	GlideLat        float64
+	GlideLon        float64
+	GlideAlt        float64
+	GlideSlopeAngle float32 // degrees
+}
+
+// Sent in reply to RequestFacilitiesList and SubscribeToFacilities for airports
+type RecvAirportList struct {
+	RecvFacilitiesList
+	Airports []FacilityAirport
+}
+
+// Sent in reply to RequestFacilitiesList and SubscribeToFacilities for VORs
+type RecvVorList struct {
+	RecvFacilitiesList
+	Vors []FacilityVor
+}
+
+// Sent in reply to RequestFacilitiesList and SubscribeToFacilities for NDBs
+type RecvNdbList struct {
+	RecvFacilitiesList
+	Ndbs []FacilityNdb
+}
+
+// Sent in reply to RequestFacilitiesList and SubscribeToFacilities for waypoints
+type RecvWaypointList struct {
+	RecvFacilitiesList
+	Waypoints []FacilityWaypoint
+}
@@ -0,0 +1,379 @@
+package simconnect_data
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSize is returned when a message is too short for its Recv header, the struct it holds or the arrays it
+// says it holds.
+var ErrInvalidSize = errors.New("invalid message size")
+
+// ErrUnexpectedID is returned when a message is not of the kind being decoded.
+var ErrUnexpectedID = errors.New("unexpected receive ID")
+
+// recvSize is the size of the Recv header at the start of every message
+const recvSize = 12
+
+// RecvEventIDs are the receive IDs of the messages extending RecvEvent, as decoded by DecodeRecvEvent.
+var RecvEventIDs = []uint32{
+	RECV_ID_EVENT,
+	RECV_ID_EVENT_OBJECT_ADDREMOVE,
+	RECV_ID_EVENT_FILENAME,
+	RECV_ID_EVENT_FRAME,
+	RECV_ID_EVENT_WEATHER_MODE,
+	RECV_ID_EVENT_MULTIPLAYER_SERVER_STARTED,
+	RECV_ID_EVENT_MULTIPLAYER_CLIENT_STARTED,
+	RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED,
+	RECV_ID_EVENT_RACE_END,
+	RECV_ID_EVENT_RACE_LAP,
+	RECV_ID_CUSTOM_ACTION,
+}
+
+// IsRecvEvent reports whether the message with the receive ID recvID extends RecvEvent.
+func IsRecvEvent(recvID uint32) bool {
+	for _, id := range RecvEventIDs {
+		if id == recvID {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeRecv returns the header of the message in data, checking data holds as much as its Size says.
+func DecodeRecv(data []byte) (*Recv, error) {
+	if len(data) < recvSize {
+		return nil, fmt.Errorf("%w: %d bytes is shorter than the header", ErrInvalidSize, len(data))
+	}
+
+	recv := &Recv{
+		Size:    binary.LittleEndian.Uint32(data[0:]),
+		Version: binary.LittleEndian.Uint32(data[4:]),
+		ID:      binary.LittleEndian.Uint32(data[8:]),
+	}
+	if recv.Size < recvSize || uint64(recv.Size) > uint64(len(data)) {
+		return nil, fmt.Errorf("%w: size %d given for a message of %d bytes", ErrInvalidSize, recv.Size, len(data))
+	}
+	return recv, nil
+}
+
+// Decode decodes the message in data into the receive struct for its ID, such as *RecvEventFrame for
+// RECV_ID_EVENT_FRAME. RECV_ID_NULL and RECV_ID_PICK have no struct and are returned as their *Recv header.
+func Decode(data []byte) (interface{}, error) {
+	recv, err := DecodeRecv(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch recv.ID {
+	case RECV_ID_EXCEPTION:
+		return DecodeRecvException(data)
+	case RECV_ID_OPEN:
+		return DecodeRecvOpen(data)
+	case RECV_ID_QUIT:
+		return DecodeRecvQuit(data)
+	case RECV_ID_EVENT:
+		return DecodeRecvEvent(data)
+	case RECV_ID_EVENT_OBJECT_ADDREMOVE:
+		return DecodeRecvEventObjectAddRemove(data)
+	case RECV_ID_EVENT_FILENAME:
+		return DecodeRecvEventFilename(data)
+	case RECV_ID_EVENT_FRAME:
+		return DecodeRecvEventFrame(data)
+	case RECV_ID_SIMOBJECT_DATA:
+		return DecodeRecvSimobjectData(data)
+	case RECV_ID_SIMOBJECT_DATA_BYTYPE:
+		return DecodeRecvSimobjectDataByType(data)
+	case RECV_ID_WEATHER_OBSERVATION:
+		return DecodeRecvWeatherObservation(data)
+	case RECV_ID_CLOUD_STATE:
+		return DecodeRecvCloudState(data)
+	case RECV_ID_ASSIGNED_OBJECT_ID:
+		return DecodeRecvAssignedObjectID(data)
+	case RECV_ID_RESERVED_KEY:
+		return DecodeRecvReservedKey(data)
+	case RECV_ID_CUSTOM_ACTION:
+		return DecodeRecvCustomAction(data)
+	case RECV_ID_SYSTEM_STATE:
+		return DecodeRecvSystemState(data)
+	case RECV_ID_CLIENT_DATA:
+		return DecodeRecvClientData(data)
+	case RECV_ID_EVENT_WEATHER_MODE:
+		return DecodeRecvEventWeatherMode(data)
+	case RECV_ID_AIRPORT_LIST:
+		return DecodeRecvAirportList(data)
+	case RECV_ID_VOR_LIST:
+		return DecodeRecvVorList(data)
+	case RECV_ID_NDB_LIST:
+		return DecodeRecvNdbList(data)
+	case RECV_ID_WAYPOINT_LIST:
+		return DecodeRecvWaypointList(data)
+	case RECV_ID_EVENT_MULTIPLAYER_SERVER_STARTED:
+		return DecodeRecvEventMultiplayerServerStarted(data)
+	case RECV_ID_EVENT_MULTIPLAYER_CLIENT_STARTED:
+		return DecodeRecvEventMultiplayerClientStarted(data)
+	case RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED:
+		return DecodeRecvEventMultiplayerSessionEnded(data)
+	case RECV_ID_EVENT_RACE_END:
+		return DecodeRecvEventRaceEnd(data)
+	case RECV_ID_EVENT_RACE_LAP:
+		return DecodeRecvEventRaceLap(data)
+	}
+	return recv, nil
+}
+
+func DecodeRecvException(data []byte) (*RecvException, error) {
+	return decodeFixed[RecvException](data, RECV_ID_EXCEPTION)
+}
+
+func DecodeRecvOpen(data []byte) (*RecvOpen, error) {
+	return decodeFixed[RecvOpen](data, RECV_ID_OPEN)
+}
+
+func DecodeRecvQuit(data []byte) (*RecvQuit, error) {
+	return decodeFixed[RecvQuit](data, RECV_ID_QUIT)
+}
+
+// DecodeRecvEvent decodes the RecvEvent at the start of any event, including the messages extending it.
+func DecodeRecvEvent(data []byte) (*RecvEvent, error) {
+	return decodeFixed[RecvEvent](data, RecvEventIDs...)
+}
+
+func DecodeRecvEventObjectAddRemove(data []byte) (*RecvEventObjectAddRemove, error) {
+	return decodeFixed[RecvEventObjectAddRemove](data, RECV_ID_EVENT_OBJECT_ADDREMOVE)
+}
+
+func DecodeRecvEventFilename(data []byte) (*RecvEventFilename, error) {
+	return decodeFixed[RecvEventFilename](data, RECV_ID_EVENT_FILENAME)
+}
+
+func DecodeRecvEventFrame(data []byte) (*RecvEventFrame, error) {
+	return decodeFixed[RecvEventFrame](data, RECV_ID_EVENT_FRAME)
+}
+
+func DecodeRecvEventWeatherMode(data []byte) (*RecvEventWeatherMode, error) {
+	return decodeFixed[RecvEventWeatherMode](data, RECV_ID_EVENT_WEATHER_MODE)
+}
+
+func DecodeRecvEventMultiplayerServerStarted(data []byte) (*RecvEventMultiplayerServerStarted, error) {
+	return decodeFixed[RecvEventMultiplayerServerStarted](data, RECV_ID_EVENT_MULTIPLAYER_SERVER_STARTED)
+}
+
+func DecodeRecvEventMultiplayerClientStarted(data []byte) (*RecvEventMultiplayerClientStarted, error) {
+	return decodeFixed[RecvEventMultiplayerClientStarted](data, RECV_ID_EVENT_MULTIPLAYER_CLIENT_STARTED)
+}
+
+func DecodeRecvEventMultiplayerSessionEnded(data []byte) (*RecvEventMultiplayerSessionEnded, error) {
+	return decodeFixed[RecvEventMultiplayerSessionEnded](data, RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED)
+}
+
+func DecodeRecvEventRaceEnd(data []byte) (*RecvEventRaceEnd, error) {
+	return decodeFixed[RecvEventRaceEnd](data, RECV_ID_EVENT_RACE_END)
+}
+
+func DecodeRecvEventRaceLap(data []byte) (*RecvEventRaceLap, error) {
+	return decodeFixed[RecvEventRaceLap](data, RECV_ID_EVENT_RACE_LAP)
+}
+
+// DecodeRecvSimobjectData decodes the header of data requested for an object, the values following it.
+func DecodeRecvSimobjectData(data []byte) (*RecvSimobjectData, error) {
+	return decodeFixed[RecvSimobjectData](data, RECV_ID_SIMOBJECT_DATA)
+}
+
+// DecodeRecvSimobjectDataByType decodes the header of data requested by type, the values following it.
+func DecodeRecvSimobjectDataByType(data []byte) (*RecvSimobjectDataByType, error) {
+	return decodeFixed[RecvSimobjectDataByType](data, RECV_ID_SIMOBJECT_DATA_BYTYPE)
+}
+
+func DecodeRecvAssignedObjectID(data []byte) (*RecvAssignedObjectID, error) {
+	return decodeFixed[RecvAssignedObjectID](data, RECV_ID_ASSIGNED_OBJECT_ID)
+}
+
+func DecodeRecvReservedKey(data []byte) (*RecvReservedKey, error) {
+	return decodeFixed[RecvReservedKey](data, RECV_ID_RESERVED_KEY)
+}
+
+func DecodeRecvSystemState(data []byte) (*RecvSystemState, error) {
+	return decodeFixed[RecvSystemState](data, RECV_ID_SYSTEM_STATE)
+}
+
+// DecodeRecvClientData decodes client data, Data being every byte after the header.
+func DecodeRecvClientData(data []byte) (*RecvClientData, error) {
+	clientData := &RecvClientData{}
+	rest, err := decodeHeader(data, &clientData.RecvSimobjectData, RECV_ID_CLIENT_DATA)
+	if err != nil {
+		return nil, err
+	}
+
+	clientData.Data = append([]byte(nil), rest...)
+	return clientData, nil
+}
+
+func DecodeRecvWeatherObservation(data []byte) (*RecvWeatherObservation, error) {
+	observation := &RecvWeatherObservation{}
+	header := struct {
+		Recv
+		RequestID uint32
+	}{}
+	rest, err := decodeHeader(data, &header, RECV_ID_WEATHER_OBSERVATION)
+	if err != nil {
+		return nil, err
+	}
+
+	observation.Recv, observation.RequestID = header.Recv, header.RequestID
+	if observation.Metar, err = stringV(rest); err != nil {
+		return nil, err
+	}
+	return observation, nil
+}
+
+func DecodeRecvCloudState(data []byte) (*RecvCloudState, error) {
+	cloudState := &RecvCloudState{}
+	header := struct {
+		Recv
+		RequestID uint32
+		ArraySize uint32
+	}{}
+	rest, err := decodeHeader(data, &header, RECV_ID_CLOUD_STATE)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(header.ArraySize) > uint64(len(rest)) {
+		return nil, fmt.Errorf("%w: %d bytes of cloud state in %d bytes", ErrInvalidSize, header.ArraySize, len(rest))
+	}
+
+	cloudState.Recv, cloudState.RequestID, cloudState.ArraySize = header.Recv, header.RequestID, header.ArraySize
+	cloudState.Data = append([]byte(nil), rest[:header.ArraySize]...)
+	return cloudState, nil
+}
+
+func DecodeRecvCustomAction(data []byte) (*RecvCustomAction, error) {
+	action := &RecvCustomAction{}
+	header := struct {
+		RecvEvent
+		InstanceID        [16]byte
+		WaitForCompletion uint32
+	}{}
+	rest, err := decodeHeader(data, &header, RECV_ID_CUSTOM_ACTION)
+	if err != nil {
+		return nil, err
+	}
+
+	action.RecvEvent, action.InstanceID, action.WaitForCompletion = header.RecvEvent, header.InstanceID, header.WaitForCompletion
+	if action.PayLoad, err = stringV(rest); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+func DecodeRecvAirportList(data []byte) (*RecvAirportList, error) {
+	list := &RecvAirportList{}
+	var err error
+	list.RecvFacilitiesList, list.Airports, err = decodeList[FacilityAirport](data, RECV_ID_AIRPORT_LIST)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func DecodeRecvVorList(data []byte) (*RecvVorList, error) {
+	list := &RecvVorList{}
+	var err error
+	list.RecvFacilitiesList, list.Vors, err = decodeList[FacilityVor](data, RECV_ID_VOR_LIST)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func DecodeRecvNdbList(data []byte) (*RecvNdbList, error) {
+	list := &RecvNdbList{}
+	var err error
+	list.RecvFacilitiesList, list.Ndbs, err = decodeList[FacilityNdb](data, RECV_ID_NDB_LIST)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func DecodeRecvWaypointList(data []byte) (*RecvWaypointList, error) {
+	list := &RecvWaypointList{}
+	var err error
+	list.RecvFacilitiesList, list.Waypoints, err = decodeList[FacilityWaypoint](data, RECV_ID_WAYPOINT_LIST)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// decodeFixed decodes a message held entirely by T, which is laid out as packed as the SimConnect structs are.
+func decodeFixed[T any](data []byte, recvIDs ...uint32) (*T, error) {
+	value := new(T)
+	if _, err := decodeHeader(data, value, recvIDs...); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// decodeHeader reads header, a struct starting with the Recv header, from the message in data after checking its ID
+// is one of recvIDs and its size holds all of header. It returns the rest of the message.
+func decodeHeader(data []byte, header interface{}, recvIDs ...uint32) ([]byte, error) {
+	recv, err := DecodeRecv(data)
+	if err != nil {
+		return nil, err
+	}
+	if !containsID(recvIDs, recv.ID) {
+		return nil, fmt.Errorf("%w: %d decoding %T", ErrUnexpectedID, recv.ID, header)
+	}
+
+	size := binary.Size(header)
+	if int(recv.Size) < size {
+		return nil, fmt.Errorf("%w: message of %d bytes too short for %T", ErrInvalidSize, recv.Size, header)
+	}
+
+	data = data[:recv.Size]
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, header); err != nil {
+		return nil, fmt.Errorf("decoding %T error: %v", header, err)
+	}
+	return data[size:], nil
+}
+
+// decodeList decodes a facilities list holding ArraySize facilities of type T.
+func decodeList[T any](data []byte, recvID uint32) (RecvFacilitiesList, []T, error) {
+	header := RecvFacilitiesList{}
+	rest, err := decodeHeader(data, &header, recvID)
+	if err != nil {
+		return header, nil, err
+	}
+
+	size := uint64(binary.Size(new(T)))
+	if uint64(header.ArraySize)*size > uint64(len(rest)) {
+		return header, nil, fmt.Errorf("%w: %d facilities of %d bytes in %d bytes", ErrInvalidSize, header.ArraySize, size, len(rest))
+	}
+
+	facilities := make([]T, header.ArraySize)
+	if err := binary.Read(bytes.NewReader(rest), binary.LittleEndian, facilities); err != nil {
+		return header, nil, fmt.Errorf("decoding facilities error: %v", err)
+	}
+	return header, facilities, nil
+}
+
+// stringV returns the null terminated string at the start of data.
+func stringV(data []byte) (string, error) {
+	end := bytes.IndexByte(data, 0)
+	if end < 0 {
+		return "", fmt.Errorf("%w: string of %d bytes is not terminated", ErrInvalidSize, len(data))
+	}
+	return string(data[:end]), nil
+}
+
+func containsID(recvIDs []uint32, recvID uint32) bool {
+	for _, id := range recvIDs {
+		if id == recvID {
+			return true
+		}
+	}
+	return false
+}
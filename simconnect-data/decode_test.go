@@ -0,0 +1,277 @@
+package simconnect_data
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixture builds a message with recvID from the little endian encoding of fields, setting its size.
+func fixture(t *testing.T, recvID uint32, fields ...interface{}) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	require.NoError(t, binary.Write(buf, binary.LittleEndian, Recv{Version: 4, ID: recvID}))
+	for _, field := range fields {
+		require.NoError(t, binary.Write(buf, binary.LittleEndian, field))
+	}
+
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint32(data, uint32(len(data)))
+	return data
+}
+
+func fixedString(s string, size int) []byte {
+	b := make([]byte, size)
+	copy(b, s)
+	return b
+}
+
+func float64Bytes(f float64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, math.Float64bits(f))
+	return b
+}
+
+func TestDecodeRecvEventFrame(t *testing.T) {
+	data := []byte{
+		0x20, 0x00, 0x00, 0x00, // size
+		0x04, 0x00, 0x00, 0x00, // version
+		0x07, 0x00, 0x00, 0x00, // RECV_ID_EVENT_FRAME
+		0xff, 0xff, 0xff, 0xff, // group ID
+		0x05, 0x00, 0x00, 0x00, // event ID
+		0x00, 0x00, 0x00, 0x00, // data
+		0x00, 0x00, 0xf0, 0x41, // frame rate 30
+		0x00, 0x00, 0x80, 0x3f, // sim speed 1
+	}
+
+	frame, err := DecodeRecvEventFrame(data)
+	require.NoError(t, err)
+	assert.Equal(t, Recv{Size: 32, Version: 4, ID: RECV_ID_EVENT_FRAME}, frame.Recv)
+	assert.Equal(t, UNUSED, frame.GroupID)
+	assert.Equal(t, uint32(5), frame.EventID)
+	assert.Equal(t, float32(30), frame.FrameRate)
+	assert.Equal(t, float32(1), frame.SimSpeed)
+
+	event, err := DecodeRecvEvent(data)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(5), event.EventID)
+}
+
+func TestIsRecvEvent(t *testing.T) {
+	assert.True(t, IsRecvEvent(RECV_ID_EVENT))
+	assert.True(t, IsRecvEvent(RECV_ID_EVENT_FRAME))
+	assert.True(t, IsRecvEvent(RECV_ID_CUSTOM_ACTION))
+	assert.False(t, IsRecvEvent(RECV_ID_SIMOBJECT_DATA))
+	assert.False(t, IsRecvEvent(RECV_ID_QUIT))
+}
+
+func TestDecodeSize(t *testing.T) {
+	data := fixture(t, RECV_ID_EVENT_FRAME, uint32(0), uint32(5), uint32(0), float32(30), float32(1))
+
+	_, err := DecodeRecvEventFrame(data[:8])
+	assert.True(t, errors.Is(err, ErrInvalidSize), err)
+
+	// The size says there is more than was received
+	_, err = DecodeRecvEventFrame(data[:len(data)-1])
+	assert.True(t, errors.Is(err, ErrInvalidSize), err)
+
+	// The size says there is less than the struct holds
+	short := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(short, 28)
+	_, err = DecodeRecvEventFrame(short)
+	assert.True(t, errors.Is(err, ErrInvalidSize), err)
+
+	// Bytes after the size are ignored
+	frame, err := DecodeRecvEventFrame(append(data, 0xff, 0xff))
+	require.NoError(t, err)
+	assert.Equal(t, float32(1), frame.SimSpeed)
+
+	_, err = DecodeRecvEventFilename(data)
+	assert.True(t, errors.Is(err, ErrUnexpectedID), err)
+}
+
+func TestDecodeFixedMessages(t *testing.T) {
+	quit, err := DecodeRecvQuit(fixture(t, RECV_ID_QUIT))
+	require.NoError(t, err)
+	assert.Equal(t, RECV_ID_QUIT, quit.ID)
+
+	added, err := DecodeRecvEventObjectAddRemove(fixture(t, RECV_ID_EVENT_OBJECT_ADDREMOVE, uint32(0), uint32(3), uint32(42), SIMOBJECT_TYPE_BOAT))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(42), added.Data)
+	assert.Equal(t, SIMOBJECT_TYPE_BOAT, added.ObjType)
+
+	filename, err := DecodeRecvEventFilename(fixture(t, RECV_ID_EVENT_FILENAME, uint32(0), uint32(4), uint32(0), fixedString(`C:\flight.FLT`, MAX_PATH), uint32(1)))
+	require.NoError(t, err)
+	assert.Equal(t, fixedString(`C:\flight.FLT`, MAX_PATH), filename.FileName[:])
+	assert.Equal(t, uint32(1), filename.Flags)
+
+	state, err := DecodeRecvSystemState(fixture(t, RECV_ID_SYSTEM_STATE, uint32(9), uint32(1), float32(0.5), fixedString("Sim", MAX_PATH)))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(9), state.RequestID)
+	assert.Equal(t, uint32(1), state.Integer)
+	assert.Equal(t, float32(0.5), state.Float)
+	assert.Equal(t, fixedString("Sim", MAX_PATH), state.String[:])
+
+	key, err := DecodeRecvReservedKey(fixture(t, RECV_ID_RESERVED_KEY, fixedString("Ctrl+Shift+A", 30), fixedString("Ctrl+Shift+A", 50)))
+	require.NoError(t, err)
+	assert.Equal(t, fixedString("Ctrl+Shift+A", 50), key.ReservedKey[:])
+
+	mode, err := DecodeRecvEventWeatherMode(fixture(t, RECV_ID_EVENT_WEATHER_MODE, uint32(0), uint32(2), uint32(0)))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), mode.EventID)
+
+	multiplayer := map[uint32]interface{}{
+		RECV_ID_EVENT_MULTIPLAYER_SERVER_STARTED: &RecvEventMultiplayerServerStarted{},
+		RECV_ID_EVENT_MULTIPLAYER_CLIENT_STARTED: &RecvEventMultiplayerClientStarted{},
+		RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED:  &RecvEventMultiplayerSessionEnded{},
+	}
+	for recvID, expected := range multiplayer {
+		event, err := Decode(fixture(t, recvID, uint32(0), uint32(11), uint32(0)))
+		require.NoError(t, err)
+		assert.IsType(t, expected, event)
+	}
+}
+
+func TestDecodeRace(t *testing.T) {
+	// The double after the strings is not aligned, as SimConnect structs are packed
+	result := [][]byte{
+		{0x02, 0x00, 0x00, 0x00},
+		bytes.Repeat([]byte{0xab}, 16),
+		fixedString("Pilot", MAX_PATH),
+		fixedString("LAN", MAX_PATH),
+		fixedString("Extra 330", MAX_PATH),
+		fixedString("Racer", MAX_PATH),
+		float64Bytes(123.5),
+		float64Bytes(2),
+		{0x01, 0x00, 0x00, 0x00},
+	}
+	fields := []interface{}{uint32(0), uint32(6), uint32(0), uint32(1)}
+	for _, field := range result {
+		fields = append(fields, field)
+	}
+
+	end, err := DecodeRecvEventRaceEnd(fixture(t, RECV_ID_EVENT_RACE_END, fields...))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), end.RacerNumber)
+	assert.Equal(t, uint32(2), end.RacerData.NumberOfRacers)
+	assert.Equal(t, fixedString("Extra 330", MAX_PATH), end.RacerData.Aircraft[:])
+	assert.Equal(t, 123.5, end.RacerData.TotalTime)
+	assert.Equal(t, 2.0, end.RacerData.PenaltyTime)
+	assert.Equal(t, uint32(1), end.RacerData.IsDisqualified)
+
+	lap, err := DecodeRecvEventRaceLap(fixture(t, RECV_ID_EVENT_RACE_LAP, fields...))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), lap.LapIndex)
+	assert.Equal(t, 123.5, lap.RacerData.TotalTime)
+}
+
+func TestDecodeVariableMessages(t *testing.T) {
+	observation, err := DecodeRecvWeatherObservation(fixture(t, RECV_ID_WEATHER_OBSERVATION, uint32(3), []byte("EGLL 121250Z 24010KT\x00\x00\x00")))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), observation.RequestID)
+	assert.Equal(t, "EGLL 121250Z 24010KT", observation.Metar)
+
+	_, err = DecodeRecvWeatherObservation(fixture(t, RECV_ID_WEATHER_OBSERVATION, uint32(3), []byte("EGLL")))
+	assert.True(t, errors.Is(err, ErrInvalidSize), err)
+
+	clouds, err := DecodeRecvCloudState(fixture(t, RECV_ID_CLOUD_STATE, uint32(4), uint32(3), []byte{1, 2, 3, 0}))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, clouds.Data)
+
+	_, err = DecodeRecvCloudState(fixture(t, RECV_ID_CLOUD_STATE, uint32(4), uint32(CLOUD_STATE_ARRAY_SIZE), []byte{1, 2, 3}))
+	assert.True(t, errors.Is(err, ErrInvalidSize), err)
+
+	action, err := DecodeRecvCustomAction(fixture(t, RECV_ID_CUSTOM_ACTION, uint32(0), uint32(8), uint32(0), bytes.Repeat([]byte{1}, 16), uint32(1), []byte("open door\x00")))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(8), action.EventID)
+	assert.Equal(t, uint32(1), action.WaitForCompletion)
+	assert.Equal(t, "open door", action.PayLoad)
+
+	clientData, err := DecodeRecvClientData(fixture(t, RECV_ID_CLIENT_DATA, uint32(5), uint32(0), uint32(2), uint32(0), uint32(1), uint32(1), uint32(1), float64Bytes(7)))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(5), clientData.RequestID)
+	assert.Equal(t, uint32(2), clientData.DefineID)
+	assert.Equal(t, float64Bytes(7), clientData.Data)
+}
+
+func TestDecodeFacilitiesLists(t *testing.T) {
+	airport := [][]byte{fixedString("EGLL", 6), fixedString("", 3), float64Bytes(51.47), float64Bytes(-0.46), float64Bytes(25)}
+	fields := []interface{}{uint32(12), uint32(2), uint32(0), uint32(1)}
+	for i := 0; i < 2; i++ {
+		for _, field := range airport {
+			fields = append(fields, field)
+		}
+	}
+
+	airports, err := DecodeRecvAirportList(fixture(t, RECV_ID_AIRPORT_LIST, fields...))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(12), airports.RequestID)
+	assert.Equal(t, uint32(1), airports.OutOf)
+	require.Len(t, airports.Airports, 2)
+	assert.Equal(t, fixedString("EGLL", 6), airports.Airports[1].Ident[:])
+	assert.Equal(t, 51.47, airports.Airports[1].Latitude)
+	assert.Equal(t, 25.0, airports.Airports[1].Altitude)
+
+	// The array size says there are more facilities than the message holds
+	fields[1] = uint32(3)
+	_, err = DecodeRecvAirportList(fixture(t, RECV_ID_AIRPORT_LIST, fields...))
+	assert.True(t, errors.Is(err, ErrInvalidSize), err)
+
+	magVar := []byte{0x00, 0x00, 0x80, 0xbf}    // -1
+	frequency := []byte{0x10, 0x27, 0x00, 0x00} // 10000 Hz
+	waypoint := append(airport[:5:5], magVar)
+	ndb := append(waypoint[:6:6], frequency)
+	vor := append(ndb[:7:7],
+		[]byte{0x0f, 0x00, 0x00, 0x00}, // flags
+		[]byte{0x00, 0x00, 0x34, 0x43}, // localizer 180
+		float64Bytes(51.5), float64Bytes(-0.5), float64Bytes(30),
+		[]byte{0x00, 0x00, 0x40, 0x40}, // glide slope 3
+	)
+
+	list := func(recvID uint32, facility [][]byte) []byte {
+		fields := []interface{}{uint32(13), uint32(1), uint32(0), uint32(1)}
+		for _, field := range facility {
+			fields = append(fields, field)
+		}
+		return fixture(t, recvID, fields...)
+	}
+
+	waypoints, err := DecodeRecvWaypointList(list(RECV_ID_WAYPOINT_LIST, waypoint))
+	require.NoError(t, err)
+	require.Len(t, waypoints.Waypoints, 1)
+	assert.Equal(t, float32(-1), waypoints.Waypoints[0].MagVar)
+
+	ndbs, err := DecodeRecvNdbList(list(RECV_ID_NDB_LIST, ndb))
+	require.NoError(t, err)
+	require.Len(t, ndbs.Ndbs, 1)
+	assert.Equal(t, uint32(10000), ndbs.Ndbs[0].Frequency)
+
+	vors, err := DecodeRecvVorList(list(RECV_ID_VOR_LIST, vor))
+	require.NoError(t, err)
+	require.Len(t, vors.Vors, 1)
+	assert.Equal(t, RECV_ID_VOR_LIST_HAS_DME|RECV_ID_VOR_LIST_HAS_GLIDE_SLOPE|RECV_ID_VOR_LIST_HAS_LOCALIZER|RECV_ID_VOR_LIST_HAS_NAV_SIGNAL, vors.Vors[0].Flags)
+	assert.Equal(t, float32(180), vors.Vors[0].Localizer)
+	assert.Equal(t, 30.0, vors.Vors[0].GlideAlt)
+	assert.Equal(t, float32(3), vors.Vors[0].GlideSlopeAngle)
+	assert.Equal(t, uint32(10000), vors.Vors[0].Frequency)
+	assert.Equal(t, fixedString("EGLL", 6), vors.Vors[0].Ident[:])
+}
+
+func TestDecode(t *testing.T) {
+	message, err := Decode(fixture(t, RECV_ID_EVENT_FRAME, uint32(0), uint32(5), uint32(0), float32(30), float32(1)))
+	require.NoError(t, err)
+	require.IsType(t, &RecvEventFrame{}, message)
+	assert.Equal(t, float32(30), message.(*RecvEventFrame).FrameRate)
+
+	message, err = Decode(fixture(t, RECV_ID_NULL))
+	require.NoError(t, err)
+	assert.Equal(t, &Recv{Size: 12, Version: 4, ID: RECV_ID_NULL}, message)
+
+	_, err = Decode(fixture(t, RECV_ID_ASSIGNED_OBJECT_ID, uint32(1)))
+	assert.True(t, errors.Is(err, ErrInvalidSize), err)
+}
@@ -0,0 +1,919 @@
+// Code generated by sc-headergen from SimConnect.h. DO NOT EDIT.
+
+package simconnect_data
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	CAMERA_IGNORE_FIELD float32 = math.MaxFloat32 // Used to tell the Camera API to NOT modify the value in this part of the argument.
+	CLIENTDATA_MAX_SIZE uint32  = 8192            // maximum value for SimConnect_CreateClientData dwSize parameter
+)
+
+// Notification Group priority values
+const (
+	GROUP_PRIORITY_HIGHEST          uint32 = 1          // highest priority
+	GROUP_PRIORITY_HIGHEST_MASKABLE uint32 = 10000000   // highest priority that allows events to be masked
+	GROUP_PRIORITY_STANDARD         uint32 = 1900000000 // standard priority
+	GROUP_PRIORITY_DEFAULT          uint32 = 2000000000 // default priority
+	GROUP_PRIORITY_LOWEST           uint32 = 4000000000 // priorities lower than this will be ignored
+)
+
+// Weather observations Metar strings
+const (
+	MAX_METAR_LENGTH uint32 = 2000
+)
+
+// Maximum thermal size is 100 km.
+const (
+	MAX_THERMAL_SIZE float32 = 100000
+	MAX_THERMAL_RATE float32 = 1000
+)
+
+// SIMCONNECT_DATA_INITPOSITION.Airspeed
+const (
+	INITPOSITION_AIRSPEED_CRUISE uint32 = 0xffffffff // aircraft's cruise airspeed
+	INITPOSITION_AIRSPEED_KEEP   uint32 = 0xfffffffe // keep current airspeed
+)
+
+// AddToClientDataDefinition dwSizeOrType parameter type values
+const (
+	CLIENTDATATYPE_INT8    uint32 = 0xffffffff // 8-bit integer number
+	CLIENTDATATYPE_INT16   uint32 = 0xfffffffe // 16-bit integer number
+	CLIENTDATATYPE_INT32   uint32 = 0xfffffffd // 32-bit integer number
+	CLIENTDATATYPE_INT64   uint32 = 0xfffffffc // 64-bit integer number
+	CLIENTDATATYPE_FLOAT32 uint32 = 0xfffffffb // 32-bit floating-point number (float)
+	CLIENTDATATYPE_FLOAT64 uint32 = 0xfffffffa // 64-bit floating-point number (double)
+)
+
+// AddToClientDataDefinition dwOffset parameter special values
+const (
+	CLIENTDATAOFFSET_AUTO uint32 = 0xffffffff // automatically compute offset of the ClientData variable
+)
+
+// Open ConfigIndex parameter special value
+const (
+	OPEN_CONFIGINDEX_LOCAL uint32 = 0xffffffff // ignore SimConnect.cfg settings, and force local connection
+)
+
+// SIMCONNECT_RECV_ID values
+const (
+	RECV_ID_EVENT_EX1                    uint32 = 28
+	RECV_ID_FACILITY_DATA                uint32 = 29
+	RECV_ID_FACILITY_DATA_END            uint32 = 30
+	RECV_ID_FACILITY_MINIMAL_LIST        uint32 = 31
+	RECV_ID_JETWAY_DATA                  uint32 = 32
+	RECV_ID_CONTROLLERS_LIST             uint32 = 33
+	RECV_ID_ACTION_CALLBACK              uint32 = 34
+	RECV_ID_ENUMERATE_INPUT_EVENTS       uint32 = 35
+	RECV_ID_GET_INPUT_EVENT              uint32 = 36
+	RECV_ID_SUBSCRIBE_INPUT_EVENT        uint32 = 37
+	RECV_ID_ENUMERATE_INPUT_EVENT_PARAMS uint32 = 38
+)
+
+// RecvID is SIMCONNECT_RECV_ID, giving its uint32 constants a String method.
+type RecvID uint32
+
+// String returns the name of the constant with the value of v.
+func (v RecvID) String() string {
+	switch uint32(v) {
+	case RECV_ID_NULL:
+		return "RECV_ID_NULL"
+	case RECV_ID_EXCEPTION:
+		return "RECV_ID_EXCEPTION"
+	case RECV_ID_OPEN:
+		return "RECV_ID_OPEN"
+	case RECV_ID_QUIT:
+		return "RECV_ID_QUIT"
+	case RECV_ID_EVENT:
+		return "RECV_ID_EVENT"
+	case RECV_ID_EVENT_OBJECT_ADDREMOVE:
+		return "RECV_ID_EVENT_OBJECT_ADDREMOVE"
+	case RECV_ID_EVENT_FILENAME:
+		return "RECV_ID_EVENT_FILENAME"
+	case RECV_ID_EVENT_FRAME:
+		return "RECV_ID_EVENT_FRAME"
+	case RECV_ID_SIMOBJECT_DATA:
+		return "RECV_ID_SIMOBJECT_DATA"
+	case RECV_ID_SIMOBJECT_DATA_BYTYPE:
+		return "RECV_ID_SIMOBJECT_DATA_BYTYPE"
+	case RECV_ID_WEATHER_OBSERVATION:
+		return "RECV_ID_WEATHER_OBSERVATION"
+	case RECV_ID_CLOUD_STATE:
+		return "RECV_ID_CLOUD_STATE"
+	case RECV_ID_ASSIGNED_OBJECT_ID:
+		return "RECV_ID_ASSIGNED_OBJECT_ID"
+	case RECV_ID_RESERVED_KEY:
+		return "RECV_ID_RESERVED_KEY"
+	case RECV_ID_CUSTOM_ACTION:
+		return "RECV_ID_CUSTOM_ACTION"
+	case RECV_ID_SYSTEM_STATE:
+		return "RECV_ID_SYSTEM_STATE"
+	case RECV_ID_CLIENT_DATA:
+		return "RECV_ID_CLIENT_DATA"
+	case RECV_ID_EVENT_WEATHER_MODE:
+		return "RECV_ID_EVENT_WEATHER_MODE"
+	case RECV_ID_AIRPORT_LIST:
+		return "RECV_ID_AIRPORT_LIST"
+	case RECV_ID_VOR_LIST:
+		return "RECV_ID_VOR_LIST"
+	case RECV_ID_NDB_LIST:
+		return "RECV_ID_NDB_LIST"
+	case RECV_ID_WAYPOINT_LIST:
+		return "RECV_ID_WAYPOINT_LIST"
+	case RECV_ID_EVENT_MULTIPLAYER_SERVER_STARTED:
+		return "RECV_ID_EVENT_MULTIPLAYER_SERVER_STARTED"
+	case RECV_ID_EVENT_MULTIPLAYER_CLIENT_STARTED:
+		return "RECV_ID_EVENT_MULTIPLAYER_CLIENT_STARTED"
+	case RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED:
+		return "RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED"
+	case RECV_ID_EVENT_RACE_END:
+		return "RECV_ID_EVENT_RACE_END"
+	case RECV_ID_EVENT_RACE_LAP:
+		return "RECV_ID_EVENT_RACE_LAP"
+	case RECV_ID_PICK:
+		return "RECV_ID_PICK"
+	case RECV_ID_EVENT_EX1:
+		return "RECV_ID_EVENT_EX1"
+	case RECV_ID_FACILITY_DATA:
+		return "RECV_ID_FACILITY_DATA"
+	case RECV_ID_FACILITY_DATA_END:
+		return "RECV_ID_FACILITY_DATA_END"
+	case RECV_ID_FACILITY_MINIMAL_LIST:
+		return "RECV_ID_FACILITY_MINIMAL_LIST"
+	case RECV_ID_JETWAY_DATA:
+		return "RECV_ID_JETWAY_DATA"
+	case RECV_ID_CONTROLLERS_LIST:
+		return "RECV_ID_CONTROLLERS_LIST"
+	case RECV_ID_ACTION_CALLBACK:
+		return "RECV_ID_ACTION_CALLBACK"
+	case RECV_ID_ENUMERATE_INPUT_EVENTS:
+		return "RECV_ID_ENUMERATE_INPUT_EVENTS"
+	case RECV_ID_GET_INPUT_EVENT:
+		return "RECV_ID_GET_INPUT_EVENT"
+	case RECV_ID_SUBSCRIBE_INPUT_EVENT:
+		return "RECV_ID_SUBSCRIBE_INPUT_EVENT"
+	case RECV_ID_ENUMERATE_INPUT_EVENT_PARAMS:
+		return "RECV_ID_ENUMERATE_INPUT_EVENT_PARAMS"
+	}
+	return fmt.Sprintf("RecvID(%d)", uint32(v))
+}
+
+// DataType is SIMCONNECT_DATATYPE, giving its uint32 constants a String method.
+type DataType uint32
+
+// String returns the name of the constant with the value of v.
+func (v DataType) String() string {
+	switch uint32(v) {
+	case DATATYPE_INVALID:
+		return "DATATYPE_INVALID"
+	case DATATYPE_INT32:
+		return "DATATYPE_INT32"
+	case DATATYPE_INT64:
+		return "DATATYPE_INT64"
+	case DATATYPE_FLOAT32:
+		return "DATATYPE_FLOAT32"
+	case DATATYPE_FLOAT64:
+		return "DATATYPE_FLOAT64"
+	case DATATYPE_STRING8:
+		return "DATATYPE_STRING8"
+	case DATATYPE_STRING32:
+		return "DATATYPE_STRING32"
+	case DATATYPE_STRING64:
+		return "DATATYPE_STRING64"
+	case DATATYPE_STRING128:
+		return "DATATYPE_STRING128"
+	case DATATYPE_STRING256:
+		return "DATATYPE_STRING256"
+	case DATATYPE_STRING260:
+		return "DATATYPE_STRING260"
+	case DATATYPE_STRINGV:
+		return "DATATYPE_STRINGV"
+	case DATATYPE_INITPOSITION:
+		return "DATATYPE_INITPOSITION"
+	case DATATYPE_MARKERSTATE:
+		return "DATATYPE_MARKERSTATE"
+	case DATATYPE_WAYPOINT:
+		return "DATATYPE_WAYPOINT"
+	case DATATYPE_LATLONALT:
+		return "DATATYPE_LATLONALT"
+	case DATATYPE_XYZ:
+		return "DATATYPE_XYZ"
+	case DATATYPE_MAX:
+		return "DATATYPE_MAX"
+	}
+	return fmt.Sprintf("DataType(%d)", uint32(v))
+}
+
+// Exception is SIMCONNECT_EXCEPTION, giving its uint32 constants a String method.
+type Exception uint32
+
+// String returns the name of the constant with the value of v.
+func (v Exception) String() string {
+	switch uint32(v) {
+	case EXCEPTION_NONE:
+		return "EXCEPTION_NONE"
+	case EXCEPTION_ERROR:
+		return "EXCEPTION_ERROR"
+	case EXCEPTION_SIZE_MISMATCH:
+		return "EXCEPTION_SIZE_MISMATCH"
+	case EXCEPTION_UNRECOGNIZED_ID:
+		return "EXCEPTION_UNRECOGNIZED_ID"
+	case EXCEPTION_UNOPENED:
+		return "EXCEPTION_UNOPENED"
+	case EXCEPTION_VERSION_MISMATCH:
+		return "EXCEPTION_VERSION_MISMATCH"
+	case EXCEPTION_TOO_MANY_GROUPS:
+		return "EXCEPTION_TOO_MANY_GROUPS"
+	case EXCEPTION_NAME_UNRECOGNIZED:
+		return "EXCEPTION_NAME_UNRECOGNIZED"
+	case EXCEPTION_TOO_MANY_EVENT_NAMES:
+		return "EXCEPTION_TOO_MANY_EVENT_NAMES"
+	case EXCEPTION_EVENT_ID_DUPLICATE:
+		return "EXCEPTION_EVENT_ID_DUPLICATE"
+	case EXCEPTION_TOO_MANY_MAPS:
+		return "EXCEPTION_TOO_MANY_MAPS"
+	case EXCEPTION_TOO_MANY_OBJECTS:
+		return "EXCEPTION_TOO_MANY_OBJECTS"
+	case EXCEPTION_TOO_MANY_REQUESTS:
+		return "EXCEPTION_TOO_MANY_REQUESTS"
+	case EXCEPTION_WEATHER_INVALID_PORT:
+		return "EXCEPTION_WEATHER_INVALID_PORT"
+	case EXCEPTION_WEATHER_INVALID_METAR:
+		return "EXCEPTION_WEATHER_INVALID_METAR"
+	case EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION:
+		return "EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION"
+	case EXCEPTION_WEATHER_UNABLE_TO_CREATE_STATION:
+		return "EXCEPTION_WEATHER_UNABLE_TO_CREATE_STATION"
+	case EXCEPTION_WEATHER_UNABLE_TO_REMOVE_STATION:
+		return "EXCEPTION_WEATHER_UNABLE_TO_REMOVE_STATION"
+	case EXCEPTION_INVALID_DATA_TYPE:
+		return "EXCEPTION_INVALID_DATA_TYPE"
+	case EXCEPTION_INVALID_DATA_SIZE:
+		return "EXCEPTION_INVALID_DATA_SIZE"
+	case EXCEPTION_DATA_ERROR:
+		return "EXCEPTION_DATA_ERROR"
+	case EXCEPTION_INVALID_ARRAY:
+		return "EXCEPTION_INVALID_ARRAY"
+	case EXCEPTION_CREATE_OBJECT_FAILED:
+		return "EXCEPTION_CREATE_OBJECT_FAILED"
+	case EXCEPTION_LOAD_FLIGHTPLAN_FAILED:
+		return "EXCEPTION_LOAD_FLIGHTPLAN_FAILED"
+	case EXCEPTION_OPERATION_INVALID_FOR_OBJECT_TYPE:
+		return "EXCEPTION_OPERATION_INVALID_FOR_OBJECT_TYPE"
+	case EXCEPTION_ILLEGAL_OPERATION:
+		return "EXCEPTION_ILLEGAL_OPERATION"
+	case EXCEPTION_ALREADY_SUBSCRIBED:
+		return "EXCEPTION_ALREADY_SUBSCRIBED"
+	case EXCEPTION_INVALID_ENUM:
+		return "EXCEPTION_INVALID_ENUM"
+	case EXCEPTION_DEFINITION_ERROR:
+		return "EXCEPTION_DEFINITION_ERROR"
+	case EXCEPTION_DUPLICATE_ID:
+		return "EXCEPTION_DUPLICATE_ID"
+	case EXCEPTION_DATUM_ID:
+		return "EXCEPTION_DATUM_ID"
+	case EXCEPTION_OUT_OF_BOUNDS:
+		return "EXCEPTION_OUT_OF_BOUNDS"
+	case EXCEPTION_ALREADY_CREATED:
+		return "EXCEPTION_ALREADY_CREATED"
+	case EXCEPTION_OBJECT_OUTSIDE_REALITY_BUBBLE:
+		return "EXCEPTION_OBJECT_OUTSIDE_REALITY_BUBBLE"
+	case EXCEPTION_OBJECT_CONTAINER:
+		return "EXCEPTION_OBJECT_CONTAINER"
+	case EXCEPTION_OBJECT_AI:
+		return "EXCEPTION_OBJECT_AI"
+	case EXCEPTION_OBJECT_ATC:
+		return "EXCEPTION_OBJECT_ATC"
+	case EXCEPTION_OBJECT_SCHEDULE:
+		return "EXCEPTION_OBJECT_SCHEDULE"
+	case EXCEPTION_JETWAY_DATA:
+		return "EXCEPTION_JETWAY_DATA"
+	case EXCEPTION_ACTION_NOT_FOUND:
+		return "EXCEPTION_ACTION_NOT_FOUND"
+	case EXCEPTION_NOT_AN_ACTION:
+		return "EXCEPTION_NOT_AN_ACTION"
+	case EXCEPTION_INCORRECT_ACTION_PARAMS:
+		return "EXCEPTION_INCORRECT_ACTION_PARAMS"
+	case EXCEPTION_GET_INPUT_EVENT_FAILED:
+		return "EXCEPTION_GET_INPUT_EVENT_FAILED"
+	case EXCEPTION_SET_INPUT_EVENT_FAILED:
+		return "EXCEPTION_SET_INPUT_EVENT_FAILED"
+	}
+	return fmt.Sprintf("Exception(%d)", uint32(v))
+}
+
+// SimobjectType is SIMCONNECT_SIMOBJECT_TYPE, giving its uint32 constants a String method.
+type SimobjectType uint32
+
+// String returns the name of the constant with the value of v.
+func (v SimobjectType) String() string {
+	switch uint32(v) {
+	case SIMOBJECT_TYPE_USER:
+		return "SIMOBJECT_TYPE_USER"
+	case SIMOBJECT_TYPE_ALL:
+		return "SIMOBJECT_TYPE_ALL"
+	case SIMOBJECT_TYPE_AIRCRAFT:
+		return "SIMOBJECT_TYPE_AIRCRAFT"
+	case SIMOBJECT_TYPE_HELICOPTER:
+		return "SIMOBJECT_TYPE_HELICOPTER"
+	case SIMOBJECT_TYPE_BOAT:
+		return "SIMOBJECT_TYPE_BOAT"
+	case SIMOBJECT_TYPE_GROUND:
+		return "SIMOBJECT_TYPE_GROUND"
+	}
+	return fmt.Sprintf("SimobjectType(%d)", uint32(v))
+}
+
+// State is SIMCONNECT_STATE, giving its uint32 constants a String method.
+type State uint32
+
+// String returns the name of the constant with the value of v.
+func (v State) String() string {
+	switch uint32(v) {
+	case SIMCONNECT_STATE_OFF:
+		return "SIMCONNECT_STATE_OFF"
+	case SIMCONNECT_STATE_ON:
+		return "SIMCONNECT_STATE_ON"
+	}
+	return fmt.Sprintf("State(%d)", uint32(v))
+}
+
+// Period is SIMCONNECT_PERIOD, giving its uint32 constants a String method.
+type Period uint32
+
+// String returns the name of the constant with the value of v.
+func (v Period) String() string {
+	switch uint32(v) {
+	case SIMCONNECT_PERIOD_NEVER:
+		return "SIMCONNECT_PERIOD_NEVER"
+	case SIMCONNECT_PERIOD_ONCE:
+		return "SIMCONNECT_PERIOD_ONCE"
+	case SIMCONNECT_PERIOD_VISUAL_FRAME:
+		return "SIMCONNECT_PERIOD_VISUAL_FRAME"
+	case SIMCONNECT_PERIOD_SIM_FRAME:
+		return "SIMCONNECT_PERIOD_SIM_FRAME"
+	case SIMCONNECT_PERIOD_SECOND:
+		return "SIMCONNECT_PERIOD_SECOND"
+	}
+	return fmt.Sprintf("Period(%d)", uint32(v))
+}
+
+// SIMCONNECT_MISSION_END values
+const (
+	MISSION_FAILED    uint32 = 0
+	MISSION_CRASHED   uint32 = 1
+	MISSION_SUCCEEDED uint32 = 2
+)
+
+// MissionEnd is SIMCONNECT_MISSION_END, giving its uint32 constants a String method.
+type MissionEnd uint32
+
+// String returns the name of the constant with the value of v.
+func (v MissionEnd) String() string {
+	switch uint32(v) {
+	case MISSION_FAILED:
+		return "MISSION_FAILED"
+	case MISSION_CRASHED:
+		return "MISSION_CRASHED"
+	case MISSION_SUCCEEDED:
+		return "MISSION_SUCCEEDED"
+	}
+	return fmt.Sprintf("MissionEnd(%d)", uint32(v))
+}
+
+// SIMCONNECT_CLIENT_DATA_PERIOD values
+const (
+	CLIENT_DATA_PERIOD_NEVER        uint32 = 0
+	CLIENT_DATA_PERIOD_ONCE         uint32 = 1
+	CLIENT_DATA_PERIOD_VISUAL_FRAME uint32 = 2
+	CLIENT_DATA_PERIOD_ON_SET       uint32 = 3
+	CLIENT_DATA_PERIOD_SECOND       uint32 = 4
+)
+
+// ClientDataPeriod is SIMCONNECT_CLIENT_DATA_PERIOD, giving its uint32 constants a String method.
+type ClientDataPeriod uint32
+
+// String returns the name of the constant with the value of v.
+func (v ClientDataPeriod) String() string {
+	switch uint32(v) {
+	case CLIENT_DATA_PERIOD_NEVER:
+		return "CLIENT_DATA_PERIOD_NEVER"
+	case CLIENT_DATA_PERIOD_ONCE:
+		return "CLIENT_DATA_PERIOD_ONCE"
+	case CLIENT_DATA_PERIOD_VISUAL_FRAME:
+		return "CLIENT_DATA_PERIOD_VISUAL_FRAME"
+	case CLIENT_DATA_PERIOD_ON_SET:
+		return "CLIENT_DATA_PERIOD_ON_SET"
+	case CLIENT_DATA_PERIOD_SECOND:
+		return "CLIENT_DATA_PERIOD_SECOND"
+	}
+	return fmt.Sprintf("ClientDataPeriod(%d)", uint32(v))
+}
+
+// SIMCONNECT_TEXT_TYPE values
+const (
+	TEXT_TYPE_SCROLL_BLACK   uint32 = 0
+	TEXT_TYPE_SCROLL_WHITE   uint32 = 1
+	TEXT_TYPE_SCROLL_RED     uint32 = 2
+	TEXT_TYPE_SCROLL_GREEN   uint32 = 3
+	TEXT_TYPE_SCROLL_BLUE    uint32 = 4
+	TEXT_TYPE_SCROLL_YELLOW  uint32 = 5
+	TEXT_TYPE_SCROLL_MAGENTA uint32 = 6
+	TEXT_TYPE_SCROLL_CYAN    uint32 = 7
+	TEXT_TYPE_PRINT_BLACK    uint32 = 0x0100
+	TEXT_TYPE_PRINT_WHITE    uint32 = 257
+	TEXT_TYPE_PRINT_RED      uint32 = 258
+	TEXT_TYPE_PRINT_GREEN    uint32 = 259
+	TEXT_TYPE_PRINT_BLUE     uint32 = 260
+	TEXT_TYPE_PRINT_YELLOW   uint32 = 261
+	TEXT_TYPE_PRINT_MAGENTA  uint32 = 262
+	TEXT_TYPE_PRINT_CYAN     uint32 = 263
+	TEXT_TYPE_MENU           uint32 = 0x0200
+)
+
+// TextType is SIMCONNECT_TEXT_TYPE, giving its uint32 constants a String method.
+type TextType uint32
+
+// String returns the name of the constant with the value of v.
+func (v TextType) String() string {
+	switch uint32(v) {
+	case TEXT_TYPE_SCROLL_BLACK:
+		return "TEXT_TYPE_SCROLL_BLACK"
+	case TEXT_TYPE_SCROLL_WHITE:
+		return "TEXT_TYPE_SCROLL_WHITE"
+	case TEXT_TYPE_SCROLL_RED:
+		return "TEXT_TYPE_SCROLL_RED"
+	case TEXT_TYPE_SCROLL_GREEN:
+		return "TEXT_TYPE_SCROLL_GREEN"
+	case TEXT_TYPE_SCROLL_BLUE:
+		return "TEXT_TYPE_SCROLL_BLUE"
+	case TEXT_TYPE_SCROLL_YELLOW:
+		return "TEXT_TYPE_SCROLL_YELLOW"
+	case TEXT_TYPE_SCROLL_MAGENTA:
+		return "TEXT_TYPE_SCROLL_MAGENTA"
+	case TEXT_TYPE_SCROLL_CYAN:
+		return "TEXT_TYPE_SCROLL_CYAN"
+	case TEXT_TYPE_PRINT_BLACK:
+		return "TEXT_TYPE_PRINT_BLACK"
+	case TEXT_TYPE_PRINT_WHITE:
+		return "TEXT_TYPE_PRINT_WHITE"
+	case TEXT_TYPE_PRINT_RED:
+		return "TEXT_TYPE_PRINT_RED"
+	case TEXT_TYPE_PRINT_GREEN:
+		return "TEXT_TYPE_PRINT_GREEN"
+	case TEXT_TYPE_PRINT_BLUE:
+		return "TEXT_TYPE_PRINT_BLUE"
+	case TEXT_TYPE_PRINT_YELLOW:
+		return "TEXT_TYPE_PRINT_YELLOW"
+	case TEXT_TYPE_PRINT_MAGENTA:
+		return "TEXT_TYPE_PRINT_MAGENTA"
+	case TEXT_TYPE_PRINT_CYAN:
+		return "TEXT_TYPE_PRINT_CYAN"
+	case TEXT_TYPE_MENU:
+		return "TEXT_TYPE_MENU"
+	}
+	return fmt.Sprintf("TextType(%d)", uint32(v))
+}
+
+// SIMCONNECT_TEXT_RESULT values
+const (
+	TEXT_RESULT_MENU_SELECT_1  uint32 = 0
+	TEXT_RESULT_MENU_SELECT_2  uint32 = 1
+	TEXT_RESULT_MENU_SELECT_3  uint32 = 2
+	TEXT_RESULT_MENU_SELECT_4  uint32 = 3
+	TEXT_RESULT_MENU_SELECT_5  uint32 = 4
+	TEXT_RESULT_MENU_SELECT_6  uint32 = 5
+	TEXT_RESULT_MENU_SELECT_7  uint32 = 6
+	TEXT_RESULT_MENU_SELECT_8  uint32 = 7
+	TEXT_RESULT_MENU_SELECT_9  uint32 = 8
+	TEXT_RESULT_MENU_SELECT_10 uint32 = 9
+	TEXT_RESULT_DISPLAYED      uint32 = 0x00010000
+	TEXT_RESULT_QUEUED         uint32 = 65537
+	TEXT_RESULT_REMOVED        uint32 = 65538
+	TEXT_RESULT_REPLACED       uint32 = 65539
+	TEXT_RESULT_TIMEOUT        uint32 = 65540
+)
+
+// TextResult is SIMCONNECT_TEXT_RESULT, giving its uint32 constants a String method.
+type TextResult uint32
+
+// String returns the name of the constant with the value of v.
+func (v TextResult) String() string {
+	switch uint32(v) {
+	case TEXT_RESULT_MENU_SELECT_1:
+		return "TEXT_RESULT_MENU_SELECT_1"
+	case TEXT_RESULT_MENU_SELECT_2:
+		return "TEXT_RESULT_MENU_SELECT_2"
+	case TEXT_RESULT_MENU_SELECT_3:
+		return "TEXT_RESULT_MENU_SELECT_3"
+	case TEXT_RESULT_MENU_SELECT_4:
+		return "TEXT_RESULT_MENU_SELECT_4"
+	case TEXT_RESULT_MENU_SELECT_5:
+		return "TEXT_RESULT_MENU_SELECT_5"
+	case TEXT_RESULT_MENU_SELECT_6:
+		return "TEXT_RESULT_MENU_SELECT_6"
+	case TEXT_RESULT_MENU_SELECT_7:
+		return "TEXT_RESULT_MENU_SELECT_7"
+	case TEXT_RESULT_MENU_SELECT_8:
+		return "TEXT_RESULT_MENU_SELECT_8"
+	case TEXT_RESULT_MENU_SELECT_9:
+		return "TEXT_RESULT_MENU_SELECT_9"
+	case TEXT_RESULT_MENU_SELECT_10:
+		return "TEXT_RESULT_MENU_SELECT_10"
+	case TEXT_RESULT_DISPLAYED:
+		return "TEXT_RESULT_DISPLAYED"
+	case TEXT_RESULT_QUEUED:
+		return "TEXT_RESULT_QUEUED"
+	case TEXT_RESULT_REMOVED:
+		return "TEXT_RESULT_REMOVED"
+	case TEXT_RESULT_REPLACED:
+		return "TEXT_RESULT_REPLACED"
+	case TEXT_RESULT_TIMEOUT:
+		return "TEXT_RESULT_TIMEOUT"
+	}
+	return fmt.Sprintf("TextResult(%d)", uint32(v))
+}
+
+// SIMCONNECT_WEATHER_MODE values
+const (
+	WEATHER_MODE_THEME  uint32 = 0
+	WEATHER_MODE_RWW    uint32 = 1
+	WEATHER_MODE_CUSTOM uint32 = 2
+	WEATHER_MODE_GLOBAL uint32 = 3
+)
+
+// WeatherMode is SIMCONNECT_WEATHER_MODE, giving its uint32 constants a String method.
+type WeatherMode uint32
+
+// String returns the name of the constant with the value of v.
+func (v WeatherMode) String() string {
+	switch uint32(v) {
+	case WEATHER_MODE_THEME:
+		return "WEATHER_MODE_THEME"
+	case WEATHER_MODE_RWW:
+		return "WEATHER_MODE_RWW"
+	case WEATHER_MODE_CUSTOM:
+		return "WEATHER_MODE_CUSTOM"
+	case WEATHER_MODE_GLOBAL:
+		return "WEATHER_MODE_GLOBAL"
+	}
+	return fmt.Sprintf("WeatherMode(%d)", uint32(v))
+}
+
+// SIMCONNECT_FACILITY_LIST_TYPE values
+const (
+	FACILITY_LIST_TYPE_AIRPORT  uint32 = 0
+	FACILITY_LIST_TYPE_WAYPOINT uint32 = 1
+	FACILITY_LIST_TYPE_NDB      uint32 = 2
+	FACILITY_LIST_TYPE_VOR      uint32 = 3
+	FACILITY_LIST_TYPE_COUNT    uint32 = 4 // invalid
+)
+
+// FacilityListType is SIMCONNECT_FACILITY_LIST_TYPE, giving its uint32 constants a String method.
+type FacilityListType uint32
+
+// String returns the name of the constant with the value of v.
+func (v FacilityListType) String() string {
+	switch uint32(v) {
+	case FACILITY_LIST_TYPE_AIRPORT:
+		return "FACILITY_LIST_TYPE_AIRPORT"
+	case FACILITY_LIST_TYPE_WAYPOINT:
+		return "FACILITY_LIST_TYPE_WAYPOINT"
+	case FACILITY_LIST_TYPE_NDB:
+		return "FACILITY_LIST_TYPE_NDB"
+	case FACILITY_LIST_TYPE_VOR:
+		return "FACILITY_LIST_TYPE_VOR"
+	case FACILITY_LIST_TYPE_COUNT:
+		return "FACILITY_LIST_TYPE_COUNT"
+	}
+	return fmt.Sprintf("FacilityListType(%d)", uint32(v))
+}
+
+// SIMCONNECT_FACILITY_DATA_TYPE values
+const (
+	FACILITY_DATA_AIRPORT             uint32 = 0
+	FACILITY_DATA_RUNWAY              uint32 = 1
+	FACILITY_DATA_START               uint32 = 2
+	FACILITY_DATA_FREQUENCY           uint32 = 3
+	FACILITY_DATA_HELIPAD             uint32 = 4
+	FACILITY_DATA_APPROACH            uint32 = 5
+	FACILITY_DATA_APPROACH_TRANSITION uint32 = 6
+	FACILITY_DATA_APPROACH_LEG        uint32 = 7
+	FACILITY_DATA_FINAL_APPROACH_LEG  uint32 = 8
+	FACILITY_DATA_MISSED_APPROACH_LEG uint32 = 9
+	FACILITY_DATA_DEPARTURE           uint32 = 10
+	FACILITY_DATA_ARRIVAL             uint32 = 11
+	FACILITY_DATA_RUNWAY_TRANSITION   uint32 = 12
+	FACILITY_DATA_ENROUTE_TRANSITION  uint32 = 13
+	FACILITY_DATA_TAXI_POINT          uint32 = 14
+	FACILITY_DATA_TAXI_PARKING        uint32 = 15
+	FACILITY_DATA_TAXI_PATH           uint32 = 16
+	FACILITY_DATA_TAXI_NAME           uint32 = 17
+	FACILITY_DATA_JETWAY              uint32 = 18
+	FACILITY_DATA_VOR                 uint32 = 19
+	FACILITY_DATA_NDB                 uint32 = 20
+	FACILITY_DATA_WAYPOINT            uint32 = 21
+	FACILITY_DATA_ROUTE               uint32 = 22
+	FACILITY_DATA_PAVEMENT            uint32 = 23
+	FACILITY_DATA_APPROACH_LIGHTS     uint32 = 24
+	FACILITY_DATA_VASI                uint32 = 25
+)
+
+// FacilityDataType is SIMCONNECT_FACILITY_DATA_TYPE, giving its uint32 constants a String method.
+type FacilityDataType uint32
+
+// String returns the name of the constant with the value of v.
+func (v FacilityDataType) String() string {
+	switch uint32(v) {
+	case FACILITY_DATA_AIRPORT:
+		return "FACILITY_DATA_AIRPORT"
+	case FACILITY_DATA_RUNWAY:
+		return "FACILITY_DATA_RUNWAY"
+	case FACILITY_DATA_START:
+		return "FACILITY_DATA_START"
+	case FACILITY_DATA_FREQUENCY:
+		return "FACILITY_DATA_FREQUENCY"
+	case FACILITY_DATA_HELIPAD:
+		return "FACILITY_DATA_HELIPAD"
+	case FACILITY_DATA_APPROACH:
+		return "FACILITY_DATA_APPROACH"
+	case FACILITY_DATA_APPROACH_TRANSITION:
+		return "FACILITY_DATA_APPROACH_TRANSITION"
+	case FACILITY_DATA_APPROACH_LEG:
+		return "FACILITY_DATA_APPROACH_LEG"
+	case FACILITY_DATA_FINAL_APPROACH_LEG:
+		return "FACILITY_DATA_FINAL_APPROACH_LEG"
+	case FACILITY_DATA_MISSED_APPROACH_LEG:
+		return "FACILITY_DATA_MISSED_APPROACH_LEG"
+	case FACILITY_DATA_DEPARTURE:
+		return "FACILITY_DATA_DEPARTURE"
+	case FACILITY_DATA_ARRIVAL:
+		return "FACILITY_DATA_ARRIVAL"
+	case FACILITY_DATA_RUNWAY_TRANSITION:
+		return "FACILITY_DATA_RUNWAY_TRANSITION"
+	case FACILITY_DATA_ENROUTE_TRANSITION:
+		return "FACILITY_DATA_ENROUTE_TRANSITION"
+	case FACILITY_DATA_TAXI_POINT:
+		return "FACILITY_DATA_TAXI_POINT"
+	case FACILITY_DATA_TAXI_PARKING:
+		return "FACILITY_DATA_TAXI_PARKING"
+	case FACILITY_DATA_TAXI_PATH:
+		return "FACILITY_DATA_TAXI_PATH"
+	case FACILITY_DATA_TAXI_NAME:
+		return "FACILITY_DATA_TAXI_NAME"
+	case FACILITY_DATA_JETWAY:
+		return "FACILITY_DATA_JETWAY"
+	case FACILITY_DATA_VOR:
+		return "FACILITY_DATA_VOR"
+	case FACILITY_DATA_NDB:
+		return "FACILITY_DATA_NDB"
+	case FACILITY_DATA_WAYPOINT:
+		return "FACILITY_DATA_WAYPOINT"
+	case FACILITY_DATA_ROUTE:
+		return "FACILITY_DATA_ROUTE"
+	case FACILITY_DATA_PAVEMENT:
+		return "FACILITY_DATA_PAVEMENT"
+	case FACILITY_DATA_APPROACH_LIGHTS:
+		return "FACILITY_DATA_APPROACH_LIGHTS"
+	case FACILITY_DATA_VASI:
+		return "FACILITY_DATA_VASI"
+	}
+	return fmt.Sprintf("FacilityDataType(%d)", uint32(v))
+}
+
+// VorFlags is SIMCONNECT_VOR_FLAGS, giving its uint32 constants a String method.
+type VorFlags uint32
+
+// String returns the names of the flags set in v, joined by |.
+func (v VorFlags) String() string {
+	return flagsString(uint32(v), []flagName{
+		{RECV_ID_VOR_LIST_HAS_NAV_SIGNAL, "RECV_ID_VOR_LIST_HAS_NAV_SIGNAL"},
+		{RECV_ID_VOR_LIST_HAS_LOCALIZER, "RECV_ID_VOR_LIST_HAS_LOCALIZER"},
+		{RECV_ID_VOR_LIST_HAS_GLIDE_SLOPE, "RECV_ID_VOR_LIST_HAS_GLIDE_SLOPE"},
+		{RECV_ID_VOR_LIST_HAS_DME, "RECV_ID_VOR_LIST_HAS_DME"},
+	})
+}
+
+// WaypointFlags is SIMCONNECT_WAYPOINT_FLAGS, giving its uint32 constants a String method.
+type WaypointFlags uint32
+
+// String returns the names of the flags set in v, joined by |.
+func (v WaypointFlags) String() string {
+	return flagsString(uint32(v), []flagName{
+		{WAYPOINT_NONE, "WAYPOINT_NONE"},
+		{WAYPOINT_SPEED_REQUESTED, "WAYPOINT_SPEED_REQUESTED"},
+		{WAYPOINT_THROTTLE_REQUESTED, "WAYPOINT_THROTTLE_REQUESTED"},
+		{WAYPOINT_COMPUTE_VERTICAL_SPEED, "WAYPOINT_COMPUTE_VERTICAL_SPEED"},
+		{WAYPOINT_ALTITUDE_IS_AGL, "WAYPOINT_ALTITUDE_IS_AGL"},
+		{WAYPOINT_ON_GROUND, "WAYPOINT_ON_GROUND"},
+		{WAYPOINT_REVERSE, "WAYPOINT_REVERSE"},
+		{WAYPOINT_WRAP_TO_FIRST, "WAYPOINT_WRAP_TO_FIRST"},
+	})
+}
+
+// SIMCONNECT_EVENT_FLAG values
+const (
+	EVENT_FLAG_DEFAULT             uint32 = 0x00000000
+	EVENT_FLAG_FAST_REPEAT_TIMER   uint32 = 0x00000001 // set event repeat timer to simulate fast repeat
+	EVENT_FLAG_SLOW_REPEAT_TIMER   uint32 = 0x00000002 // set event repeat timer to simulate slow repeat
+	EVENT_FLAG_GROUPID_IS_PRIORITY uint32 = 0x00000010 // interpret GroupID parameter as priority value
+)
+
+// EventFlag is SIMCONNECT_EVENT_FLAG, giving its uint32 constants a String method.
+type EventFlag uint32
+
+// String returns the names of the flags set in v, joined by |.
+func (v EventFlag) String() string {
+	return flagsString(uint32(v), []flagName{
+		{EVENT_FLAG_DEFAULT, "EVENT_FLAG_DEFAULT"},
+		{EVENT_FLAG_FAST_REPEAT_TIMER, "EVENT_FLAG_FAST_REPEAT_TIMER"},
+		{EVENT_FLAG_SLOW_REPEAT_TIMER, "EVENT_FLAG_SLOW_REPEAT_TIMER"},
+		{EVENT_FLAG_GROUPID_IS_PRIORITY, "EVENT_FLAG_GROUPID_IS_PRIORITY"},
+	})
+}
+
+// DataRequestFlag is SIMCONNECT_DATA_REQUEST_FLAG, giving its uint32 constants a String method.
+type DataRequestFlag uint32
+
+// String returns the names of the flags set in v, joined by |.
+func (v DataRequestFlag) String() string {
+	return flagsString(uint32(v), []flagName{
+		{DATA_REQUEST_FLAG_DEFAULT, "DATA_REQUEST_FLAG_DEFAULT"},
+		{DATA_REQUEST_FLAG_CHANGED, "DATA_REQUEST_FLAG_CHANGED"},
+		{DATA_REQUEST_FLAG_TAGGED, "DATA_REQUEST_FLAG_TAGGED"},
+	})
+}
+
+// SIMCONNECT_DATA_SET_FLAG values
+const (
+	DATA_SET_FLAG_DEFAULT uint32 = 0x00000000
+	DATA_SET_FLAG_TAGGED  uint32 = 0x00000001 // data is in tagged format
+)
+
+// DataSetFlag is SIMCONNECT_DATA_SET_FLAG, giving its uint32 constants a String method.
+type DataSetFlag uint32
+
+// String returns the names of the flags set in v, joined by |.
+func (v DataSetFlag) String() string {
+	return flagsString(uint32(v), []flagName{
+		{DATA_SET_FLAG_DEFAULT, "DATA_SET_FLAG_DEFAULT"},
+		{DATA_SET_FLAG_TAGGED, "DATA_SET_FLAG_TAGGED"},
+	})
+}
+
+// SIMCONNECT_CREATE_CLIENT_DATA_FLAG values
+const (
+	CREATE_CLIENT_DATA_FLAG_DEFAULT   uint32 = 0x00000000
+	CREATE_CLIENT_DATA_FLAG_READ_ONLY uint32 = 0x00000001 // permit only ClientData creator to write into ClientData
+)
+
+// CreateClientDataFlag is SIMCONNECT_CREATE_CLIENT_DATA_FLAG, giving its uint32 constants a String method.
+type CreateClientDataFlag uint32
+
+// String returns the names of the flags set in v, joined by |.
+func (v CreateClientDataFlag) String() string {
+	return flagsString(uint32(v), []flagName{
+		{CREATE_CLIENT_DATA_FLAG_DEFAULT, "CREATE_CLIENT_DATA_FLAG_DEFAULT"},
+		{CREATE_CLIENT_DATA_FLAG_READ_ONLY, "CREATE_CLIENT_DATA_FLAG_READ_ONLY"},
+	})
+}
+
+// SIMCONNECT_CLIENT_DATA_REQUEST_FLAG values
+const (
+	CLIENT_DATA_REQUEST_FLAG_DEFAULT uint32 = 0x00000000
+	CLIENT_DATA_REQUEST_FLAG_CHANGED uint32 = 0x00000001 // send requested ClientData when value(s) change
+	CLIENT_DATA_REQUEST_FLAG_TAGGED  uint32 = 0x00000002 // send requested ClientData in tagged format
+)
+
+// ClientDataRequestFlag is SIMCONNECT_CLIENT_DATA_REQUEST_FLAG, giving its uint32 constants a String method.
+type ClientDataRequestFlag uint32
+
+// String returns the names of the flags set in v, joined by |.
+func (v ClientDataRequestFlag) String() string {
+	return flagsString(uint32(v), []flagName{
+		{CLIENT_DATA_REQUEST_FLAG_DEFAULT, "CLIENT_DATA_REQUEST_FLAG_DEFAULT"},
+		{CLIENT_DATA_REQUEST_FLAG_CHANGED, "CLIENT_DATA_REQUEST_FLAG_CHANGED"},
+		{CLIENT_DATA_REQUEST_FLAG_TAGGED, "CLIENT_DATA_REQUEST_FLAG_TAGGED"},
+	})
+}
+
+// SIMCONNECT_CLIENT_DATA_SET_FLAG values
+const (
+	CLIENT_DATA_SET_FLAG_DEFAULT uint32 = 0x00000000
+	CLIENT_DATA_SET_FLAG_TAGGED  uint32 = 0x00000001 // data is in tagged format
+)
+
+// ClientDataSetFlag is SIMCONNECT_CLIENT_DATA_SET_FLAG, giving its uint32 constants a String method.
+type ClientDataSetFlag uint32
+
+// String returns the names of the flags set in v, joined by |.
+func (v ClientDataSetFlag) String() string {
+	return flagsString(uint32(v), []flagName{
+		{CLIENT_DATA_SET_FLAG_DEFAULT, "CLIENT_DATA_SET_FLAG_DEFAULT"},
+		{CLIENT_DATA_SET_FLAG_TAGGED, "CLIENT_DATA_SET_FLAG_TAGGED"},
+	})
+}
+
+// SIMCONNECT_VIEW_SYSTEM_EVENT_DATA values
+const (
+	VIEW_SYSTEM_EVENT_DATA_COCKPIT_2D      uint32 = 0x00000001 // 2D Panels in cockpit view
+	VIEW_SYSTEM_EVENT_DATA_COCKPIT_VIRTUAL uint32 = 0x00000002 // Virtual (3D) panels in cockpit view
+	VIEW_SYSTEM_EVENT_DATA_ORTHOGONAL      uint32 = 0x00000004 // Orthogonal (Map) view
+)
+
+// ViewSystemEventData is SIMCONNECT_VIEW_SYSTEM_EVENT_DATA, giving its uint32 constants a String method.
+type ViewSystemEventData uint32
+
+// String returns the names of the flags set in v, joined by |.
+func (v ViewSystemEventData) String() string {
+	return flagsString(uint32(v), []flagName{
+		{VIEW_SYSTEM_EVENT_DATA_COCKPIT_2D, "VIEW_SYSTEM_EVENT_DATA_COCKPIT_2D"},
+		{VIEW_SYSTEM_EVENT_DATA_COCKPIT_VIRTUAL, "VIEW_SYSTEM_EVENT_DATA_COCKPIT_VIRTUAL"},
+		{VIEW_SYSTEM_EVENT_DATA_ORTHOGONAL, "VIEW_SYSTEM_EVENT_DATA_ORTHOGONAL"},
+	})
+}
+
+// SIMCONNECT_SOUND_SYSTEM_EVENT_DATA values
+const (
+	SOUND_SYSTEM_EVENT_DATA_MASTER uint32 = 0x00000001 // Sound Master
+)
+
+// SoundSystemEventData is SIMCONNECT_SOUND_SYSTEM_EVENT_DATA, giving its uint32 constants a String method.
+type SoundSystemEventData uint32
+
+// String returns the names of the flags set in v, joined by |.
+func (v SoundSystemEventData) String() string {
+	return flagsString(uint32(v), []flagName{
+		{SOUND_SYSTEM_EVENT_DATA_MASTER, "SOUND_SYSTEM_EVENT_DATA_MASTER"},
+	})
+}
+
+const (
+	RECV_EXCEPTION_UNKNOWN_SENDID uint32 = 0
+	RECV_EXCEPTION_UNKNOWN_INDEX  uint32 = 0xffffffff
+)
+
+const (
+	RECV_EVENT_UNKNOWN_GROUP uint32 = 0xffffffff
+)
+
+// RecvEventEx1 is SIMCONNECT_RECV_EVENT_EX1.
+type RecvEventEx1 struct {
+	Recv
+	GroupID uint32
+	EventID uint32
+	Data0   uint32
+	Data1   uint32
+	Data2   uint32
+	Data3   uint32
+	Data4   uint32
+}
+
+// RecvFacilityData is SIMCONNECT_RECV_FACILITY_DATA.
+type RecvFacilityData struct {
+	Recv
+	UserRequestID         uint32
+	UniqueRequestID       uint32
+	ParentUniqueRequestID uint32
+	Type                  uint32 // SIMCONNECT_FACILITY_DATA_TYPE
+	IsListItem            uint32
+	ItemIndex             uint32
+	ListSize              uint32
+	Data                  uint32 // data begins here
+}
+
+// RecvFacilityDataEnd is SIMCONNECT_RECV_FACILITY_DATA_END.
+type RecvFacilityDataEnd struct {
+	Recv
+	RequestID uint32
+}
+
+type flagName struct {
+	value uint32
+	name  string
+}
+
+// flagsString returns the names of the flags set in v joined by |, with any bits left over in hex.
+func flagsString(v uint32, flags []flagName) string {
+	var names []string
+	for _, flag := range flags {
+		if flag.value == 0 {
+			if v == 0 {
+				return flag.name
+			}
+			continue
+		}
+		if v&flag.value == flag.value {
+			names = append(names, flag.name)
+			v &^= flag.value
+		}
+	}
+	if v != 0 || len(names) == 0 {
+		names = append(names, fmt.Sprintf("%#x", v))
+	}
+	return strings.Join(names, "|")
+}
@@ -0,0 +1,17 @@
+package simconnect_data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratedStrings(t *testing.T) {
+	assert.Equal(t, "EXCEPTION_UNRECOGNIZED_ID", Exception(EXCEPTION_UNRECOGNIZED_ID).String())
+	assert.Equal(t, "RECV_ID_EVENT_FRAME", RecvID(RECV_ID_EVENT_FRAME).String())
+	assert.Equal(t, "RecvID(999)", RecvID(999).String())
+
+	assert.Equal(t, "EVENT_FLAG_DEFAULT", EventFlag(0).String())
+	assert.Equal(t, "EVENT_FLAG_FAST_REPEAT_TIMER|EVENT_FLAG_SLOW_REPEAT_TIMER", EventFlag(3).String())
+	assert.Equal(t, "EVENT_FLAG_FAST_REPEAT_TIMER|0x80000000", EventFlag(0x80000001).String())
+}
@@ -1,28 +1,74 @@
 package simconnect
 
 import (
-	"errors"
+	"bytes"
+	"context"
+	"encoding/binary"
 	"fmt"
-	"io/ioutil"
-	"os"
-	"path/filepath"
 	"reflect"
 	"sync"
-	"syscall"
 	"time"
-	"unsafe"
 
 	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
-
-	_ "embed"
 )
 
 type SimconnectInstance struct {
-	handle           unsafe.Pointer // handle
-	definitionMap    map[string]uint32
+	name             string
+	transport        Transport
+	definitionMap    map[reflect.Type]uint32
+	definitionFields map[uint32][]definitionField
+	definitionTypes  map[uint32]reflect.Type
 	nextDefinitionID uint32
+	// definitionsRegistering holds the types whose definition is being registered, the channel being closed once done
+	definitionsRegistering map[reflect.Type]chan struct{}
+
+	// rejectedFields holds the fields of each definition rejected by the simulator, by field index
+	rejectedFields     map[uint32]map[int]*FieldError
+	dropRejectedFields bool
+
+	// Catalog warnings, see catalog.go. settableChecked holds the definitions already checked when setting data.
+	simVarWarning   func(SimVarWarning)
+	settableChecked map[uint32]bool
+
+	// dynamicRefs counts the DynamicDefinitions sharing each struct type, see dynamic.go
+	dynamicRefs map[reflect.Type]int
 
 	definitionMapMutex sync.Mutex
+	transportMutex     sync.Mutex
+
+	// Registrations replayed after reconnecting, see reconnect.go
+	eventMapMutex       sync.Mutex
+	systemEvents        map[uint32]string
+	systemEventStates   map[uint32]uint32
+	eventHandlers       map[uint32]func()
+	clientEvents        map[uint32]string
+	subscriptions       map[uint32]subscription
+	newTransport        func() (Transport, error)
+	reconnectBackoff    time.Duration
+	maxReconnectBackoff time.Duration
+
+	// Dispatcher state, see dispatch.go
+	dispatchMutex  sync.Mutex
+	requestWaiters map[uint32]chan dispatchResult
+	overflowed     map[uint32]bool
+	listeners      map[*listener]struct{}
+	dispatchErr    error
+	sentPackets    map[uint32]sentPacket
+	stopped        bool
+	stateWatchers  map[chan ConnectionStateChange]struct{}
+	nextRequestID  uint32
+	nextEventID    uint32
+	timeout        time.Duration
+	stopDispatcher chan struct{}
+	closeOnce      sync.Once
+	closeErr       error
+	dispatcherDone chan struct{}
+
+	// Handlers registered with the On* methods, see router.go
+	handlersMutex sync.Mutex
+	handlers      []*handler
+	routerOnce    sync.Once
+	router        *listener
 }
 
 // Report contains data for a given sim object
@@ -98,278 +144,229 @@ type SetSimObjectDataExpose struct {
 	Pitch     float32
 }
 
-var (
-	procSimconnectOpen                       *syscall.LazyProc
-	procSimconnectClose                      *syscall.LazyProc
-	procSimconnectRequestDataOnSimObjectType *syscall.LazyProc
-	procSimconnectRequestDataOnSimObject     *syscall.LazyProc
-	procSimconnectAddtodatadefinition        *syscall.LazyProc
-	procSimconnectGetnextdispatch            *syscall.LazyProc
-	procSimconnectFlightplanLoad             *syscall.LazyProc
-	procSimconnectAICreateParkedATCAircraft  *syscall.LazyProc
-	procSimconnectAICreateNonATCAircraft     *syscall.LazyProc
-	procSimconnectSetDataOnSimObject         *syscall.LazyProc
-	procSimconnectCreateEnrouteATCAircraft   *syscall.LazyProc
-	procSimconnectAISetAircraftFlightPlan    *syscall.LazyProc
-	procSimconnectAIRemoveObject             *syscall.LazyProc
-	procSimconnectMapClientEventToSimEvent   *syscall.LazyProc
-	procSimconnectSubscribeToSystemEvent     *syscall.LazyProc
-	procSimconnectTransmitClientEvent        *syscall.LazyProc
-	procSimconnectText                       *syscall.LazyProc
-)
-
+// getDefinitionID returns the ID of the definition of the type input points to. When it is not registered yet, created
+// is set and the caller must register it then call finishDefinition. Callers meanwhile wait for it to be finished.
 func (instance *SimconnectInstance) getDefinitionID(input interface{}) (defID uint32, created bool) {
-	structName := reflect.TypeOf(input).Elem().Name()
+	definitionType := reflect.TypeOf(input).Elem()
 
 	instance.definitionMapMutex.Lock()
 	defer instance.definitionMapMutex.Unlock()
 
-	id, ok := instance.definitionMap[structName]
-	if !ok {
-		instance.definitionMap[structName] = instance.nextDefinitionID
-		instance.nextDefinitionID++
-		return instance.definitionMap[structName], true
+	for {
+		if id, ok := instance.definitionMap[definitionType]; ok {
+			return id, false
+		}
+		registering, ok := instance.definitionsRegistering[definitionType]
+		if !ok {
+			break
+		}
+		// Registered by another call, or left for this one to register again should it fail
+		instance.definitionMapMutex.Unlock()
+		<-registering
+		instance.definitionMapMutex.Lock()
 	}
 
-	return id, false
+	id := instance.nextDefinitionID
+	instance.nextDefinitionID++
+	instance.definitionsRegistering[definitionType] = make(chan struct{})
+	return id, true
 }
 
-func (instance *SimconnectInstance) SubscribeToSystemEvent(eventID uint32, eventName string) error {
-	_eventName := []byte(eventName + "\x00")
-
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(eventID),
-		uintptr(unsafe.Pointer(&_eventName[0])),
-	}
-
-	r1, _, err := procSimconnectSubscribeToSystemEvent.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf("SimConnect_SubscribeToSystemEvent for %s error: %d %s", eventName, r1, err)
+// finishDefinition makes the definition registered under definitionID available once err is nil. Otherwise what was
+// added of it is cleared, so it is registered again in full by the next call using definitionType.
+func (instance *SimconnectInstance) finishDefinition(definitionType reflect.Type, definitionID uint32, err error) {
+	if err != nil {
+		// Nothing more can be done should the simulator not clear it, the ID not being used again
+		instance.send(sentPacket{method: "ClearDataDefinition"}, func(transport Transport) error {
+			return transport.ClearDataDefinition(definitionID)
+		})
 	}
 
-	return nil
-}
-
-// Made request to DLL to actually register a data definition
-func (instance *SimconnectInstance) addToDataDefinitions(definitionID uint32, name, unit string, dataType uint32) error {
-	nameParam := []byte(name + "\x00")
-	unitParam := []byte(unit + "\x00")
+	instance.definitionMapMutex.Lock()
+	defer instance.definitionMapMutex.Unlock()
 
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(definitionID),
-		uintptr(unsafe.Pointer(&nameParam[0])),
-		uintptr(0),
-		uintptr(dataType),
-		uintptr(float32(0)),
-		uintptr(0xffffffff),
-	}
-	if unit != "" {
-		args[3] = uintptr(unsafe.Pointer(&unitParam[0]))
+	if err == nil {
+		instance.definitionMap[definitionType] = definitionID
+	} else {
+		delete(instance.definitionFields, definitionID)
+		delete(instance.definitionTypes, definitionID)
+		delete(instance.rejectedFields, definitionID)
+		delete(instance.settableChecked, definitionID)
 	}
+	close(instance.definitionsRegistering[definitionType])
+	delete(instance.definitionsRegistering, definitionType)
+}
 
-	r1, _, err := procSimconnectAddtodatadefinition.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf("add to data definition failed for %s error: %d %s", name, r1, err)
+// Made request to DLL to actually register a data definition. fieldIndex and fieldName identify the struct field the
+// datum is decoded into, so exceptions can be reported against it.
+func (instance *SimconnectInstance) addToDataDefinitions(definitionID uint32, field definitionField) error {
+	err := instance.send(field.packet(definitionID), field.add(definitionID))
+	if err != nil {
+		return err
 	}
 
+	instance.recordDefinitionField(definitionID, field)
 	return nil
 }
 
 func (instance *SimconnectInstance) registerDataDefinition(input interface{}) error {
+	// The tags are checked before anything is registered so a bad one does not leave half a definition behind
+	t := reflect.TypeOf(input).Elem()
+	fields, err := parseDefinitionFields(t)
+	if err != nil {
+		return err
+	}
+
 	definitionID, created := instance.getDefinitionID(input)
 	if !created {
 		return nil
 	}
+	instance.recordDefinitionType(definitionID, t)
+	instance.warnUnknownSimVars(definitionID, fields)
 
-	v := reflect.ValueOf(input).Elem()
-	for j := 1; j < v.NumField(); j++ {
-		fieldName := v.Type().Field(j).Name
-		nameTag, _ := v.Type().Field(j).Tag.Lookup("name")
-		unitTag, _ := v.Type().Field(j).Tag.Lookup("unit")
-
-		fieldType := v.Field(j).Kind().String()
-		if fieldType == "array" {
-			fieldType = fmt.Sprintf("[%d]byte", v.Field(j).Type().Len())
-		}
-
-		if nameTag == "" {
-			return fmt.Errorf("name tag not found %s", fieldName)
-		}
-
-		dataType, err := derefDataType(fieldType)
+	for _, field := range fields {
+		err = instance.addToDataDefinitions(definitionID, field)
 		if err != nil {
-			return fmt.Errorf("error derefing datatype: %v", err)
-		}
-
-		err = instance.addToDataDefinitions(definitionID, nameTag, unitTag, dataType)
-		if err != nil {
-			return fmt.Errorf("error adding data definition: %v", err)
+			err = fmt.Errorf("error adding data definition: %v", err)
+			break
 		}
 	}
 
-	return nil
+	instance.finishDefinition(t, definitionID, err)
+	return err
 }
 
 func (instance *SimconnectInstance) requestDataOnSimObjectType(requestID, defineID, radius, simObjectType uint32) error {
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(requestID),
-		uintptr(defineID),
-		uintptr(radius),
-		uintptr(simObjectType),
-	}
-
-	r1, _, err := procSimconnectRequestDataOnSimObjectType.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf("requestData for requestID %d defineID %d error: %d %v",
-			requestID, defineID, r1, err)
-	}
-
-	return nil
+	return instance.send(sentPacket{method: "RequestDataOnSimObjectType", request: true, requestID: requestID}, func(transport Transport) error {
+		return transport.RequestDataOnSimObjectType(requestID, defineID, radius, simObjectType)
+	})
 }
 
 func (instance *SimconnectInstance) requestDataOnSimObject(requestID, defineID, objectID, period uint32) error {
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(requestID),
-		uintptr(defineID),
-		uintptr(objectID),
-		uintptr(period),
-	}
-
-	r1, _, err := procSimconnectRequestDataOnSimObject.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf("requestData for requestID %d defineID %d objectID %d error: %d %v", requestID, defineID, objectID, r1, err)
-	}
-
-	return nil
+	return instance.send(sentPacket{method: "RequestDataOnSimObject", request: true, requestID: requestID}, func(transport Transport) error {
+		return transport.RequestDataOnSimObject(requestID, defineID, objectID, period, 0, 0, 0, 0)
+	})
 }
 
-func (instance *SimconnectInstance) getData() (unsafe.Pointer, error) {
-	var ppData unsafe.Pointer
-	var ppDataLength uint32
+func (instance *SimconnectInstance) processConnectionOpenData() error {
+	l := instance.listen(simconnect_data.RECV_ID_OPEN, false, false)
+	defer instance.unlisten(l)
+
+	// The listener must be in place before the dispatcher can route the open response
+	instance.startDispatcher()
 
-	r1, _, err := procSimconnectGetnextdispatch.Call(
-		uintptr(instance.handle),
-		uintptr(unsafe.Pointer(&ppData)),
-		uintptr(unsafe.Pointer(&ppDataLength)),
-	)
+	ctx, cancel := instance.withTimeout(context.Background())
+	defer cancel()
 
-	if r1 < 0 {
-		return nil, fmt.Errorf("GetNextDispatch error: %d %v", r1, err)
+	select {
+	case result := <-l.results:
+		if result.err != nil {
+			return result.err
+		}
+		recvOpen, err := simconnect_data.DecodeRecvOpen(result.data)
+		if err != nil {
+			return err
+		}
+		fmt.Println("SIMCONNECT_RECV_ID_OPEN", fmt.Sprintf("%s", recvOpen.ApplicationName))
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error waiting for open connection: %w", ctx.Err())
 	}
+}
 
-	if uint32(r1) == simconnect_data.E_FAIL {
-		// No new message
-		return nil, nil
+// request sends a request for requestID and returns its decoded response, see processSimObjectTypeData.
+func (instance *SimconnectInstance) request(ctx context.Context, requestID uint32, send func() error) (interface{}, error) {
+	data, err := instance.awaitRequest(ctx, requestID, send)
+	if err != nil {
+		return nil, err
 	}
 
-	return ppData, nil
+	return instance.processSimObjectTypeData(data)
 }
 
-func (instance *SimconnectInstance) processData() (unsafe.Pointer, *simconnect_data.Recv, error) {
-	var ppData unsafe.Pointer
-	var err error
-	var recvInfo *simconnect_data.Recv
-	loopErr := retryFunc(20, time.Millisecond*100, func() (bool, error) {
+func (instance *SimconnectInstance) processSimObjectTypeData(data []byte) (interface{}, error) {
+	data, err := checkRecvSize(data)
+	if err != nil {
+		return nil, err
+	}
 
-		ppData, err = instance.getData()
+	switch id := recvID(data); id {
+	case simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, simconnect_data.RECV_ID_SIMOBJECT_DATA:
+		header, err := decodeHeader(data)
 		if err != nil {
-			return true, nil
-		}
-		if ppData == nil {
-			return true, err
+			return nil, err
 		}
 
-		recvInfo = (*simconnect_data.Recv)(ppData)
-
-		if recvInfo.ID == simconnect_data.RECV_ID_EXCEPTION {
-			return true, errors.New("exception")
+		instance.definitionMapMutex.Lock()
+		definitionType := instance.definitionTypes[header.DefineID]
+		instance.definitionMapMutex.Unlock()
+		if definitionType == nil {
+			return nil, fmt.Errorf("received data for unknown definition %d", header.DefineID)
 		}
 
-		return false, nil
-	})
-	if loopErr != nil {
-		return nil, nil, loopErr
+		value := reflect.New(definitionType)
+		if err := instance.decodeData(data, value.Elem()); err != nil {
+			return nil, err
+		}
+		return value.Interface(), nil
+	case simconnect_data.RECV_ID_ASSIGNED_OBJECT_ID:
+		recvData, err := simconnect_data.DecodeRecvAssignedObjectID(data)
+		if err != nil {
+			return nil, err
+		}
+		return recvData.ObjectID, nil
+	default:
+		return nil, fmt.Errorf("processSimObjectTypeData() hit default recvID: %d", id)
 	}
-
-	return ppData, recvInfo, nil
 }
 
-func (instance *SimconnectInstance) processConnectionOpenData() error {
-	ppData, recvInfo, err := instance.processData()
+// decodeData decodes the data received for a definition into value, which must be of the type registered for it.
+// Fields missing from tagged data are left as they were.
+func (instance *SimconnectInstance) decodeData(data []byte, value reflect.Value) error {
+	header, err := decodeHeader(data)
 	if err != nil {
 		return err
 	}
-	switch recvInfo.ID {
-	case simconnect_data.RECV_ID_EXCEPTION:
-		return fmt.Errorf("received exception")
-	case simconnect_data.RECV_ID_OPEN:
-		recvOpen := *(*simconnect_data.RecvOpen)(ppData)
-		fmt.Println("SIMCONNECT_RECV_ID_OPEN", fmt.Sprintf("%s", recvOpen.ApplicationName))
-		return nil
-	default:
-		return fmt.Errorf("processConnectionOpenData() hit default")
-	}
-}
 
-func (instance *SimconnectInstance) processSimObjectTypeData() (interface{}, error) {
-	ppData, recvInfo, err := instance.processData()
-	if err != nil {
-		return nil, err
-	}
-	switch recvInfo.ID {
-	case simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE:
-		recvData := *(*simconnect_data.RecvSimobjectDataByType)(ppData)
+	instance.definitionMapMutex.Lock()
+	defer instance.definitionMapMutex.Unlock()
 
-		instance.definitionMapMutex.Lock()
-		defer instance.definitionMapMutex.Unlock()
-
-		switch recvData.RequestID {
-		case instance.definitionMap["Report"]:
-			report2 := (*Report)(ppData)
-			return report2, nil
-		case instance.definitionMap["APReport"]:
-			report2 := (*APReport)(ppData)
-			return report2, nil
-		}
+	if instance.definitionTypes[header.DefineID] != value.Type() {
+		return fmt.Errorf("received data for definition %d instead of %s", header.DefineID, value.Type())
+	}
 
-		break
-	case simconnect_data.RECV_ID_SIMOBJECT_DATA:
-		report2 := (*Report)(ppData)
-		return report2, nil
-	case simconnect_data.RECV_ID_ASSIGNED_OBJECT_ID:
-		recvData := *(*simconnect_data.RecvAssignedObjectID)(ppData)
-		return recvData.ObjectID, nil
-	default:
-		recvData := *(*simconnect_data.RecvSimobjectDataByType)(ppData)
-		return ppData, fmt.Errorf("processSimObjectTypeData() hit default recvInfo: %v ppData: %+v", recvInfo, recvData)
+	// The simulator leaves rejected fields out of the data, so they are skipped when dropped
+	rejected := instance.rejectedFields[header.DefineID]
+	if len(rejected) > 0 && !instance.dropRejectedFields {
+		return firstFieldError(rejected)
 	}
 
-	return ppData, fmt.Errorf("Unknown format")
+	return decodeDefinition(data, value, instance.definitionFields[header.DefineID], rejected)
 }
 
 // GetReport returns Report struct containing current user data
 func (instance *SimconnectInstance) GetReport() (*Report, error) {
+	return instance.GetReportContext(context.Background())
+}
+
+// GetReportContext is GetReport which gives up once ctx is done
+func (instance *SimconnectInstance) GetReportContext(ctx context.Context) (*Report, error) {
 	report := &Report{}
 	err := instance.registerDataDefinition(report)
 	if err != nil {
 		return nil, err
 	}
 	definitionID, _ := instance.getDefinitionID(report)
-	err = instance.requestDataOnSimObjectType(
-		definitionID,
-		definitionID,
-		0,
-		simconnect_data.SIMOBJECT_TYPE_USER,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	reportData, err := instance.processSimObjectTypeData()
+	requestID := instance.newRequestID()
+
+	reportData, err := instance.request(ctx, requestID, func() error {
+		return instance.requestDataOnSimObjectType(
+			requestID,
+			definitionID,
+			0,
+			simconnect_data.SIMOBJECT_TYPE_USER,
+		)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -379,23 +376,27 @@ func (instance *SimconnectInstance) GetReport() (*Report, error) {
 
 // GetAPReport returns APReport struct containing current user data
 func (instance *SimconnectInstance) GetAPReport() (*APReport, error) {
+	return instance.GetAPReportContext(context.Background())
+}
+
+// GetAPReportContext is GetAPReport which gives up once ctx is done
+func (instance *SimconnectInstance) GetAPReportContext(ctx context.Context) (*APReport, error) {
 	report := &APReport{}
 	err := instance.registerDataDefinition(report)
 	if err != nil {
 		return nil, err
 	}
 	definitionID, _ := instance.getDefinitionID(report)
-	err = instance.requestDataOnSimObjectType(
-		definitionID,
-		definitionID,
-		0,
-		simconnect_data.SIMOBJECT_TYPE_USER,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	reportData, err := instance.processSimObjectTypeData()
+	requestID := instance.newRequestID()
+
+	reportData, err := instance.request(ctx, requestID, func() error {
+		return instance.requestDataOnSimObjectType(
+			requestID,
+			definitionID,
+			0,
+			simconnect_data.SIMOBJECT_TYPE_USER,
+		)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -405,19 +406,22 @@ func (instance *SimconnectInstance) GetAPReport() (*APReport, error) {
 
 // GetReportOnObjectID returns a Report struct containing the data for the Object ID passed in
 func (instance *SimconnectInstance) GetReportOnObjectID(objectID uint32) (*Report, error) {
+	return instance.GetReportOnObjectIDContext(context.Background(), objectID)
+}
+
+// GetReportOnObjectIDContext is GetReportOnObjectID which gives up once ctx is done
+func (instance *SimconnectInstance) GetReportOnObjectIDContext(ctx context.Context, objectID uint32) (*Report, error) {
 	report := &Report{}
 	err := instance.registerDataDefinition(report)
 	if err != nil {
 		return nil, err
 	}
 	definitionID, _ := instance.getDefinitionID(report)
-	err = instance.requestDataOnSimObject(definitionID, definitionID, objectID, simconnect_data.SIMCONNECT_PERIOD_ONCE)
+	requestID := instance.newRequestID()
 
-	if err != nil {
-		return nil, err
-	}
-
-	reportData, err := instance.processSimObjectTypeData()
+	reportData, err := instance.request(ctx, requestID, func() error {
+		return instance.requestDataOnSimObject(requestID, definitionID, objectID, simconnect_data.SIMCONNECT_PERIOD_ONCE)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -426,69 +430,32 @@ func (instance *SimconnectInstance) GetReportOnObjectID(objectID uint32) (*Repor
 
 }
 
-func (instance *SimconnectInstance) processEventData(terminate <-chan struct{}) (<-chan simconnect_data.RecvEvent, <-chan error) {
-	recvEventChan := make(chan simconnect_data.RecvEvent, 1)
-	errorChan := make(chan error, 1)
-
-	go func() {
-	forLoop:
-		for {
-			select {
-			case <-terminate:
-				return
-			default:
-				ppData, recvInfo, err := instance.processData()
-				if err != nil {
-					continue
-				}
-				switch recvInfo.ID {
-				case simconnect_data.RECV_ID_EXCEPTION:
-					errorChan <- fmt.Errorf("received exception")
-					break forLoop
-				case simconnect_data.RECV_ID_EVENT:
-					recvOpen := *(*simconnect_data.RecvEvent)(ppData)
-					recvEventChan <- recvOpen
-					break forLoop
-				default:
-					errorChan <- fmt.Errorf("processConnectionOpenData() hit default")
-					break forLoop
-				}
-
-			}
-		}
-		close(recvEventChan)
-		close(errorChan)
-		return
-	}()
-
-	return recvEventChan, errorChan
-
+func (instance *SimconnectInstance) openConnection(simconnectName string) error {
+	return instance.call(func(transport Transport) error {
+		return transport.Open(simconnectName)
+	})
 }
 
-func (instance *SimconnectInstance) openConnection(simconnectName string) error {
-	args := []uintptr{
-		uintptr(unsafe.Pointer(&instance.handle)),
-		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(simconnectName))),
-		0,
-		0,
-		0,
-		0,
-	}
+func (instance *SimconnectInstance) closeConnection() error {
+	instance.closeOnce.Do(func() {
+		close(instance.stopDispatcher)
+		<-instance.dispatcherDone
 
-	r1, _, err := procSimconnectOpen.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf("open connect failed, error: %d %v", r1, err)
-	}
+		instance.closeErr = instance.call(func(transport Transport) error {
+			return transport.Close()
+		})
+	})
 
-	return nil
+	return instance.closeErr
 }
 
-func (instance *SimconnectInstance) closeConnection() error {
-	r1, _, err := procSimconnectClose.Call(uintptr(instance.handle))
-	if int32(r1) < 0 {
-		return fmt.Errorf("close connection failed, error %d %v", r1, err)
-	}
-	return nil
+// SetDefaultTimeout sets how long calls wait for the simulator to respond when the context passed in has no deadline
+// of its own. A timeout of zero waits until the context is cancelled.
+func (instance *SimconnectInstance) SetDefaultTimeout(timeout time.Duration) {
+	instance.dispatchMutex.Lock()
+	defer instance.dispatchMutex.Unlock()
+
+	instance.timeout = timeout
 }
 
 // Close will end the connection to the SimConnect API
@@ -499,39 +466,23 @@ func (instance *SimconnectInstance) Close() error {
 // LoadFlightPlan will load the supplied flight plan path into the users aircraft. FlightPlanPath must be a pln but the
 // .pln extension must not be supplied with the flight plan.
 func (instance *SimconnectInstance) LoadFlightPlan(flightPlanPath string) error {
-
-	flightPlanPathArg := []byte(flightPlanPath + "\x00")
-
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(unsafe.Pointer(&flightPlanPathArg[0])),
-	}
-	r1, _, err := procSimconnectFlightplanLoad.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf("error: %d %v", r1, err)
-	}
-
-	return nil
+	return instance.send(sentPacket{method: "FlightPlanLoad"}, func(transport Transport) error {
+		return transport.FlightPlanLoad(flightPlanPath)
+	})
 }
 
 // LoadParkedATCAircraft will load a parked ATC aircraft with the specified parameters. See SimConnect API reference.
 func (instance *SimconnectInstance) LoadParkedATCAircraft(containerTitle, tailNumber, airportICAO string, requestID int) (*uint32, error) {
-	containerTitleArg := []byte(containerTitle + "\x00")
-	tailNumberArg := []byte(tailNumber + "\x00")
-	airportICAOArg := []byte(airportICAO + "\x00")
-
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(unsafe.Pointer(&containerTitleArg[0])),
-		uintptr(unsafe.Pointer(&tailNumberArg[0])),
-		uintptr(unsafe.Pointer(&airportICAOArg[0])),
-		uintptr(unsafe.Pointer(&requestID)),
-	}
-	r1, _, err := procSimconnectAICreateParkedATCAircraft.Call(args...)
-	if int32(r1) < 0 {
-		return nil, fmt.Errorf("error: %d %v", r1, err)
-	}
-	objectIDInterface, err := instance.processSimObjectTypeData()
+	return instance.LoadParkedATCAircraftContext(context.Background(), containerTitle, tailNumber, airportICAO, requestID)
+}
+
+// LoadParkedATCAircraftContext is LoadParkedATCAircraft which gives up once ctx is done
+func (instance *SimconnectInstance) LoadParkedATCAircraftContext(ctx context.Context, containerTitle, tailNumber, airportICAO string, requestID int) (*uint32, error) {
+	objectIDInterface, err := instance.request(ctx, uint32(requestID), func() error {
+		return instance.send(sentPacket{method: "AICreateParkedATCAircraft", request: true, requestID: uint32(requestID)}, func(transport Transport) error {
+			return transport.AICreateParkedATCAircraft(containerTitle, tailNumber, airportICAO, uint32(requestID))
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -541,22 +492,16 @@ func (instance *SimconnectInstance) LoadParkedATCAircraft(containerTitle, tailNu
 
 // LoadNonATCAircraft will load a non ATC (vfr) aircraft with the specified parameters. See SimConnect API reference.
 func (instance *SimconnectInstance) LoadNonATCAircraft(containerTitle, tailNumber string, initPos simconnect_data.SimconnectDataInitPosition, requestID int) (*uint32, error) {
-	containerTitleArg := []byte(containerTitle + "\x00")
-	tailNumberArg := []byte(tailNumber + "\x00")
-
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(unsafe.Pointer(&containerTitleArg[0])),
-		uintptr(unsafe.Pointer(&tailNumberArg[0])),
-		uintptr(unsafe.Pointer(&initPos)),
-		uintptr(unsafe.Pointer(&requestID)),
-	}
-	r1, _, err := procSimconnectAICreateNonATCAircraft.Call(args...)
-	if int32(r1) < 0 {
-		return nil, fmt.Errorf("error: %d %v", r1, err)
-	}
+	return instance.LoadNonATCAircraftContext(context.Background(), containerTitle, tailNumber, initPos, requestID)
+}
 
-	objectIDInterface, err := instance.processSimObjectTypeData()
+// LoadNonATCAircraftContext is LoadNonATCAircraft which gives up once ctx is done
+func (instance *SimconnectInstance) LoadNonATCAircraftContext(ctx context.Context, containerTitle, tailNumber string, initPos simconnect_data.SimconnectDataInitPosition, requestID int) (*uint32, error) {
+	objectIDInterface, err := instance.request(ctx, uint32(requestID), func() error {
+		return instance.send(sentPacket{method: "AICreateNonATCAircraft", request: true, requestID: uint32(requestID)}, func(transport Transport) error {
+			return transport.AICreateNonATCAircraft(containerTitle, tailNumber, initPos, uint32(requestID))
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -575,7 +520,6 @@ func b2i(b bool) float64 {
 // aircraft. See SimConnect API reference.
 func (instance *SimconnectInstance) SetDataOnSimObject(objectID uint32, data []SetSimObjectDataExpose) error {
 	InternalSimObjectData := struct {
-		simconnect_data.RecvSimobjectDataByType
 		Airspeed  float64 `name:"Airspeed Indicated" unit:"knot"`
 		Altitude  float64 `name:"Plane Altitude" unit:"feet"`
 		Bank      float64 `name:"Plane Bank Degrees"`
@@ -608,50 +552,34 @@ func (instance *SimconnectInstance) SetDataOnSimObject(objectID uint32, data []S
 	}
 	defID, _ := instance.getDefinitionID(&InternalSimObjectData)
 
-	return instance.setDataOnSimObject(defID, objectID, 0, uint32(len(data)), uint32(8*8), unsafe.Pointer(&buf[0]))
-}
-
-func (instance *SimconnectInstance) setDataOnSimObject(defID, objectID, flags, arrayCount, size uint32, byteArray unsafe.Pointer) error {
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(defID),
-		uintptr(objectID),
-		uintptr(flags),
-		uintptr(arrayCount),
-		uintptr(size),
-		uintptr(byteArray),
+	byteBuf := &bytes.Buffer{}
+	if err := binary.Write(byteBuf, binary.LittleEndian, buf); err != nil {
+		return err
 	}
 
-	r1, _, err := procSimconnectSetDataOnSimObject.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf("setDataOnSimObject for objectID %d error: %d %v", objectID, r1, err)
-	}
+	return instance.setDataOnSimObject(defID, objectID, 0, uint32(len(data)), uint32(8*8), byteBuf.Bytes())
+}
 
-	return nil
+func (instance *SimconnectInstance) setDataOnSimObject(defID, objectID, flags, arrayCount, size uint32, data []byte) error {
+	instance.warnNotSettable(defID)
+	return instance.send(sentPacket{method: "SetDataOnSimObject"}, func(transport Transport) error {
+		return transport.SetDataOnSimObject(defID, objectID, flags, arrayCount, size, data)
+	})
 }
 
 // CreateEnrouteATCAircraft allows you to create an ATC already part way through its flight plan. See SimConnect API
 // reference.
 func (instance *SimconnectInstance) CreateEnrouteATCAircraft(containerTitle, tailNumber string, flightNumber uint32, flightPlanPath string, flightPlanPosition float32, touchAndGo bool, requestID uint32) (*uint32, error) {
-	containerTitleArg := []byte(containerTitle + "\x00")
-	tailNumberArg := []byte(tailNumber + "\x00")
-	pathArg := []byte(flightPlanPath + "\x00")
-
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(unsafe.Pointer(&containerTitleArg[0])),
-		uintptr(unsafe.Pointer(&tailNumberArg[0])),
-		uintptr(flightNumber),
-		uintptr(unsafe.Pointer(&pathArg[0])),
-		uintptr(flightPlanPosition),
-		uintptr(b2i(touchAndGo)),
-		uintptr(requestID),
-	}
-	r1, _, err := procSimconnectCreateEnrouteATCAircraft.Call(args...)
-	if int32(r1) < 0 {
-		return nil, fmt.Errorf("error: %d %v", r1, err)
-	}
-	objectIDInterface, err := instance.processSimObjectTypeData()
+	return instance.CreateEnrouteATCAircraftContext(context.Background(), containerTitle, tailNumber, flightNumber, flightPlanPath, flightPlanPosition, touchAndGo, requestID)
+}
+
+// CreateEnrouteATCAircraftContext is CreateEnrouteATCAircraft which gives up once ctx is done
+func (instance *SimconnectInstance) CreateEnrouteATCAircraftContext(ctx context.Context, containerTitle, tailNumber string, flightNumber uint32, flightPlanPath string, flightPlanPosition float32, touchAndGo bool, requestID uint32) (*uint32, error) {
+	objectIDInterface, err := instance.request(ctx, requestID, func() error {
+		return instance.send(sentPacket{method: "AICreateEnrouteATCAircraft", request: true, requestID: requestID}, func(transport Transport) error {
+			return transport.AICreateEnrouteATCAircraft(containerTitle, tailNumber, flightNumber, flightPlanPath, float64(flightPlanPosition), touchAndGo, requestID)
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -663,156 +591,118 @@ func (instance *SimconnectInstance) CreateEnrouteATCAircraft(containerTitle, tai
 
 // SetAircraftFlightPlan allows you to set a flight plan for an existing aircraft. See SimConnect API reference.
 func (instance *SimconnectInstance) SetAircraftFlightPlan(objectID, requestID uint32, flightPlanPath string) error {
-	pathArg := []byte(flightPlanPath + "\x00")
-
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(objectID),
-		uintptr(unsafe.Pointer(&pathArg[0])),
-		uintptr(requestID),
-	}
-
-	r1, _, err := procSimconnectAISetAircraftFlightPlan.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf("error: %d %v", r1, err)
-	}
-
-	return nil
+	return instance.send(sentPacket{method: "AISetAircraftFlightPlan"}, func(transport Transport) error {
+		return transport.AISetAircraftFlightPlan(objectID, flightPlanPath, requestID)
+	})
 }
 
 // RemoveAIObject will remove an AI object from the sim. See SimConnect API reference.
 func (instance *SimconnectInstance) RemoveAIObject(objectID, requestID uint32) error {
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(objectID),
-		uintptr(requestID),
-	}
-
-	r1, _, err := procSimconnectAIRemoveObject.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf("error: %d %v", r1, err)
-	}
-
-	return nil
+	return instance.send(sentPacket{method: "AIRemoveObject"}, func(transport Transport) error {
+		return transport.AIRemoveObject(objectID, requestID)
+	})
 }
 
 func (instance *SimconnectInstance) MapClientEventToSimEvent(eventID uint32, eventName string) error {
-	_eventName := []byte(eventName + "\x00")
-
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(eventID),
-		uintptr(unsafe.Pointer(&_eventName[0])),
-	}
-
-	r1, _, err := procSimconnectMapClientEventToSimEvent.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf(
-			"SimConnect_MapClientEventToSimEvent for eventID %d error: %d %s",
-			eventID, r1, err,
-		)
+	err := instance.send(sentPacket{method: "MapClientEventToSimEvent"}, func(transport Transport) error {
+		return transport.MapClientEventToSimEvent(eventID, eventName)
+	})
+	if err != nil {
+		return err
 	}
 
+	instance.recordEvent(instance.clientEvents, eventID, eventName)
 	return nil
 }
 
 func (instance *SimconnectInstance) TransmitClientID(eventID uint32, data uint32) error {
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(0),
-		uintptr(eventID),
-		uintptr(data),
-		uintptr(1),
-		uintptr(0x00000010),
-	}
-
-	r1, _, err := procSimconnectTransmitClientEvent.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf(
-			"SimConnect_TransmitClientEvent for eventID %d and data %d error: %d %s",
-			eventID, data, r1, err,
-		)
-	}
-
-	return nil
+	return instance.send(sentPacket{method: "TransmitClientEvent"}, func(transport Transport) error {
+		return transport.TransmitClientEvent(0, eventID, data, 1, 0x00000010)
+	})
 }
 
 // SendText will display a text notification in the simulator.
 // Note: This will only be shown if 'Software Tips' are set to 'on' in the Assistance Options in the case of MSFS
 func (instance *SimconnectInstance) SendText(eventID uint32, duration float64, textString string) error {
-	text := []byte(textString + "\x00")
-
-	args := []uintptr{
-		uintptr(instance.handle),
-		uintptr(uint32(0x101)), // text type
-		uintptr(duration),      // duration
-		uintptr(eventID),       // event ID
-		uintptr(uint32(len(text))),
-		uintptr(unsafe.Pointer(&text[0])),
-	}
-
-	r1, _, err := procSimconnectText.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf("SimConnect_Text for eventID %d and data %s error: %d %v", eventID, textString, r1, err)
-	}
-	return nil
+	return instance.send(sentPacket{method: "Text"}, func(transport Transport) error {
+		return transport.Text(0x101, float32(duration), eventID, textString)
+	})
 }
 
-//go:embed "simconnect-data/SimConnect.dll"
-var simconnectDLLBytes []byte
-
-// NewSimConnect returns a new instance of SimConnect which will be used to call the methods.
-func NewSimConnect(simconnectName string) (*SimconnectInstance, error) {
-	dllPath := filepath.Join("simconnect-data", "SimConnect.dll")
+// NewSimConnect returns a new instance of SimConnect which will be used to call the methods. Every instance loads its
+// own copy of the SimConnect.dll functions so several instances can be open at once.
+func NewSimConnect(simconnectName string, opts ...Option) (*SimconnectInstance, error) {
+	o := newOptions(opts)
+	return newSimconnectInstance(simconnectName, func() (Transport, error) {
+		return newDLLTransport(o)
+	}, o)
+}
 
-	if _, err := os.Stat(dllPath); os.IsNotExist(err) {
-		dir, err := ioutil.TempDir("", "")
-		if err != nil {
-			return nil, err
-		}
-		dllPath = filepath.Join(dir, "SimConnect.dll")
+// NewSimConnectTCP returns a new instance of SimConnect which talks to the simulator at address (host:port) using
+// the SimConnect network protocol rather than SimConnect.dll. The simulator must be configured to listen on address
+// through its SimConnect.xml.
+func NewSimConnectTCP(simconnectName, address string, opts ...Option) (*SimconnectInstance, error) {
+	return newSimconnectInstance(simconnectName, func() (Transport, error) {
+		return newNetworkTransport(address), nil
+	}, newOptions(opts))
+}
 
-		if err := ioutil.WriteFile(dllPath, simconnectDLLBytes, 0644); err != nil {
-			return nil, err
-		}
-	}
+// NewSimConnectWithTransport returns a new instance of SimConnect which makes its calls through transport. This is
+// mostly useful with FakeTransport to test code depending on SimconnectInstance without a simulator. When
+// reconnecting, transport is closed and opened again.
+func NewSimConnectWithTransport(simconnectName string, transport Transport, opts ...Option) (*SimconnectInstance, error) {
+	return newSimconnectInstance(simconnectName, func() (Transport, error) {
+		return transport, nil
+	}, newOptions(opts))
+}
 
-	mod := syscall.NewLazyDLL(dllPath)
-	err := mod.Load()
+func newSimconnectInstance(simconnectName string, newTransport func() (Transport, error), opts options) (*SimconnectInstance, error) {
+	transport, err := newTransport()
 	if err != nil {
 		return nil, err
 	}
 
-	procSimconnectOpen = mod.NewProc("SimConnect_Open")
-	procSimconnectClose = mod.NewProc("SimConnect_Close")
-	procSimconnectRequestDataOnSimObjectType = mod.NewProc("SimConnect_RequestDataOnSimObjectType")
-	procSimconnectRequestDataOnSimObject = mod.NewProc("SimConnect_RequestDataOnSimObject")
-	procSimconnectAddtodatadefinition = mod.NewProc("SimConnect_AddToDataDefinition")
-	procSimconnectGetnextdispatch = mod.NewProc("SimConnect_GetNextDispatch")
-	procSimconnectFlightplanLoad = mod.NewProc("SimConnect_FlightPlanLoad")
-	procSimconnectAICreateParkedATCAircraft = mod.NewProc("SimConnect_AICreateParkedATCAircraft")
-	procSimconnectAICreateNonATCAircraft = mod.NewProc("SimConnect_AICreateNonATCAircraft")
-	procSimconnectSetDataOnSimObject = mod.NewProc("SimConnect_SetDataOnSimObject")
-	procSimconnectCreateEnrouteATCAircraft = mod.NewProc("SimConnect_AICreateEnrouteATCAircraft")
-	procSimconnectAISetAircraftFlightPlan = mod.NewProc("SimConnect_AISetAircraftFlightPlan")
-	procSimconnectAIRemoveObject = mod.NewProc("SimConnect_AIRemoveObject")
-	procSimconnectMapClientEventToSimEvent = mod.NewProc("SimConnect_MapClientEventToSimEvent")
-	procSimconnectSubscribeToSystemEvent = mod.NewProc("SimConnect_SubscribeToSystemEvent")
-	procSimconnectTransmitClientEvent = mod.NewProc("SimConnect_TransmitClientEvent")
-	procSimconnectText = mod.NewProc("SimConnect_Text")
-
 	instance := SimconnectInstance{
-		nextDefinitionID: 1,
-		definitionMap:    map[string]uint32{},
+		name:                   simconnectName,
+		transport:              transport,
+		nextDefinitionID:       1,
+		definitionMap:          map[reflect.Type]uint32{},
+		definitionsRegistering: map[reflect.Type]chan struct{}{},
+		definitionFields:       map[uint32][]definitionField{},
+		definitionTypes:        map[uint32]reflect.Type{},
+		rejectedFields:         map[uint32]map[int]*FieldError{},
+		dropRejectedFields:     opts.dropRejectedFields,
+		simVarWarning:          opts.simVarWarning,
+		settableChecked:        map[uint32]bool{},
+		dynamicRefs:            map[reflect.Type]int{},
+		systemEvents:           map[uint32]string{},
+		systemEventStates:      map[uint32]uint32{},
+		eventHandlers:          map[uint32]func(){},
+		clientEvents:           map[uint32]string{},
+		subscriptions:          map[uint32]subscription{},
+		newTransport:           newTransport,
+		reconnectBackoff:       opts.reconnectBackoff,
+		maxReconnectBackoff:    opts.maxReconnectBackoff,
+		requestWaiters:         map[uint32]chan dispatchResult{},
+		overflowed:             map[uint32]bool{},
+		sentPackets:            map[uint32]sentPacket{},
+		listeners:              map[*listener]struct{}{},
+		stateWatchers:          map[chan ConnectionStateChange]struct{}{},
+		nextRequestID:          firstInternalRequestID,
+		nextEventID:            firstInternalEventID,
+		timeout:                DefaultTimeout,
 	}
 
 	err = instance.openConnection(simconnectName)
 	if err != nil {
+		// Release whatever the transport set up before failing, such as an extracted DLL
+		transport.Close()
 		return nil, err
 	}
 
 	err = instance.processConnectionOpenData()
 	if err != nil {
+		instance.closeConnection()
 		return nil, err
 	}
 
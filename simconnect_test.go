@@ -1,20 +1,59 @@
 package simconnect
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strconv"
 	"testing"
 	"time"
-	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/JRascagneres/Simconnect-Go/emulator"
 	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
 )
 
+// liveSim reports whether the tests should talk to a running simulator through SimConnect.dll rather than to the
+// emulator.
+func liveSim() bool {
+	return os.Getenv("SIMCONNECT_LIVE") != ""
+}
+
+// newTestSimConnect connects to the running simulator when SIMCONNECT_LIVE is set, otherwise to an emulator started
+// for the test. The instance is closed when the test ends.
+func newTestSimConnect(t *testing.T, simconnectName string) (*SimconnectInstance, error) {
+	var instance *SimconnectInstance
+	var err error
+	if liveSim() {
+		instance, err = NewSimConnect(simconnectName)
+	} else {
+		server, startErr := emulator.Start("127.0.0.1:0", emulator.Config{})
+		require.NoError(t, startErr)
+		t.Cleanup(func() { server.Close() })
+
+		instance, err = NewSimConnectTCP(simconnectName, server.Addr())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Cleanups run last in first, so the instance is closed before the emulator it is connected to
+	t.Cleanup(func() { instance.Close() })
+	return instance, nil
+}
+
+// waitForSim pauses so the effect of a call can be watched in the simulator. The emulator applies changes
+// immediately so there is nothing to wait for.
+func waitForSim(d time.Duration) {
+	if liveSim() {
+		time.Sleep(d)
+	}
+}
+
 func TestExample(t *testing.T) {
-	instance, err := NewSimConnect("data")
+	instance, err := newTestSimConnect(t, "data")
 	require.NoError(t, err)
 
 	report, err := instance.GetReport()
@@ -33,7 +72,7 @@ func TestExample(t *testing.T) {
 
 // These aren't 'real' tests. This is simply for testing easily within the game.
 func TestWork(t *testing.T) {
-	instance, _ := NewSimConnect("data")
+	instance, _ := newTestSimConnect(t, "data")
 
 	instance.GetReport()
 
@@ -49,7 +88,7 @@ func TestWork(t *testing.T) {
 		Pitch:     0,
 	}, i)
 
-	time.Sleep(10 * time.Second)
+	waitForSim(10 * time.Second)
 
 	instance.SetDataOnSimObject(*objID, []SetSimObjectDataExpose{{
 		Airspeed:  200,
@@ -64,55 +103,52 @@ func TestWork(t *testing.T) {
 
 	data, _ := instance.GetReportOnObjectID(*objID)
 	fmt.Println(data.Altitude)
-	time.Sleep(10 * time.Second)
+	waitForSim(10 * time.Second)
 }
 
 func TestWork2(t *testing.T) {
-	instance, err := NewSimConnect("data")
+	instance, err := newTestSimConnect(t, "data")
 	require.NoError(t, err)
 
 	objID, err := instance.LoadParkedATCAircraft("Boeing 747-8i Asobo", "G-420", "EGCC", 100)
 	require.NoError(t, err)
 
-	time.Sleep(5 * time.Second)
+	waitForSim(5 * time.Second)
 
 	err = instance.SetAircraftFlightPlan(*objID, 1000, "C:\\Users\\Jacques\\Desktop\\EGCCLFPG")
 	require.NoError(t, err)
 
 	data, _ := instance.GetReportOnObjectID(*objID)
 
-	time.Sleep(5 * time.Second)
+	waitForSim(5 * time.Second)
 
 	err = instance.RemoveAIObject(*objID, 10001)
 	require.NoError(t, err)
 
-	time.Sleep(60 * time.Second)
+	waitForSim(60 * time.Second)
 
 	fmt.Println(data.Altitude)
-	time.Sleep(10 * time.Second)
+	waitForSim(10 * time.Second)
 }
 
 func TestSystemEvent(t *testing.T) {
-	instance, err := NewSimConnect("test")
+	instance, err := newTestSimConnect(t, "test")
 	require.NoError(t, err)
 
 	err = instance.SubscribeToSystemEvent(10, "4sec")
 	assert.NoError(t, err)
 
-	dataChan, errChan := instance.processEventData(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	if data, open := <-dataChan; open {
+	if data, open := <-instance.Events(ctx); open {
 		fmt.Println(data)
 	}
 
-	if err, open := <-errChan; open {
-		fmt.Println(err)
-	}
-
 }
 
 func TestRadioSet(t *testing.T) {
-	instance, err := NewSimConnect(t.Name())
+	instance, err := newTestSimConnect(t, t.Name())
 	require.NoError(t, err)
 
 	report, err := instance.GetReport()
@@ -150,15 +186,15 @@ func TestRadioSet(t *testing.T) {
 
 	err = instance.TransmitClientID(events.Plus, 0)
 	require.NoError(t, err)
-	time.Sleep(2 * time.Second)
+	waitForSim(2 * time.Second)
 
 	err = instance.TransmitClientID(events.Minus, 0)
 	require.NoError(t, err)
-	time.Sleep(2 * time.Second)
+	waitForSim(2 * time.Second)
 }
 
 func TestAPAltitude(t *testing.T) {
-	instance, err := NewSimConnect(t.Name())
+	instance, err := newTestSimConnect(t, t.Name())
 	require.NoError(t, err)
 
 	type Events struct {
@@ -174,11 +210,11 @@ func TestAPAltitude(t *testing.T) {
 
 	err = instance.TransmitClientID(events.APAlt, 100)
 	require.NoError(t, err)
-	time.Sleep(2 * time.Second)
+	waitForSim(2 * time.Second)
 }
 
 func TestAPReport(t *testing.T) {
-	instance, err := NewSimConnect(t.Name())
+	instance, err := newTestSimConnect(t, t.Name())
 	require.NoError(t, err)
 
 	apReport, err := instance.GetAPReport()
@@ -194,28 +230,17 @@ func TestCustomReport(t *testing.T) {
 		Altitude float64   `name:"AUTOPILOT ALTITUDE LOCK VAR" unit:"feet"`
 	}
 
-	instance, err := NewSimConnect(t.Name())
-	require.NoError(t, err)
-
-	cReport := &CustomReport{}
-	err = instance.registerDataDefinition(cReport)
-	require.NoError(t, err)
-
-	definitionID, _ := instance.getDefinitionID(cReport)
-	err = instance.requestDataOnSimObjectType(definitionID, definitionID, 0, simconnect_data.SIMOBJECT_TYPE_USER)
+	instance, err := newTestSimConnect(t, t.Name())
 	require.NoError(t, err)
 
-	reportData, err := instance.processSimObjectTypeData()
+	cReport, err := GetByType[CustomReport](instance, simconnect_data.SIMOBJECT_TYPE_USER)
 	require.NoError(t, err)
 
-	ptr := reportData.(unsafe.Pointer)
-	cReport = (*CustomReport)(ptr)
-
 	fmt.Println(cReport.Altitude)
 }
 
 func TestReport(t *testing.T) {
-	instance, err := NewSimConnect(t.Name())
+	instance, err := newTestSimConnect(t, t.Name())
 	require.NoError(t, err)
 
 	report, err := instance.GetReport()
@@ -225,7 +250,7 @@ func TestReport(t *testing.T) {
 }
 
 func TestMessage(t *testing.T) {
-	instance, err := NewSimConnect(t.Name() + time.Now().String())
+	instance, err := newTestSimConnect(t, t.Name()+time.Now().String())
 	require.NoError(t, err)
 
 	err = instance.SendText(1, 1, time.Now().String())
@@ -0,0 +1,162 @@
+package simconnect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// SubscribeOptions are the optional parameters of SimConnect_RequestDataOnSimObject used by Subscribe.
+type SubscribeOptions struct {
+	// Flags is a combination of the DATA_REQUEST_FLAG_* flags. With DATA_REQUEST_FLAG_CHANGED data is only sent
+	// once it changes by more than the epsilon of a field, and with DATA_REQUEST_FLAG_TAGGED as well only the fields
+	// which changed are sent, the others keeping their previous value. Tagged data needs every field to have a datum
+	// tag.
+	Flags uint32
+	// Origin is the number of periods to wait before the first data is sent
+	Origin uint32
+	// Interval is the number of periods to skip between each data sent
+	Interval uint32
+	// Limit is the number of times data is sent before it stops, zero for no limit
+	Limit uint32
+	// OnError is called from the goroutine of the subscription with each message which can not be decoded, which is
+	// skipped, and with the exception closing the subscription if the simulator rejects the request. It must not
+	// block. Errors are dropped when it is nil.
+	OnError func(error)
+}
+
+// subscription is a periodic request, kept so it can be made again after reconnecting.
+type subscription struct {
+	definitionID uint32
+	objectID     uint32
+	period       uint32
+	options      SubscribeOptions
+}
+
+// Subscribe requests the data described by the tags of T for objectID every period, one of the SIMCONNECT_PERIOD_*
+// constants, and sends it on the channel returned. See Get for the requirements on T. The request is stopped with
+// SIMCONNECT_PERIOD_NEVER and the channel closed once ctx is done, or when the simulator rejects the request or the
+// instance stops. Data arriving faster than it is received from the channel is dropped. Errors are passed to
+// opts.OnError.
+func Subscribe[T any](ctx context.Context, instance *SimconnectInstance, objectID, period uint32, opts SubscribeOptions) (<-chan ObjectData[T], error) {
+	if opts.Flags&simconnect_data.DATA_REQUEST_FLAG_TAGGED != 0 {
+		if err := checkDatumIDs(reflect.TypeOf(new(T)).Elem()); err != nil {
+			return nil, err
+		}
+	}
+
+	definitionID, err := definitionFor[T](instance)
+	if err != nil {
+		return nil, err
+	}
+	requestID := instance.newRequestID()
+
+	results, err := instance.watchRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := subscription{definitionID: definitionID, objectID: objectID, period: period, options: opts}
+	if err := instance.subscribe(requestID, sub); err != nil {
+		instance.unwatchRequest(requestID)
+		return nil, fmt.Errorf("error sending request %d: %w", requestID, err)
+	}
+
+	values := make(chan ObjectData[T], listenerBufferSize)
+	go func() {
+		defer close(values)
+		defer instance.unsubscribe(requestID, sub)
+
+		// Tagged data only carries the fields which changed, so it is applied on top of the previous value
+		var object ObjectData[T]
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case result, ok := <-results:
+				if !ok {
+					return
+				}
+				if errors.Is(result.err, ErrDisconnected) {
+					// Made again once reconnected
+					continue
+				}
+				if result.err != nil {
+					opts.reportError(result.err)
+					return
+				}
+				next := object
+				if err := next.decode(instance, result.data); err != nil {
+					opts.reportError(fmt.Errorf("error decoding data of request %d: %w", requestID, err))
+					continue
+				}
+				object = next
+
+				select {
+				case values <- object:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return values, nil
+}
+
+func (opts SubscribeOptions) reportError(err error) {
+	if opts.OnError != nil {
+		opts.OnError(err)
+	}
+}
+
+func (instance *SimconnectInstance) subscribe(requestID uint32, sub subscription) error {
+	err := instance.send(sentPacket{method: "RequestDataOnSimObject", request: true, requestID: requestID}, func(transport Transport) error {
+		return transport.RequestDataOnSimObject(requestID, sub.definitionID, sub.objectID, sub.period, sub.options.Flags, sub.options.Origin, sub.options.Interval, sub.options.Limit)
+	})
+	if err != nil {
+		return err
+	}
+
+	instance.eventMapMutex.Lock()
+	defer instance.eventMapMutex.Unlock()
+
+	instance.subscriptions[requestID] = sub
+	return nil
+}
+
+// unsubscribe stops the request made by subscribe, unless the connection is already closed.
+func (instance *SimconnectInstance) unsubscribe(requestID uint32, sub subscription) {
+	instance.unwatchRequest(requestID)
+
+	instance.eventMapMutex.Lock()
+	delete(instance.subscriptions, requestID)
+	instance.eventMapMutex.Unlock()
+
+	instance.call(instance.unlessStopped(func(transport Transport) error {
+		return transport.RequestDataOnSimObject(requestID, sub.definitionID, sub.objectID, simconnect_data.SIMCONNECT_PERIOD_NEVER, 0, 0, 0, 0)
+	}))
+}
+
+// checkDatumIDs checks every field of t has its own datum ID, without which tagged data can not be decoded.
+func checkDatumIDs(t reflect.Type) error {
+	fields, err := parseDefinitionFields(t)
+	if err != nil {
+		return err
+	}
+
+	seen := map[uint32]string{}
+	for _, field := range fields {
+		if field.datumID == simconnect_data.UNUSED {
+			return fmt.Errorf("field %s needs a datum tag for tagged data", field.fieldName)
+		}
+		if other, ok := seen[field.datumID]; ok {
+			return fmt.Errorf("fields %s and %s have the same datum ID %d", other, field.fieldName, field.datumID)
+		}
+		seen[field.datumID] = field.fieldName
+	}
+	return nil
+}
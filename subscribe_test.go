@@ -0,0 +1,193 @@
+package simconnect
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+type altitudeReport struct {
+	Altitude float64 `name:"Plane Altitude" unit:"feet"`
+}
+
+// receive returns the next value sent on values, failing the test if none arrives in time.
+func receive[T any](t *testing.T, values <-chan ObjectData[T]) ObjectData[T] {
+	t.Helper()
+	select {
+	case value, ok := <-values:
+		require.True(t, ok, "subscription closed")
+		return value
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no data received")
+	}
+	return ObjectData[T]{}
+}
+
+func TestSubscribe(t *testing.T) {
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	values, err := Subscribe[positionReport](ctx, instance, simconnect_data.OBJECT_ID_USER, simconnect_data.SIMCONNECT_PERIOD_SIM_FRAME, SubscribeOptions{})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		value := receive(t, values)
+		assert.Equal(t, simconnect_data.OBJECT_ID_USER, value.ObjectID)
+		assert.NotZero(t, value.Data.Latitude)
+	}
+
+	cancel()
+	for range values {
+	}
+}
+
+func TestSubscribeChanged(t *testing.T) {
+	type changedReport struct {
+		Altitude float64 `name:"Plane Altitude" unit:"feet" epsilon:"10" datum:"1"`
+		Latitude float64 `name:"Plane Latitude" unit:"degrees" datum:"2"`
+	}
+
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := SubscribeOptions{Flags: simconnect_data.DATA_REQUEST_FLAG_CHANGED | simconnect_data.DATA_REQUEST_FLAG_TAGGED}
+	values, err := Subscribe[changedReport](ctx, instance, simconnect_data.OBJECT_ID_USER, simconnect_data.SIMCONNECT_PERIOD_SIM_FRAME, opts)
+	require.NoError(t, err)
+
+	first := receive(t, values)
+	require.NotZero(t, first.Data.Latitude)
+
+	// A change within the epsilon is not sent, the one after it is
+	require.NoError(t, Set(instance, simconnect_data.OBJECT_ID_USER, altitudeReport{first.Data.Altitude + 5}))
+	waitForSim(time.Second)
+	require.NoError(t, Set(instance, simconnect_data.OBJECT_ID_USER, altitudeReport{first.Data.Altitude + 100}))
+
+	for {
+		value := receive(t, values)
+		// Fields left out of tagged data keep their previous value
+		assert.NotZero(t, value.Data.Latitude)
+		if value.Data.Altitude != first.Data.Altitude {
+			assert.Equal(t, first.Data.Altitude+100, value.Data.Altitude)
+			break
+		}
+	}
+}
+
+func TestSubscribeOptions(t *testing.T) {
+	fake := NewFakeTransport()
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := SubscribeOptions{Flags: simconnect_data.DATA_REQUEST_FLAG_CHANGED, Origin: 2, Interval: 3, Limit: 4}
+	values, err := Subscribe[altitudeReport](ctx, instance, 7, simconnect_data.SIMCONNECT_PERIOD_SECOND, opts)
+	require.NoError(t, err)
+
+	calls := fake.CallsTo("RequestDataOnSimObject")
+	require.Len(t, calls, 1)
+	requestID, definitionID := calls[0].Args[0], calls[0].Args[1]
+	assert.Equal(t, []interface{}{
+		requestID, definitionID, uint32(7), simconnect_data.SIMCONNECT_PERIOD_SECOND,
+		simconnect_data.DATA_REQUEST_FLAG_CHANGED, uint32(2), uint32(3), uint32(4),
+	}, calls[0].Args)
+
+	data := struct {
+		simconnect_data.RecvSimobjectDataByType
+		Altitude float64
+	}{Altitude: 1500}
+	data.RequestID = requestID.(uint32)
+	data.ObjectID = 7
+	data.DefineID = definitionID.(uint32)
+	data.DefineCount = 1
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA, &data))
+
+	value := receive(t, values)
+	assert.Equal(t, ObjectData[altitudeReport]{ObjectID: 7, Data: altitudeReport{1500}}, value)
+
+	// Cancelling stops the request and closes the channel
+	cancel()
+	for range values {
+	}
+	calls = fake.CallsTo("RequestDataOnSimObject")
+	require.Len(t, calls, 2)
+	assert.Equal(t, []interface{}{
+		requestID, definitionID, uint32(7), simconnect_data.SIMCONNECT_PERIOD_NEVER,
+		uint32(0), uint32(0), uint32(0), uint32(0),
+	}, calls[1].Args)
+}
+
+func TestSubscribeTaggedNeedsDatumIDs(t *testing.T) {
+	instance, err := NewSimConnectWithTransport(t.Name(), NewFakeTransport())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	opts := SubscribeOptions{Flags: simconnect_data.DATA_REQUEST_FLAG_TAGGED}
+	_, err = Subscribe[altitudeReport](context.Background(), instance, simconnect_data.OBJECT_ID_USER, simconnect_data.SIMCONNECT_PERIOD_SECOND, opts)
+	assert.EqualError(t, err, "field Altitude needs a datum tag for tagged data")
+}
+
+func TestSubscribeErrors(t *testing.T) {
+	fake := NewFakeTransport()
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	errs := make(chan error, 4)
+	opts := SubscribeOptions{OnError: func(err error) { errs <- err }}
+	values, err := Subscribe[altitudeReport](context.Background(), instance, 7, simconnect_data.SIMCONNECT_PERIOD_SECOND, opts)
+	require.NoError(t, err)
+
+	calls := fake.CallsTo("RequestDataOnSimObject")
+	require.Len(t, calls, 1)
+	requestID, definitionID := calls[0].Args[0].(uint32), calls[0].Args[1].(uint32)
+
+	// Data missing its field is reported and skipped
+	short := simconnect_data.RecvSimobjectDataByType{}
+	short.RequestID = requestID
+	short.ObjectID = 7
+	short.DefineID = definitionID
+	short.DefineCount = 1
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA, &short))
+	data := struct {
+		simconnect_data.RecvSimobjectDataByType
+		Altitude float64
+	}{RecvSimobjectDataByType: short, Altitude: 1500}
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA, &data))
+
+	select {
+	case err := <-errs:
+		assert.Contains(t, err.Error(), fmt.Sprintf("error decoding data of request %d", requestID))
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no error reported")
+	}
+	assert.Equal(t, altitudeReport{1500}, receive(t, values).Data)
+
+	// The exception rejecting the request is reported before the channel is closed
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_EXCEPTION, &simconnect_data.RecvException{
+		Exception: simconnect_data.EXCEPTION_ERROR,
+		SendID:    calls[0].SendID,
+	}))
+	for range values {
+	}
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrException)
+	default:
+		assert.Fail(t, "no exception reported")
+	}
+}
@@ -0,0 +1,411 @@
+package simconnect
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// maxRadius is the largest radius in meters the simulator accepts for requests by type.
+const maxRadius = 200000
+
+// TrafficObject is an AI or multiplayer object followed by a TrafficTracker.
+type TrafficObject struct {
+	ObjectID    uint32
+	Type        uint32 // SIMOBJECT_TYPE_*
+	Title       string
+	ATCID       string
+	Latitude    float64 // degrees
+	Longitude   float64 // degrees
+	Altitude    float64 // feet
+	Heading     float64 // degrees true
+	GroundSpeed float64 // knots
+	LastSeen    time.Time
+}
+
+// trafficData is the data requested for each TrafficObject.
+type trafficData struct {
+	Title       string  `name:"Title"`
+	ATCID       string  `name:"ATC ID"`
+	Latitude    float64 `name:"Plane Latitude" unit:"degrees"`
+	Longitude   float64 `name:"Plane Longitude" unit:"degrees"`
+	Altitude    float64 `name:"Plane Altitude" unit:"feet"`
+	Heading     float64 `name:"Plane Heading Degrees True" unit:"degrees"`
+	GroundSpeed float64 `name:"Ground Velocity" unit:"knots"`
+}
+
+// TrafficChangeKind is what happened to the object of a TrafficChange.
+type TrafficChangeKind int
+
+const (
+	TrafficAdded TrafficChangeKind = iota
+	TrafficUpdated
+	TrafficRemoved
+)
+
+func (kind TrafficChangeKind) String() string {
+	switch kind {
+	case TrafficAdded:
+		return "added"
+	case TrafficUpdated:
+		return "updated"
+	case TrafficRemoved:
+		return "removed"
+	}
+	return fmt.Sprintf("TrafficChangeKind(%d)", int(kind))
+}
+
+// TrafficChange is sent by a TrafficTracker each time an object is added, updated or removed. Object is its last
+// known state.
+type TrafficChange struct {
+	Kind   TrafficChangeKind
+	Object TrafficObject
+}
+
+// TrafficOptions configures a TrafficTracker. Zero values pick the defaults.
+type TrafficOptions struct {
+	// Types are the SIMOBJECT_TYPE_* tracked, by default aircraft, helicopters, boats and ground vehicles
+	Types []uint32
+	// Radius in meters around the user's aircraft, by default the largest the simulator allows
+	Radius uint32
+	// Interval between updates of every object, by default a second
+	Interval time.Duration
+	// StaleAfter is how long an object is kept without being seen, by default three intervals
+	StaleAfter time.Duration
+}
+
+// TrafficTracker keeps the set of AI and multiplayer objects around the user's aircraft up to date. Objects are added
+// and removed as the simulator reports them through the ObjectAdded and ObjectRemoved system events, and all of them
+// are requested by type every interval. Objects which are no longer seen are pruned once stale.
+type TrafficTracker struct {
+	instance *SimconnectInstance
+	options  TrafficOptions
+	changes  chan TrafficChange
+
+	mutex   sync.Mutex
+	objects map[uint32]TrafficObject
+	userID  uint32
+
+	// Objects removed while data is being fetched are remembered, so the data does not bring them back. removedAt
+	// holds the number of removals counted when each was removed, and is emptied once no fetch is in flight.
+	removals  uint64
+	removedAt map[uint32]uint64
+	fetches   int
+}
+
+// NewTrafficTracker starts tracking the objects around the user's aircraft until ctx is done.
+func NewTrafficTracker(ctx context.Context, instance *SimconnectInstance, opts TrafficOptions) (*TrafficTracker, error) {
+	if len(opts.Types) == 0 {
+		opts.Types = []uint32{
+			simconnect_data.SIMOBJECT_TYPE_AIRCRAFT,
+			simconnect_data.SIMOBJECT_TYPE_HELICOPTER,
+			simconnect_data.SIMOBJECT_TYPE_BOAT,
+			simconnect_data.SIMOBJECT_TYPE_GROUND,
+		}
+	}
+	if opts.Radius == 0 {
+		opts.Radius = maxRadius
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.StaleAfter <= 0 {
+		opts.StaleAfter = 3 * opts.Interval
+	}
+
+	tracker := &TrafficTracker{
+		instance: instance,
+		options:  opts,
+		changes:  make(chan TrafficChange, listenerBufferSize),
+		objects:  map[uint32]TrafficObject{},
+
+		removedAt: map[uint32]uint64{},
+	}
+
+	addedID, removedID := instance.newEventID(), instance.newEventID()
+	added := instance.listen(addedID, true, false)
+	removed := instance.listen(removedID, true, false)
+	stop := func() {
+		instance.UnsubscribeFromSystemEvent(addedID)
+		instance.UnsubscribeFromSystemEvent(removedID)
+		instance.unlisten(added)
+		instance.unlisten(removed)
+	}
+
+	if err := instance.SubscribeToSystemEvent(addedID, "ObjectAdded"); err != nil {
+		stop()
+		return nil, fmt.Errorf("error subscribing to ObjectAdded: %v", err)
+	}
+	if err := instance.SubscribeToSystemEvent(removedID, "ObjectRemoved"); err != nil {
+		stop()
+		return nil, fmt.Errorf("error subscribing to ObjectRemoved: %v", err)
+	}
+
+	go func() {
+		defer close(tracker.changes)
+		defer stop()
+
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+
+		// Polls run beside the loop so that events are handled while waiting for the simulator, one at a time
+		polled := make(chan struct{}, 1)
+		polling := false
+		startPoll := func() {
+			if polling {
+				return
+			}
+			polling = true
+			go func() {
+				tracker.poll(ctx)
+				polled <- struct{}{}
+			}()
+		}
+		// Added objects are fetched beside the loop as well, one at a time in the order they were added. The fetch
+		// counts from when the object was added, so a removal while it is queued is not missed.
+		var queued []queuedObject
+		fetched := make(chan struct{}, 1)
+		fetching := false
+		fetchNext := func() {
+			if fetching || len(queued) == 0 {
+				return
+			}
+			object := queued[0]
+			queued = queued[1:]
+			fetching = true
+			go func() {
+				tracker.objectAdded(ctx, object)
+				fetched <- struct{}{}
+			}()
+		}
+		// Changes must not be sent by a poll or fetch once closed
+		defer func() {
+			if polling {
+				<-polled
+			}
+			if fetching {
+				<-fetched
+			}
+		}()
+
+		startPoll()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-polled:
+				polling = false
+			case <-fetched:
+				fetching = false
+				fetchNext()
+			case result := <-added.results:
+				if result.err != nil {
+					return
+				}
+				event, err := simconnect_data.DecodeRecvEventObjectAddRemove(result.data)
+				if err != nil || !tracker.tracks(event.ObjType) {
+					continue
+				}
+				queued = append(queued, queuedObject{event: *event, since: tracker.startFetch()})
+				fetchNext()
+			case result := <-removed.results:
+				if result.err != nil {
+					return
+				}
+				tracker.objectRemoved(result.data)
+			case <-ticker.C:
+				startPoll()
+			}
+		}
+	}()
+
+	return tracker, nil
+}
+
+// Changes returns the channel on which every change is sent. It is closed once the tracker stops. Changes are dropped
+// when it is not received from fast enough, Objects still being up to date.
+func (tracker *TrafficTracker) Changes() <-chan TrafficChange {
+	return tracker.changes
+}
+
+// Objects returns the objects currently tracked, by object ID.
+func (tracker *TrafficTracker) Objects() map[uint32]TrafficObject {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	objects := make(map[uint32]TrafficObject, len(tracker.objects))
+	for id, object := range tracker.objects {
+		objects[id] = object
+	}
+	return objects
+}
+
+// Object returns the object with the given ID, if tracked.
+func (tracker *TrafficTracker) Object(objectID uint32) (TrafficObject, bool) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	object, ok := tracker.objects[objectID]
+	return object, ok
+}
+
+// poll requests every object of the tracked types and prunes those which were not seen for too long. Nothing is
+// pruned when any request fails or times out, as the objects missing may only have gone unreported, so the objects
+// tracked are kept until a later poll succeeds.
+func (tracker *TrafficTracker) poll(ctx context.Context) {
+	complete := true
+	since := tracker.startFetch()
+	defer tracker.endFetch()
+
+	// The user's aircraft is returned along with the AI aircraft, and its ID may change after reconnecting
+	user, err := GetObjectByTypeContext[trafficData](ctx, tracker.instance, simconnect_data.SIMOBJECT_TYPE_USER)
+	if err == nil {
+		tracker.mutex.Lock()
+		tracker.userID = user.ObjectID
+		tracker.mutex.Unlock()
+	} else {
+		complete = false
+	}
+
+	for _, objectType := range tracker.options.Types {
+		objects, err := GetObjectsInRadiusContext[trafficData](ctx, tracker.instance, tracker.options.Radius, objectType)
+		if err != nil {
+			complete = false
+			continue
+		}
+		now := time.Now()
+		for objectID, data := range objects {
+			tracker.update(objectID, objectType, data, now, since)
+		}
+	}
+
+	if complete {
+		tracker.prune(time.Now())
+	}
+}
+
+// queuedObject is an object added, waiting for its data to be fetched since the given number of removals.
+type queuedObject struct {
+	event simconnect_data.RecvEventObjectAddRemove
+	since uint64
+}
+
+// objectAdded fetches the data of the object queued.
+func (tracker *TrafficTracker) objectAdded(ctx context.Context, queued queuedObject) {
+	defer tracker.endFetch()
+
+	object, err := GetObjectContext[trafficData](ctx, tracker.instance, queued.event.Data)
+	if err != nil {
+		// Removed again already, or not sent until the next poll
+		return
+	}
+	tracker.update(queued.event.Data, queued.event.ObjType, object.Data, time.Now(), queued.since)
+}
+
+// startFetch must be called before requesting the data of objects, and returns the number of removals to pass to
+// update with the data. endFetch must be called once done.
+func (tracker *TrafficTracker) startFetch() uint64 {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	tracker.fetches++
+	return tracker.removals
+}
+
+func (tracker *TrafficTracker) endFetch() {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	tracker.fetches--
+	if tracker.fetches == 0 {
+		tracker.removedAt = map[uint32]uint64{}
+	}
+}
+
+func (tracker *TrafficTracker) objectRemoved(data []byte) {
+	event, err := simconnect_data.DecodeRecvEventObjectAddRemove(data)
+	if err != nil {
+		return
+	}
+
+	tracker.mutex.Lock()
+	object, ok := tracker.objects[event.Data]
+	delete(tracker.objects, event.Data)
+	tracker.removals++
+	if tracker.fetches > 0 {
+		tracker.removedAt[event.Data] = tracker.removals
+	}
+	tracker.mutex.Unlock()
+
+	if ok {
+		tracker.notify(TrafficChange{Kind: TrafficRemoved, Object: object})
+	}
+}
+
+func (tracker *TrafficTracker) tracks(objectType uint32) bool {
+	for _, t := range tracker.options.Types {
+		if t == objectType {
+			return true
+		}
+	}
+	return false
+}
+
+// update records the latest data seen for objectID, fetched from when the number of removals was since. The data is
+// dropped when the object has been removed since.
+func (tracker *TrafficTracker) update(objectID, objectType uint32, data trafficData, now time.Time, since uint64) {
+	tracker.mutex.Lock()
+	if objectID == tracker.userID || tracker.removedAt[objectID] > since {
+		tracker.mutex.Unlock()
+		return
+	}
+	_, known := tracker.objects[objectID]
+	object := TrafficObject{
+		ObjectID:    objectID,
+		Type:        objectType,
+		Title:       data.Title,
+		ATCID:       data.ATCID,
+		Latitude:    data.Latitude,
+		Longitude:   data.Longitude,
+		Altitude:    data.Altitude,
+		Heading:     data.Heading,
+		GroundSpeed: data.GroundSpeed,
+		LastSeen:    now,
+	}
+	tracker.objects[objectID] = object
+	tracker.mutex.Unlock()
+
+	kind := TrafficAdded
+	if known {
+		kind = TrafficUpdated
+	}
+	tracker.notify(TrafficChange{Kind: kind, Object: object})
+}
+
+// prune removes the objects not seen since StaleAfter before now.
+func (tracker *TrafficTracker) prune(now time.Time) {
+	var stale []TrafficObject
+
+	tracker.mutex.Lock()
+	for objectID, object := range tracker.objects {
+		if now.Sub(object.LastSeen) > tracker.options.StaleAfter {
+			stale = append(stale, object)
+			delete(tracker.objects, objectID)
+		}
+	}
+	tracker.mutex.Unlock()
+
+	for _, object := range stale {
+		tracker.notify(TrafficChange{Kind: TrafficRemoved, Object: object})
+	}
+}
+
+func (tracker *TrafficTracker) notify(change TrafficChange) {
+	select {
+	case tracker.changes <- change:
+	default:
+	}
+}
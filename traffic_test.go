@@ -0,0 +1,303 @@
+package simconnect
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// nextChange returns the next change of kind sent by tracker, failing the test if none arrives in time.
+func nextChange(t *testing.T, tracker *TrafficTracker, kind TrafficChangeKind) TrafficChange {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case change, ok := <-tracker.Changes():
+			require.True(t, ok, "tracker stopped")
+			if change.Kind == kind {
+				return change
+			}
+		case <-timeout:
+			require.FailNow(t, "no change received", "kind %s", kind)
+		}
+	}
+}
+
+func TestTrafficTracker(t *testing.T) {
+	instance, err := newTestSimConnect(t, t.Name())
+	require.NoError(t, err)
+	defer instance.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracker, err := NewTrafficTracker(ctx, instance, TrafficOptions{Interval: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	user, err := GetObject[positionReport](instance, simconnect_data.OBJECT_ID_USER)
+	require.NoError(t, err)
+
+	objectID, err := instance.LoadNonATCAircraft("Boeing 747-8i Asobo", "G-TRFC", simconnect_data.SimconnectDataInitPosition{
+		Latitude:  user.Data.Latitude + 0.01,
+		Longitude: user.Data.Longitude,
+		Altitude:  3000,
+		Airspeed:  200,
+	}, 1100)
+	require.NoError(t, err)
+
+	added := nextChange(t, tracker, TrafficAdded)
+	assert.Equal(t, *objectID, added.Object.ObjectID)
+	assert.Equal(t, simconnect_data.SIMOBJECT_TYPE_AIRCRAFT, added.Object.Type)
+	assert.Equal(t, "G-TRFC", added.Object.ATCID)
+	assert.InDelta(t, user.Data.Latitude+0.01, added.Object.Latitude, 0.01)
+
+	updated := nextChange(t, tracker, TrafficUpdated)
+	assert.Equal(t, *objectID, updated.Object.ObjectID)
+	assert.True(t, updated.Object.LastSeen.After(added.Object.LastSeen))
+
+	// The user's aircraft is not traffic
+	_, ok := tracker.Object(user.ObjectID)
+	assert.False(t, ok)
+	assert.Contains(t, tracker.Objects(), *objectID)
+
+	require.NoError(t, instance.RemoveAIObject(*objectID, 1101))
+	removed := nextChange(t, tracker, TrafficRemoved)
+	assert.Equal(t, *objectID, removed.Object.ObjectID)
+	assert.NotContains(t, tracker.Objects(), *objectID)
+
+	cancel()
+	for range tracker.Changes() {
+	}
+}
+
+func TestTrafficTrackerPrunesStale(t *testing.T) {
+	type trafficMessage struct {
+		simconnect_data.RecvSimobjectDataByType
+		Title       [1]byte // Empty variable length string
+		ATCID       [3]byte // "N5" and its terminating null
+		Latitude    float64
+		Longitude   float64
+		Altitude    float64
+		Heading     float64
+		GroundSpeed float64
+	}
+
+	// Object 5 is only returned by the first request for aircraft, as if it went out of range without being removed
+	requests := 0
+	fake := NewFakeTransport()
+	fake.On("RequestDataOnSimObjectType", func(fake *FakeTransport, call FakeCall) error {
+		data := trafficMessage{Altitude: 1000}
+		data.RequestID = call.Args[0].(uint32)
+		data.DefineID = call.Args[1].(uint32)
+		data.EntryNumber = 1
+		data.OutOf = 1
+		data.DefineCount = 7
+
+		switch call.Args[3] {
+		case simconnect_data.SIMOBJECT_TYPE_USER:
+			data.ObjectID = 1
+		case simconnect_data.SIMOBJECT_TYPE_AIRCRAFT:
+			requests++
+			if requests > 1 {
+				data.EntryNumber, data.OutOf = 0, 0
+				break
+			}
+			data.ObjectID = 5
+			copy(data.ATCID[:], "N5")
+		}
+		return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, &data)
+	})
+
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracker, err := NewTrafficTracker(ctx, instance, TrafficOptions{
+		Types:      []uint32{simconnect_data.SIMOBJECT_TYPE_AIRCRAFT},
+		Interval:   50 * time.Millisecond,
+		StaleAfter: 200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	added := nextChange(t, tracker, TrafficAdded)
+	assert.Equal(t, uint32(5), added.Object.ObjectID)
+	assert.Equal(t, "N5", added.Object.ATCID)
+
+	removed := nextChange(t, tracker, TrafficRemoved)
+	assert.Equal(t, uint32(5), removed.Object.ObjectID)
+	assert.Empty(t, tracker.Objects())
+
+	subscribed := fake.CallsTo("SubscribeToSystemEvent")
+	require.Len(t, subscribed, 2)
+	assert.Equal(t, "ObjectAdded", subscribed[0].Args[1])
+	assert.Equal(t, "ObjectRemoved", subscribed[1].Args[1])
+}
+
+func TestTrafficTrackerKeepsObjectsWhenPollFails(t *testing.T) {
+	type trafficMessage struct {
+		simconnect_data.RecvSimobjectDataByType
+		Title       [1]byte
+		ATCID       [3]byte
+		Latitude    float64
+		Longitude   float64
+		Altitude    float64
+		Heading     float64
+		GroundSpeed float64
+	}
+
+	// Object 5 is returned by the first request for aircraft, and every later one fails
+	var requestsMutex sync.Mutex
+	requests := 0
+	fake := NewFakeTransport()
+	fake.On("RequestDataOnSimObjectType", func(fake *FakeTransport, call FakeCall) error {
+		data := trafficMessage{}
+		data.RequestID = call.Args[0].(uint32)
+		data.DefineID = call.Args[1].(uint32)
+		data.EntryNumber = 1
+		data.OutOf = 1
+		data.DefineCount = 7
+
+		if call.Args[3] == simconnect_data.SIMOBJECT_TYPE_AIRCRAFT {
+			requestsMutex.Lock()
+			requests++
+			first := requests == 1
+			requestsMutex.Unlock()
+			if !first {
+				return fake.Queue(simconnect_data.RECV_ID_EXCEPTION, &simconnect_data.RecvException{
+					Exception: simconnect_data.EXCEPTION_ERROR,
+					SendID:    call.SendID,
+				})
+			}
+			data.ObjectID = 5
+			copy(data.ATCID[:], "N5")
+		} else {
+			data.ObjectID = 1
+		}
+		return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, &data)
+	})
+
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracker, err := NewTrafficTracker(ctx, instance, TrafficOptions{
+		Types:      []uint32{simconnect_data.SIMOBJECT_TYPE_AIRCRAFT},
+		Interval:   20 * time.Millisecond,
+		StaleAfter: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	added := nextChange(t, tracker, TrafficAdded)
+	assert.Equal(t, uint32(5), added.Object.ObjectID)
+
+	// Well past StaleAfter, with every poll since the first having failed
+	time.Sleep(300 * time.Millisecond)
+	requestsMutex.Lock()
+	assert.Greater(t, requests, 3)
+	requestsMutex.Unlock()
+	assert.Contains(t, tracker.Objects(), uint32(5))
+
+	cancel()
+	for change := range tracker.Changes() {
+		assert.NotEqual(t, TrafficRemoved, change.Kind)
+	}
+}
+
+func TestTrafficTrackerObjectRemovedWhileFetched(t *testing.T) {
+	type trafficMessage struct {
+		simconnect_data.RecvSimobjectDataByType
+		Title       [1]byte
+		ATCID       [3]byte
+		Latitude    float64
+		Longitude   float64
+		Altitude    float64
+		Heading     float64
+		GroundSpeed float64
+	}
+
+	// Polls find nothing, and the data of the object added is only sent once the test queues it
+	fetches := make(chan FakeCall, 1)
+	fake := NewFakeTransport()
+	fake.On("RequestDataOnSimObjectType", func(fake *FakeTransport, call FakeCall) error {
+		data := trafficMessage{}
+		data.RequestID = call.Args[0].(uint32)
+		data.DefineID = call.Args[1].(uint32)
+		if call.Args[3] == simconnect_data.SIMOBJECT_TYPE_USER {
+			data.ObjectID, data.EntryNumber, data.OutOf = 1, 1, 1
+		}
+		return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, &data)
+	})
+	fake.On("RequestDataOnSimObject", func(fake *FakeTransport, call FakeCall) error {
+		fetches <- call
+		return nil
+	})
+
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+	defer instance.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracker, err := NewTrafficTracker(ctx, instance, TrafficOptions{
+		Types:    []uint32{simconnect_data.SIMOBJECT_TYPE_AIRCRAFT},
+		Interval: 20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	subscribed := fake.CallsTo("SubscribeToSystemEvent")
+	require.Len(t, subscribed, 2)
+	event := func(subscription FakeCall) *simconnect_data.RecvEventObjectAddRemove {
+		event := &simconnect_data.RecvEventObjectAddRemove{ObjType: simconnect_data.SIMOBJECT_TYPE_AIRCRAFT}
+		event.EventID = subscription.Args[0].(uint32)
+		event.Data = 50
+		return event
+	}
+	removals := func() uint64 {
+		tracker.mutex.Lock()
+		defer tracker.mutex.Unlock()
+		return tracker.removals
+	}
+
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_EVENT_OBJECT_ADDREMOVE, event(subscribed[0])))
+	var fetch FakeCall
+	select {
+	case fetch = <-fetches:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "object added not fetched")
+	}
+
+	// The removal is handled while the data of the object is awaited
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_EVENT_OBJECT_ADDREMOVE, event(subscribed[1])))
+	require.Eventually(t, func() bool { return removals() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// and the data arriving afterwards does not bring the object back
+	data := trafficMessage{}
+	data.RequestID = fetch.Args[0].(uint32)
+	data.DefineID = fetch.Args[1].(uint32)
+	data.ObjectID = 50
+	require.NoError(t, fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA, &data))
+	require.Eventually(t, func() bool {
+		tracker.mutex.Lock()
+		defer tracker.mutex.Unlock()
+		return len(tracker.removedAt) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, tracker.Objects())
+
+	cancel()
+	for change := range tracker.Changes() {
+		assert.NotEqual(t, TrafficAdded, change.Kind)
+	}
+}
@@ -0,0 +1,43 @@
+package simconnect
+
+import (
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// Transport carries the SimConnect calls made by a SimconnectInstance. The methods mirror the SimConnect_* functions
+// of the same name, minus the connection handle which is owned by the Transport itself.
+//
+// Three implementations are provided: the SimConnect.dll bindings used by NewSimConnect on Windows, the network
+// protocol client used by NewSimConnectTCP and FakeTransport for tests. Other implementations can be supplied through
+// NewSimConnectWithTransport.
+type Transport interface {
+	// Open connects to the simulator. The simulator answers with a RECV_ID_OPEN message.
+	Open(simconnectName string) error
+	Close() error
+
+	// GetNextDispatch returns the next pending message including its Recv header, or nil if there is none.
+	GetNextDispatch() ([]byte, error)
+	// GetLastSentPacketID returns the send ID of the last packet sent, which RecvException.SendID refers to.
+	GetLastSentPacketID() (uint32, error)
+
+	AddToDataDefinition(defineID uint32, datumName, unitsName string, datumType uint32, epsilon float32, datumID uint32) error
+	ClearDataDefinition(defineID uint32) error
+	RequestDataOnSimObject(requestID, defineID, objectID, period, flags, origin, interval, limit uint32) error
+	RequestDataOnSimObjectType(requestID, defineID, radius, simObjectType uint32) error
+	SetDataOnSimObject(defineID, objectID, flags, arrayCount, unitSize uint32, data []byte) error
+
+	SubscribeToSystemEvent(eventID uint32, eventName string) error
+	UnsubscribeFromSystemEvent(eventID uint32) error
+	SetSystemEventState(eventID, state uint32) error
+	MapClientEventToSimEvent(eventID uint32, eventName string) error
+	TransmitClientEvent(objectID, eventID, data, groupID, flags uint32) error
+
+	FlightPlanLoad(flightPlanPath string) error
+	AICreateParkedATCAircraft(containerTitle, tailNumber, airportICAO string, requestID uint32) error
+	AICreateNonATCAircraft(containerTitle, tailNumber string, initPos simconnect_data.SimconnectDataInitPosition, requestID uint32) error
+	AICreateEnrouteATCAircraft(containerTitle, tailNumber string, flightNumber uint32, flightPlanPath string, flightPlanPosition float64, touchAndGo bool, requestID uint32) error
+	AISetAircraftFlightPlan(objectID uint32, flightPlanPath string, requestID uint32) error
+	AIRemoveObject(objectID, requestID uint32) error
+
+	Text(textType uint32, duration float32, eventID uint32, text string) error
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package simconnect
+
+import "errors"
+
+func newDLLTransport(opts options) (Transport, error) {
+	return nil, errors.New("SimConnect.dll can only be loaded on windows, use NewSimConnectTCP to connect over the network")
+}
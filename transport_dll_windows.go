@@ -0,0 +1,597 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+
+	_ "embed"
+)
+
+//go:embed "simconnect-data/SimConnect.dll"
+var simconnectDLLBytes []byte
+
+// errDLLUnloaded is returned by the calls made to a transport once Close has unloaded its DLL.
+var errDLLUnloaded = errors.New("SimConnect.dll unloaded")
+
+// dllProcs holds the functions exported by one loaded SimConnect.dll.
+type dllProcs struct {
+	open                       *syscall.LazyProc
+	close                      *syscall.LazyProc
+	requestDataOnSimObjectType *syscall.LazyProc
+	requestDataOnSimObject     *syscall.LazyProc
+	addToDataDefinition        *syscall.LazyProc
+	clearDataDefinition        *syscall.LazyProc
+	getNextDispatch            *syscall.LazyProc
+	getLastSentPacketID        *syscall.LazyProc
+	flightPlanLoad             *syscall.LazyProc
+	aiCreateParkedATCAircraft  *syscall.LazyProc
+	aiCreateNonATCAircraft     *syscall.LazyProc
+	setDataOnSimObject         *syscall.LazyProc
+	aiCreateEnrouteATCAircraft *syscall.LazyProc
+	aiSetAircraftFlightPlan    *syscall.LazyProc
+	aiRemoveObject             *syscall.LazyProc
+	mapClientEventToSimEvent   *syscall.LazyProc
+	subscribeToSystemEvent     *syscall.LazyProc
+	unsubscribeFromSystemEvent *syscall.LazyProc
+	setSystemEventState        *syscall.LazyProc
+	transmitClientEvent        *syscall.LazyProc
+	text                       *syscall.LazyProc
+}
+
+// dllTransport calls into SimConnect.dll. Every transport resolves its own procs so instances loading different
+// builds of the DLL do not interfere with each other.
+type dllTransport struct {
+	handle      unsafe.Pointer
+	procs       *dllProcs
+	dll         *syscall.LazyDLL
+	configIndex uint32
+
+	// tempDir is the directory the embedded DLL was extracted to, removed on Close
+	tempDir string
+}
+
+func newDLLTransport(opts options) (Transport, error) {
+	t := &dllTransport{configIndex: opts.configIndex}
+
+	dllPath, err := t.locateDLL(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	t.dll = syscall.NewLazyDLL(dllPath)
+	err = t.dll.Load()
+	if err != nil {
+		t.removeTempDir()
+		return nil, fmt.Errorf("error loading %s: %v", dllPath, err)
+	}
+
+	mod := t.dll
+	t.procs = &dllProcs{
+		open:                       mod.NewProc("SimConnect_Open"),
+		close:                      mod.NewProc("SimConnect_Close"),
+		requestDataOnSimObjectType: mod.NewProc("SimConnect_RequestDataOnSimObjectType"),
+		requestDataOnSimObject:     mod.NewProc("SimConnect_RequestDataOnSimObject"),
+		addToDataDefinition:        mod.NewProc("SimConnect_AddToDataDefinition"),
+		clearDataDefinition:        mod.NewProc("SimConnect_ClearDataDefinition"),
+		getNextDispatch:            mod.NewProc("SimConnect_GetNextDispatch"),
+		getLastSentPacketID:        mod.NewProc("SimConnect_GetLastSentPacketID"),
+		flightPlanLoad:             mod.NewProc("SimConnect_FlightPlanLoad"),
+		aiCreateParkedATCAircraft:  mod.NewProc("SimConnect_AICreateParkedATCAircraft"),
+		aiCreateNonATCAircraft:     mod.NewProc("SimConnect_AICreateNonATCAircraft"),
+		setDataOnSimObject:         mod.NewProc("SimConnect_SetDataOnSimObject"),
+		aiCreateEnrouteATCAircraft: mod.NewProc("SimConnect_AICreateEnrouteATCAircraft"),
+		aiSetAircraftFlightPlan:    mod.NewProc("SimConnect_AISetAircraftFlightPlan"),
+		aiRemoveObject:             mod.NewProc("SimConnect_AIRemoveObject"),
+		mapClientEventToSimEvent:   mod.NewProc("SimConnect_MapClientEventToSimEvent"),
+		subscribeToSystemEvent:     mod.NewProc("SimConnect_SubscribeToSystemEvent"),
+		unsubscribeFromSystemEvent: mod.NewProc("SimConnect_UnsubscribeFromSystemEvent"),
+		setSystemEventState:        mod.NewProc("SimConnect_SetSystemEventState"),
+		transmitClientEvent:        mod.NewProc("SimConnect_TransmitClientEvent"),
+		text:                       mod.NewProc("SimConnect_Text"),
+	}
+
+	return t, nil
+}
+
+// locateDLL returns the path of the DLL to load: the one given by WithDLLPath, simconnect-data/SimConnect.dll relative
+// to the working directory, or else the embedded DLL extracted to the cache or a temporary directory.
+func (t *dllTransport) locateDLL(opts options) (string, error) {
+	if opts.dllPath != "" {
+		return opts.dllPath, nil
+	}
+
+	dllPath := filepath.Join("simconnect-data", "SimConnect.dll")
+	if _, err := os.Stat(dllPath); err == nil {
+		return dllPath, nil
+	}
+
+	if opts.dllCacheDir != "" {
+		return writeDLL(opts.dllCacheDir, simconnectDLLBytes)
+	}
+
+	dir, err := ioutil.TempDir("", "simconnect-go")
+	if err != nil {
+		return "", err
+	}
+	t.tempDir = dir
+
+	dllPath, err = writeDLL(dir, simconnectDLLBytes)
+	if err != nil {
+		t.removeTempDir()
+		return "", err
+	}
+	return dllPath, nil
+}
+
+func (t *dllTransport) removeTempDir() error {
+	if t.tempDir == "" {
+		return nil
+	}
+
+	err := os.RemoveAll(t.tempDir)
+	t.tempDir = ""
+	return err
+}
+
+func (t *dllTransport) Open(simconnectName string) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	args := []uintptr{
+		uintptr(unsafe.Pointer(&t.handle)),
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(simconnectName))),
+		0,
+		0,
+		0,
+		uintptr(t.configIndex),
+	}
+
+	r1, _, err := t.procs.open.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("open connect failed, error: %d %v", r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) Close() error {
+	var closeErr error
+	if t.handle != nil && t.procs != nil {
+		r1, _, err := t.procs.close.Call(uintptr(t.handle))
+		if int32(r1) < 0 {
+			closeErr = fmt.Errorf("close connection failed, error %d %v", r1, err)
+		}
+		t.handle = nil
+	}
+
+	if t.tempDir != "" && t.dll != nil {
+		// Windows will not delete the extracted DLL while it is still loaded
+		if err := syscall.FreeLibrary(syscall.Handle(t.dll.Handle())); err != nil {
+			return fmt.Errorf("error unloading %s: %v", t.dll.Name, err)
+		}
+		// The procs point into the unloaded DLL
+		t.procs = nil
+		t.dll = nil
+		if err := t.removeTempDir(); err != nil {
+			return fmt.Errorf("error removing extracted SimConnect.dll: %v", err)
+		}
+	}
+	return closeErr
+}
+
+func (t *dllTransport) GetNextDispatch() ([]byte, error) {
+	if t.procs == nil {
+		return nil, errDLLUnloaded
+	}
+
+	var ppData unsafe.Pointer
+	var ppDataLength uint32
+
+	r1, _, err := t.procs.getNextDispatch.Call(
+		uintptr(t.handle),
+		uintptr(unsafe.Pointer(&ppData)),
+		uintptr(unsafe.Pointer(&ppDataLength)),
+	)
+
+	if uint32(r1) == simconnect_data.E_FAIL {
+		// No new message
+		return nil, nil
+	}
+
+	if int32(r1) < 0 {
+		return nil, fmt.Errorf("GetNextDispatch error: %d %v", r1, err)
+	}
+
+	return (*[1 << 30]byte)(ppData)[:ppDataLength:ppDataLength], nil
+}
+
+func (t *dllTransport) GetLastSentPacketID() (uint32, error) {
+	if t.procs == nil {
+		return 0, errDLLUnloaded
+	}
+
+	var sendID uint32
+
+	r1, _, err := t.procs.getLastSentPacketID.Call(
+		uintptr(t.handle),
+		uintptr(unsafe.Pointer(&sendID)),
+	)
+	if int32(r1) < 0 {
+		return 0, fmt.Errorf("GetLastSentPacketID error: %d %v", r1, err)
+	}
+
+	return sendID, nil
+}
+
+func (t *dllTransport) AddToDataDefinition(defineID uint32, datumName, unitsName string, datumType uint32, epsilon float32, datumID uint32) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	nameParam := []byte(datumName + "\x00")
+	unitParam := []byte(unitsName + "\x00")
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(defineID),
+		uintptr(unsafe.Pointer(&nameParam[0])),
+		uintptr(0),
+		uintptr(datumType),
+		float32Arg(epsilon),
+		uintptr(datumID),
+	}
+	if unitsName != "" {
+		args[3] = uintptr(unsafe.Pointer(&unitParam[0]))
+	}
+
+	r1, _, err := t.procs.addToDataDefinition.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("add to data definition failed for %s error: %d %s", datumName, r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) ClearDataDefinition(defineID uint32) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	r1, _, err := t.procs.clearDataDefinition.Call(uintptr(t.handle), uintptr(defineID))
+	if int32(r1) < 0 {
+		return fmt.Errorf("clear data definition failed for defineID %d error: %d %v", defineID, r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) RequestDataOnSimObject(requestID, defineID, objectID, period, flags, origin, interval, limit uint32) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(requestID),
+		uintptr(defineID),
+		uintptr(objectID),
+		uintptr(period),
+		uintptr(flags),
+		uintptr(origin),
+		uintptr(interval),
+		uintptr(limit),
+	}
+
+	r1, _, err := t.procs.requestDataOnSimObject.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("requestData for requestID %d defineID %d objectID %d error: %d %v", requestID, defineID, objectID, r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) RequestDataOnSimObjectType(requestID, defineID, radius, simObjectType uint32) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(requestID),
+		uintptr(defineID),
+		uintptr(radius),
+		uintptr(simObjectType),
+	}
+
+	r1, _, err := t.procs.requestDataOnSimObjectType.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("requestData for requestID %d defineID %d error: %d %v",
+			requestID, defineID, r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) SetDataOnSimObject(defineID, objectID, flags, arrayCount, unitSize uint32, data []byte) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(defineID),
+		uintptr(objectID),
+		uintptr(flags),
+		uintptr(arrayCount),
+		uintptr(unitSize),
+		uintptr(unsafe.Pointer(&data[0])),
+	}
+
+	r1, _, err := t.procs.setDataOnSimObject.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("setDataOnSimObject for objectID %d error: %d %v", objectID, r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) SubscribeToSystemEvent(eventID uint32, eventName string) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	_eventName := []byte(eventName + "\x00")
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(eventID),
+		uintptr(unsafe.Pointer(&_eventName[0])),
+	}
+
+	r1, _, err := t.procs.subscribeToSystemEvent.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("SimConnect_SubscribeToSystemEvent for %s error: %d %s", eventName, r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) UnsubscribeFromSystemEvent(eventID uint32) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	r1, _, err := t.procs.unsubscribeFromSystemEvent.Call(uintptr(t.handle), uintptr(eventID))
+	if int32(r1) < 0 {
+		return fmt.Errorf("SimConnect_UnsubscribeFromSystemEvent for %d error: %d %s", eventID, r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) SetSystemEventState(eventID, state uint32) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	r1, _, err := t.procs.setSystemEventState.Call(uintptr(t.handle), uintptr(eventID), uintptr(state))
+	if int32(r1) < 0 {
+		return fmt.Errorf("SimConnect_SetSystemEventState for %d error: %d %s", eventID, r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) MapClientEventToSimEvent(eventID uint32, eventName string) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	_eventName := []byte(eventName + "\x00")
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(eventID),
+		uintptr(unsafe.Pointer(&_eventName[0])),
+	}
+
+	r1, _, err := t.procs.mapClientEventToSimEvent.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf(
+			"SimConnect_MapClientEventToSimEvent for eventID %d error: %d %s",
+			eventID, r1, err,
+		)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) TransmitClientEvent(objectID, eventID, data, groupID, flags uint32) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(objectID),
+		uintptr(eventID),
+		uintptr(data),
+		uintptr(groupID),
+		uintptr(flags),
+	}
+
+	r1, _, err := t.procs.transmitClientEvent.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf(
+			"SimConnect_TransmitClientEvent for eventID %d and data %d error: %d %s",
+			eventID, data, r1, err,
+		)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) FlightPlanLoad(flightPlanPath string) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	flightPlanPathArg := []byte(flightPlanPath + "\x00")
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(unsafe.Pointer(&flightPlanPathArg[0])),
+	}
+	r1, _, err := t.procs.flightPlanLoad.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("error: %d %v", r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) AICreateParkedATCAircraft(containerTitle, tailNumber, airportICAO string, requestID uint32) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	containerTitleArg := []byte(containerTitle + "\x00")
+	tailNumberArg := []byte(tailNumber + "\x00")
+	airportICAOArg := []byte(airportICAO + "\x00")
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(unsafe.Pointer(&containerTitleArg[0])),
+		uintptr(unsafe.Pointer(&tailNumberArg[0])),
+		uintptr(unsafe.Pointer(&airportICAOArg[0])),
+		uintptr(requestID),
+	}
+	r1, _, err := t.procs.aiCreateParkedATCAircraft.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("error: %d %v", r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) AICreateNonATCAircraft(containerTitle, tailNumber string, initPos simconnect_data.SimconnectDataInitPosition, requestID uint32) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	containerTitleArg := []byte(containerTitle + "\x00")
+	tailNumberArg := []byte(tailNumber + "\x00")
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(unsafe.Pointer(&containerTitleArg[0])),
+		uintptr(unsafe.Pointer(&tailNumberArg[0])),
+		uintptr(unsafe.Pointer(&initPos)),
+		uintptr(requestID),
+	}
+	r1, _, err := t.procs.aiCreateNonATCAircraft.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("error: %d %v", r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) AICreateEnrouteATCAircraft(containerTitle, tailNumber string, flightNumber uint32, flightPlanPath string, flightPlanPosition float64, touchAndGo bool, requestID uint32) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	containerTitleArg := []byte(containerTitle + "\x00")
+	tailNumberArg := []byte(tailNumber + "\x00")
+	pathArg := []byte(flightPlanPath + "\x00")
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(unsafe.Pointer(&containerTitleArg[0])),
+		uintptr(unsafe.Pointer(&tailNumberArg[0])),
+		uintptr(flightNumber),
+		uintptr(unsafe.Pointer(&pathArg[0])),
+		float64Arg(flightPlanPosition),
+		uintptr(b2i(touchAndGo)),
+		uintptr(requestID),
+	}
+	r1, _, err := t.procs.aiCreateEnrouteATCAircraft.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("error: %d %v", r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) AISetAircraftFlightPlan(objectID uint32, flightPlanPath string, requestID uint32) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	pathArg := []byte(flightPlanPath + "\x00")
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(objectID),
+		uintptr(unsafe.Pointer(&pathArg[0])),
+		uintptr(requestID),
+	}
+
+	r1, _, err := t.procs.aiSetAircraftFlightPlan.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("error: %d %v", r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) AIRemoveObject(objectID, requestID uint32) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(objectID),
+		uintptr(requestID),
+	}
+
+	r1, _, err := t.procs.aiRemoveObject.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("error: %d %v", r1, err)
+	}
+
+	return nil
+}
+
+func (t *dllTransport) Text(textType uint32, duration float32, eventID uint32, text string) error {
+	if t.procs == nil {
+		return errDLLUnloaded
+	}
+
+	textArg := []byte(text + "\x00")
+
+	args := []uintptr{
+		uintptr(t.handle),
+		uintptr(textType),
+		float32Arg(duration),
+		uintptr(eventID),
+		uintptr(uint32(len(textArg))),
+		uintptr(unsafe.Pointer(&textArg[0])),
+	}
+
+	r1, _, err := t.procs.text.Call(args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("SimConnect_Text for eventID %d and data %s error: %d %v", eventID, text, r1, err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// FakeCall is a single call recorded by FakeTransport. Args holds the parameters in the order of the Transport method.
+// SendID is the packet ID the call was given, for use as RecvException.SendID.
+type FakeCall struct {
+	Method string
+	Args   []interface{}
+	SendID uint32
+}
+
+// FakeHandler scripts the behaviour of a FakeTransport method. It is typically used to Queue the messages the
+// simulator would send in response to the call. A non nil error is returned from the call.
+type FakeHandler func(fake *FakeTransport, call FakeCall) error
+
+// FakeTransport is an in-memory Transport which records every call and replays queued messages through
+// GetNextDispatch. By default Open queues a RecvOpen so NewSimConnectWithTransport succeeds without any scripting.
+type FakeTransport struct {
+	mutex    sync.Mutex
+	calls    []FakeCall
+	queue    [][]byte
+	handlers map[string]FakeHandler
+	sendID   uint32
+}
+
+// NewFakeTransport returns an empty FakeTransport.
+func NewFakeTransport() *FakeTransport {
+	fake := &FakeTransport{
+		handlers: map[string]FakeHandler{},
+	}
+
+	fake.On("Open", func(fake *FakeTransport, call FakeCall) error {
+		recvOpen := simconnect_data.RecvOpen{}
+		copy(recvOpen.ApplicationName[:], "FakeTransport")
+		return fake.Queue(simconnect_data.RECV_ID_OPEN, &recvOpen)
+	})
+
+	return fake
+}
+
+// On replaces the handler for method, which must be the name of a Transport method.
+func (fake *FakeTransport) On(method string, handler FakeHandler) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	fake.handlers[method] = handler
+}
+
+// Queue appends a message to be returned by GetNextDispatch. msg must be a pointer to a struct starting with a
+// simconnect_data.Recv header, such as simconnect_data.RecvEvent or a Report. Its Size and ID are filled in.
+func (fake *FakeTransport) Queue(recvID uint32, msg interface{}) error {
+	buf := &bytes.Buffer{}
+	if err := binary.Write(buf, binary.LittleEndian, msg); err != nil {
+		return fmt.Errorf("encoding fake message: %v", err)
+	}
+
+	data := buf.Bytes()
+	if len(data) < 12 {
+		return fmt.Errorf("fake message of %d bytes is smaller than its header", len(data))
+	}
+	binary.LittleEndian.PutUint32(data[0:], uint32(len(data)))
+	binary.LittleEndian.PutUint32(data[8:], recvID)
+
+	fake.QueueBytes(data)
+	return nil
+}
+
+// QueueBytes appends a raw message, including its header, to be returned by GetNextDispatch.
+func (fake *FakeTransport) QueueBytes(data []byte) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	fake.queue = append(fake.queue, data)
+}
+
+// Calls returns every call recorded so far.
+func (fake *FakeTransport) Calls() []FakeCall {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	return append([]FakeCall(nil), fake.calls...)
+}
+
+// CallsTo returns the recorded calls to method.
+func (fake *FakeTransport) CallsTo(method string) []FakeCall {
+	var calls []FakeCall
+	for _, call := range fake.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func (fake *FakeTransport) record(method string, args ...interface{}) error {
+	fake.mutex.Lock()
+	fake.sendID++
+	call := FakeCall{Method: method, Args: args, SendID: fake.sendID}
+	fake.calls = append(fake.calls, call)
+	handler := fake.handlers[method]
+	fake.mutex.Unlock()
+
+	if handler == nil {
+		return nil
+	}
+	return handler(fake, call)
+}
+
+// GetLastSentPacketID returns the SendID of the last recorded call. It is not recorded itself.
+func (fake *FakeTransport) GetLastSentPacketID() (uint32, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	return fake.sendID, nil
+}
+
+func (fake *FakeTransport) Open(simconnectName string) error {
+	return fake.record("Open", simconnectName)
+}
+
+func (fake *FakeTransport) Close() error {
+	return fake.record("Close")
+}
+
+func (fake *FakeTransport) GetNextDispatch() ([]byte, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	if len(fake.queue) == 0 {
+		// No new message
+		return nil, nil
+	}
+
+	data := fake.queue[0]
+	fake.queue = fake.queue[1:]
+	return data, nil
+}
+
+func (fake *FakeTransport) AddToDataDefinition(defineID uint32, datumName, unitsName string, datumType uint32, epsilon float32, datumID uint32) error {
+	return fake.record("AddToDataDefinition", defineID, datumName, unitsName, datumType, epsilon, datumID)
+}
+
+func (fake *FakeTransport) ClearDataDefinition(defineID uint32) error {
+	return fake.record("ClearDataDefinition", defineID)
+}
+
+func (fake *FakeTransport) RequestDataOnSimObject(requestID, defineID, objectID, period, flags, origin, interval, limit uint32) error {
+	return fake.record("RequestDataOnSimObject", requestID, defineID, objectID, period, flags, origin, interval, limit)
+}
+
+func (fake *FakeTransport) RequestDataOnSimObjectType(requestID, defineID, radius, simObjectType uint32) error {
+	return fake.record("RequestDataOnSimObjectType", requestID, defineID, radius, simObjectType)
+}
+
+func (fake *FakeTransport) SetDataOnSimObject(defineID, objectID, flags, arrayCount, unitSize uint32, data []byte) error {
+	return fake.record("SetDataOnSimObject", defineID, objectID, flags, arrayCount, unitSize, append([]byte(nil), data...))
+}
+
+func (fake *FakeTransport) SubscribeToSystemEvent(eventID uint32, eventName string) error {
+	return fake.record("SubscribeToSystemEvent", eventID, eventName)
+}
+
+func (fake *FakeTransport) UnsubscribeFromSystemEvent(eventID uint32) error {
+	return fake.record("UnsubscribeFromSystemEvent", eventID)
+}
+
+func (fake *FakeTransport) SetSystemEventState(eventID, state uint32) error {
+	return fake.record("SetSystemEventState", eventID, state)
+}
+
+func (fake *FakeTransport) MapClientEventToSimEvent(eventID uint32, eventName string) error {
+	return fake.record("MapClientEventToSimEvent", eventID, eventName)
+}
+
+func (fake *FakeTransport) TransmitClientEvent(objectID, eventID, data, groupID, flags uint32) error {
+	return fake.record("TransmitClientEvent", objectID, eventID, data, groupID, flags)
+}
+
+func (fake *FakeTransport) FlightPlanLoad(flightPlanPath string) error {
+	return fake.record("FlightPlanLoad", flightPlanPath)
+}
+
+func (fake *FakeTransport) AICreateParkedATCAircraft(containerTitle, tailNumber, airportICAO string, requestID uint32) error {
+	return fake.record("AICreateParkedATCAircraft", containerTitle, tailNumber, airportICAO, requestID)
+}
+
+func (fake *FakeTransport) AICreateNonATCAircraft(containerTitle, tailNumber string, initPos simconnect_data.SimconnectDataInitPosition, requestID uint32) error {
+	return fake.record("AICreateNonATCAircraft", containerTitle, tailNumber, initPos, requestID)
+}
+
+func (fake *FakeTransport) AICreateEnrouteATCAircraft(containerTitle, tailNumber string, flightNumber uint32, flightPlanPath string, flightPlanPosition float64, touchAndGo bool, requestID uint32) error {
+	return fake.record("AICreateEnrouteATCAircraft", containerTitle, tailNumber, flightNumber, flightPlanPath, flightPlanPosition, touchAndGo, requestID)
+}
+
+func (fake *FakeTransport) AISetAircraftFlightPlan(objectID uint32, flightPlanPath string, requestID uint32) error {
+	return fake.record("AISetAircraftFlightPlan", objectID, flightPlanPath, requestID)
+}
+
+func (fake *FakeTransport) AIRemoveObject(objectID, requestID uint32) error {
+	return fake.record("AIRemoveObject", objectID, requestID)
+}
+
+func (fake *FakeTransport) Text(textType uint32, duration float32, eventID uint32, text string) error {
+	return fake.record("Text", textType, duration, eventID, text)
+}
@@ -0,0 +1,82 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+func TestFakeGetReport(t *testing.T) {
+	fake := NewFakeTransport()
+	fake.On("RequestDataOnSimObjectType", func(fake *FakeTransport, call FakeCall) error {
+		report := Report{}
+		report.RequestID = call.Args[0].(uint32)
+		report.DefineID = call.Args[1].(uint32)
+		report.Altitude = 35000
+		report.EngineCount = 2
+		copy(report.Title[:], "Airbus A320 Neo Asobo")
+		return fake.Queue(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, &report)
+	})
+
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+
+	report, err := instance.GetReport()
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(35000), report.Altitude)
+	assert.Equal(t, int32(2), report.EngineCount)
+	assert.Equal(t, "Airbus A320 Neo Asobo", string(bytes.TrimRight(report.Title[:], "\x00")))
+
+	definitions := fake.CallsTo("AddToDataDefinition")
+	require.Len(t, definitions, 49)
+	assert.Equal(t, []interface{}{uint32(1), "Plane Altitude", "feet", simconnect_data.DATATYPE_FLOAT64, float32(0), uint32(0xffffffff)}, definitions[5].Args)
+
+	assert.Equal(t, []interface{}{firstInternalRequestID, uint32(1), uint32(0), simconnect_data.SIMOBJECT_TYPE_USER}, fake.CallsTo("RequestDataOnSimObjectType")[0].Args)
+}
+
+func TestFakeLoadNonATCAircraft(t *testing.T) {
+	fake := NewFakeTransport()
+	fake.On("AICreateNonATCAircraft", func(fake *FakeTransport, call FakeCall) error {
+		assigned := simconnect_data.RecvAssignedObjectID{}
+		assigned.RequestID = call.Args[3].(uint32)
+		assigned.ObjectID = 42
+		return fake.Queue(simconnect_data.RECV_ID_ASSIGNED_OBJECT_ID, &assigned)
+	})
+
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+
+	objectID, err := instance.LoadNonATCAircraft("Boeing 747-8i Asobo", "G-4210", simconnect_data.SimconnectDataInitPosition{Altitude: 235}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(42), *objectID)
+
+	err = instance.SetDataOnSimObject(*objectID, []SetSimObjectDataExpose{{Altitude: 400, OnGround: true}})
+	require.NoError(t, err)
+
+	set := fake.CallsTo("SetDataOnSimObject")
+	require.Len(t, set, 1)
+	assert.Equal(t, uint32(42), set[0].Args[1])
+
+	values := make([]float64, 8)
+	require.NoError(t, binary.Read(bytes.NewReader(set[0].Args[5].([]byte)), binary.LittleEndian, values))
+	assert.Equal(t, []float64{0, 400, 0, 0, 0, 0, 1, 0}, values)
+}
+
+func TestFakeCallError(t *testing.T) {
+	fake := NewFakeTransport()
+	fake.On("MapClientEventToSimEvent", func(fake *FakeTransport, call FakeCall) error {
+		return errors.New("mapping failed")
+	})
+
+	instance, err := NewSimConnectWithTransport(t.Name(), fake)
+	require.NoError(t, err)
+
+	assert.EqualError(t, instance.MapClientEventToSimEvent(1, "COM_RADIO_FRACT_INC"), "mapping failed")
+}
@@ -0,0 +1,335 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/JRascagneres/Simconnect-Go/internal/protocol"
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// Version reported to the server when opening a connection, matching the FSX SP2 SimConnect client.
+const (
+	networkClientVersionMajor uint32 = 10
+	networkClientVersionMinor uint32 = 0
+	networkClientBuildMajor   uint32 = 61259
+	networkClientBuildMinor   uint32 = 0
+)
+
+const networkDialTimeout = 5 * time.Second
+
+// networkTransport speaks the SimConnect network protocol directly over TCP and therefore needs no SimConnect.dll.
+type networkTransport struct {
+	address string
+
+	conn       net.Conn
+	writeMutex sync.Mutex
+	nextSendID uint32
+
+	received chan []byte
+	readErr  error
+	// done is closed by Close to stop readLoop while it waits for received to be read
+	done      chan struct{}
+	closeOnce sync.Once
+	readDone  chan struct{}
+}
+
+func newNetworkTransport(address string) *networkTransport {
+	return &networkTransport{
+		address:    address,
+		nextSendID: 1,
+	}
+}
+
+func (t *networkTransport) Open(simconnectName string) error {
+	conn, err := net.DialTimeout("tcp", t.address, networkDialTimeout)
+	if err != nil {
+		return fmt.Errorf("open connect failed, error: %v", err)
+	}
+	t.conn = conn
+	t.received = make(chan []byte, 256)
+	t.done = make(chan struct{})
+	t.closeOnce = sync.Once{}
+	t.readDone = make(chan struct{})
+
+	go t.readLoop()
+
+	payload := (&protocol.Builder{}).
+		String(simconnectName, protocol.StringSize256).
+		Uint32(0).
+		Raw([]byte{0, 'X', 'S', 'F'}).
+		Uint32(networkClientVersionMajor).
+		Uint32(networkClientVersionMinor).
+		Uint32(networkClientBuildMajor).
+		Uint32(networkClientBuildMinor).
+		Payload()
+
+	return t.send(protocol.ID_OPEN, payload)
+}
+
+// readLoop queues every packet sent by the server until the connection fails or the transport is closed.
+func (t *networkTransport) readLoop() {
+	defer close(t.readDone)
+
+	for {
+		packet, err := protocol.ReadPacket(t.conn)
+		if err != nil {
+			t.readErr = err
+			close(t.received)
+			return
+		}
+		select {
+		case t.received <- packet:
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *networkTransport) send(id uint32, payload []byte) error {
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+
+	if t.conn == nil {
+		return errors.New("connection not open")
+	}
+
+	packet := protocol.EncodeRequest(id, t.nextSendID, payload)
+	t.nextSendID++
+
+	if _, err := t.conn.Write(packet); err != nil {
+		return fmt.Errorf("send packet %#x error: %v", id, err)
+	}
+
+	return nil
+}
+
+func (t *networkTransport) GetLastSentPacketID() (uint32, error) {
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+
+	if t.nextSendID <= 1 {
+		return 0, errors.New("no packet sent")
+	}
+	return t.nextSendID - 1, nil
+}
+
+func (t *networkTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	t.closeOnce.Do(func() { close(t.done) })
+	err := t.conn.Close()
+	<-t.readDone
+	if err != nil {
+		return fmt.Errorf("close connection failed, error %v", err)
+	}
+	return nil
+}
+
+func (t *networkTransport) GetNextDispatch() ([]byte, error) {
+	select {
+	case packet, ok := <-t.received:
+		if !ok {
+			return nil, fmt.Errorf("GetNextDispatch error: %v", t.readErr)
+		}
+		return packet, nil
+	default:
+		// No new message
+		return nil, nil
+	}
+}
+
+func (t *networkTransport) AddToDataDefinition(defineID uint32, datumName, unitsName string, datumType uint32, epsilon float32, datumID uint32) error {
+	payload := (&protocol.Builder{}).
+		Uint32(defineID).
+		String(datumName, protocol.StringSize256).
+		String(unitsName, protocol.StringSize256).
+		Uint32(datumType).
+		Float32(epsilon).
+		Uint32(datumID).
+		Payload()
+
+	return t.send(protocol.ID_ADD_TO_DATA_DEFINITION, payload)
+}
+
+func (t *networkTransport) ClearDataDefinition(defineID uint32) error {
+	payload := (&protocol.Builder{}).
+		Uint32(defineID).
+		Payload()
+
+	return t.send(protocol.ID_CLEAR_DATA_DEFINITION, payload)
+}
+
+func (t *networkTransport) RequestDataOnSimObject(requestID, defineID, objectID, period, flags, origin, interval, limit uint32) error {
+	payload := (&protocol.Builder{}).
+		Uint32(requestID).
+		Uint32(defineID).
+		Uint32(objectID).
+		Uint32(period).
+		Uint32(flags).
+		Uint32(origin).
+		Uint32(interval).
+		Uint32(limit).
+		Payload()
+
+	return t.send(protocol.ID_REQUEST_DATA_ON_SIM_OBJECT, payload)
+}
+
+func (t *networkTransport) RequestDataOnSimObjectType(requestID, defineID, radius, simObjectType uint32) error {
+	payload := (&protocol.Builder{}).
+		Uint32(requestID).
+		Uint32(defineID).
+		Uint32(radius).
+		Uint32(simObjectType).
+		Payload()
+
+	return t.send(protocol.ID_REQUEST_DATA_ON_SIM_OBJECT_TYPE, payload)
+}
+
+func (t *networkTransport) SetDataOnSimObject(defineID, objectID, flags, arrayCount, unitSize uint32, data []byte) error {
+	payload := (&protocol.Builder{}).
+		Uint32(defineID).
+		Uint32(objectID).
+		Uint32(flags).
+		Uint32(arrayCount).
+		Uint32(unitSize).
+		Raw(data).
+		Payload()
+
+	return t.send(protocol.ID_SET_DATA_ON_SIM_OBJECT, payload)
+}
+
+func (t *networkTransport) SubscribeToSystemEvent(eventID uint32, eventName string) error {
+	payload := (&protocol.Builder{}).
+		Uint32(eventID).
+		String(eventName, protocol.StringSize256).
+		Payload()
+
+	return t.send(protocol.ID_SUBSCRIBE_TO_SYSTEM_EVENT, payload)
+}
+
+func (t *networkTransport) UnsubscribeFromSystemEvent(eventID uint32) error {
+	payload := (&protocol.Builder{}).
+		Uint32(eventID).
+		Payload()
+
+	return t.send(protocol.ID_UNSUBSCRIBE_FROM_SYSTEM_EVENT, payload)
+}
+
+func (t *networkTransport) SetSystemEventState(eventID, state uint32) error {
+	payload := (&protocol.Builder{}).
+		Uint32(eventID).
+		Uint32(state).
+		Payload()
+
+	return t.send(protocol.ID_SET_SYSTEM_EVENT_STATE, payload)
+}
+
+func (t *networkTransport) MapClientEventToSimEvent(eventID uint32, eventName string) error {
+	payload := (&protocol.Builder{}).
+		Uint32(eventID).
+		String(eventName, protocol.StringSize256).
+		Payload()
+
+	return t.send(protocol.ID_MAP_CLIENT_EVENT_TO_SIM_EVENT, payload)
+}
+
+func (t *networkTransport) TransmitClientEvent(objectID, eventID, data, groupID, flags uint32) error {
+	payload := (&protocol.Builder{}).
+		Uint32(objectID).
+		Uint32(eventID).
+		Uint32(data).
+		Uint32(groupID).
+		Uint32(flags).
+		Payload()
+
+	return t.send(protocol.ID_TRANSMIT_CLIENT_EVENT, payload)
+}
+
+func (t *networkTransport) FlightPlanLoad(flightPlanPath string) error {
+	payload := (&protocol.Builder{}).
+		String(flightPlanPath, protocol.StringSize260).
+		Payload()
+
+	return t.send(protocol.ID_FLIGHT_PLAN_LOAD, payload)
+}
+
+func (t *networkTransport) AICreateParkedATCAircraft(containerTitle, tailNumber, airportICAO string, requestID uint32) error {
+	payload := (&protocol.Builder{}).
+		String(containerTitle, protocol.StringSize256).
+		String(tailNumber, protocol.TailNumberSize).
+		String(airportICAO, protocol.AirportICAOSize).
+		Uint32(requestID).
+		Payload()
+
+	return t.send(protocol.ID_AI_CREATE_PARKED_ATC_AIRCRAFT, payload)
+}
+
+func (t *networkTransport) AICreateNonATCAircraft(containerTitle, tailNumber string, initPos simconnect_data.SimconnectDataInitPosition, requestID uint32) error {
+	payload := (&protocol.Builder{}).
+		String(containerTitle, protocol.StringSize256).
+		String(tailNumber, protocol.TailNumberSize).
+		Float64(initPos.Latitude).
+		Float64(initPos.Longitude).
+		Float64(initPos.Altitude).
+		Float64(initPos.Pitch).
+		Float64(initPos.Bank).
+		Float64(initPos.Heading).
+		Uint32(uint32(b2i(initPos.OnGround))).
+		Uint32(initPos.Airspeed).
+		Uint32(requestID).
+		Payload()
+
+	return t.send(protocol.ID_AI_CREATE_NON_ATC_AIRCRAFT, payload)
+}
+
+func (t *networkTransport) AICreateEnrouteATCAircraft(containerTitle, tailNumber string, flightNumber uint32, flightPlanPath string, flightPlanPosition float64, touchAndGo bool, requestID uint32) error {
+	payload := (&protocol.Builder{}).
+		String(containerTitle, protocol.StringSize256).
+		String(tailNumber, protocol.TailNumberSize).
+		Uint32(flightNumber).
+		String(flightPlanPath, protocol.StringSize260).
+		Float64(flightPlanPosition).
+		Uint32(uint32(b2i(touchAndGo))).
+		Uint32(requestID).
+		Payload()
+
+	return t.send(protocol.ID_AI_CREATE_ENROUTE_ATC_AIRCRAFT, payload)
+}
+
+func (t *networkTransport) AISetAircraftFlightPlan(objectID uint32, flightPlanPath string, requestID uint32) error {
+	payload := (&protocol.Builder{}).
+		Uint32(objectID).
+		String(flightPlanPath, protocol.StringSize260).
+		Uint32(requestID).
+		Payload()
+
+	return t.send(protocol.ID_AI_SET_AIRCRAFT_FLIGHT_PLAN, payload)
+}
+
+func (t *networkTransport) AIRemoveObject(objectID, requestID uint32) error {
+	payload := (&protocol.Builder{}).
+		Uint32(objectID).
+		Uint32(requestID).
+		Payload()
+
+	return t.send(protocol.ID_AI_REMOVE_OBJECT, payload)
+}
+
+func (t *networkTransport) Text(textType uint32, duration float32, eventID uint32, text string) error {
+	textArg := []byte(text + "\x00")
+
+	payload := (&protocol.Builder{}).
+		Uint32(textType).
+		Float32(duration).
+		Uint32(eventID).
+		Uint32(uint32(len(textArg))).
+		Raw(textArg).
+		Payload()
+
+	return t.send(protocol.ID_TEXT, payload)
+}
@@ -0,0 +1,211 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JRascagneres/Simconnect-Go/internal/protocol"
+	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
+)
+
+// standInServer accepts a single connection and hands every decoded request to handle, writing back whatever
+// packets it returns.
+func standInServer(t *testing.T, handle func(req protocol.Request) [][]byte) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			packet, err := protocol.ReadPacket(conn)
+			if err != nil {
+				return
+			}
+			req, err := protocol.DecodeRequest(packet)
+			if err != nil {
+				return
+			}
+			for _, response := range handle(req) {
+				if _, err := conn.Write(response); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func openResponse(name string) []byte {
+	payload := (&protocol.Builder{}).
+		String(name, protocol.StringSize256).
+		Uint32(11).Uint32(0).Uint32(62651).Uint32(3).
+		Uint32(11).Uint32(0).Uint32(62651).Uint32(3).
+		Uint32(0).Uint32(0).
+		Payload()
+	return protocol.EncodeResponse(simconnect_data.RECV_ID_OPEN, payload)
+}
+
+func TestNetworkGetReport(t *testing.T) {
+	var definitions []string
+	requests := make(chan protocol.Request, 16)
+
+	address := standInServer(t, func(req protocol.Request) [][]byte {
+		r := protocol.NewReader(req.Payload)
+		switch req.ID {
+		case protocol.ID_OPEN:
+			assert.Equal(t, "TestNetworkGetReport", r.String(protocol.StringSize256))
+			return [][]byte{openResponse("KittyHawk")}
+		case protocol.ID_ADD_TO_DATA_DEFINITION:
+			r.Uint32()
+			definitions = append(definitions, r.String(protocol.StringSize256))
+		case protocol.ID_REQUEST_DATA_ON_SIM_OBJECT_TYPE:
+			requestID, defineID := r.Uint32(), r.Uint32()
+
+			report := APReport{}
+			report.ID = simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE
+			report.RequestID = requestID
+			report.DefineID = defineID
+			report.ObjectID = 1
+			report.EntryNumber = 1
+			report.OutOf = 1
+			report.DefineCount = 3
+			copy(report.Title[:], "Cessna Skyhawk")
+			report.APSelectedAlt = 4500
+			report.APAltSlot = 1
+
+			buf := &bytes.Buffer{}
+			require.NoError(t, binary.Write(buf, binary.LittleEndian, report))
+			return [][]byte{protocol.EncodeResponse(simconnect_data.RECV_ID_SIMOBJECT_DATA_BYTYPE, buf.Bytes()[protocol.ResponseHeaderSize:])}
+		default:
+			requests <- req
+		}
+		return nil
+	})
+
+	instance, err := NewSimConnectTCP(t.Name(), address)
+	require.NoError(t, err)
+
+	report, err := instance.GetAPReport()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Title", "AUTOPILOT ALTITUDE LOCK VAR:3", "AUTOPILOT ALTITUDE SLOT INDEX"}, definitions)
+	assert.Equal(t, "Cessna Skyhawk", string(bytes.TrimRight(report.Title[:], "\x00")))
+	assert.Equal(t, float64(4500), report.APSelectedAlt)
+	assert.Equal(t, int32(1), report.APAltSlot)
+
+	require.NoError(t, instance.MapClientEventToSimEvent(10, "AP_ALT_VAR_SET_ENGLISH"))
+	require.NoError(t, instance.TransmitClientID(10, 4500))
+
+	req := <-requests
+	r := protocol.NewReader(req.Payload)
+	assert.Equal(t, protocol.ID_MAP_CLIENT_EVENT_TO_SIM_EVENT, req.ID)
+	assert.Equal(t, uint32(10), r.Uint32())
+	assert.Equal(t, "AP_ALT_VAR_SET_ENGLISH", r.String(protocol.StringSize256))
+
+	req = <-requests
+	r = protocol.NewReader(req.Payload)
+	assert.Equal(t, protocol.ID_TRANSMIT_CLIENT_EVENT, req.ID)
+	assert.Equal(t, []uint32{0, 10, 4500, 1, 0x10}, []uint32{r.Uint32(), r.Uint32(), r.Uint32(), r.Uint32(), r.Uint32()})
+	assert.NoError(t, r.Err())
+
+	assert.NoError(t, instance.Close())
+}
+
+// TestNetworkPacketBytes checks the bytes of the packets sent, whose function IDs must be those sent by SimConnect.dll.
+func TestNetworkPacketBytes(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	packets := make(chan []byte, 16)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			packet, err := protocol.ReadPacket(conn)
+			if err != nil {
+				return
+			}
+			packets <- packet
+		}
+	}()
+
+	transport := newNetworkTransport(listener.Addr().String())
+	require.NoError(t, transport.Open(t.Name()))
+	defer transport.Close()
+	<-packets
+
+	// Headers are the size, the protocol version, the function ID OR'ed with 0xF0000000 and the send ID
+	require.NoError(t, transport.MapClientEventToSimEvent(10, "AP_MASTER"))
+	packet := <-packets
+	assert.Equal(t, "14010000"+"04000000"+"040000f0"+"02000000"+"0a000000"+"41505f4d4153544552", hex.EncodeToString(packet[:29]))
+
+	require.NoError(t, transport.AddToDataDefinition(1, "Title", "", simconnect_data.DATATYPE_STRINGV, 0.5, simconnect_data.UNUSED))
+	packet = <-packets
+	assert.Equal(t, "20020000"+"04000000"+"0c0000f0"+"03000000"+"01000000", hex.EncodeToString(packet[:20]))
+	assert.Equal(t, "0b000000"+"0000003f"+"ffffffff", hex.EncodeToString(packet[20+2*protocol.StringSize256:]))
+
+	require.NoError(t, transport.RequestDataOnSimObjectType(2, 1, 0, simconnect_data.SIMOBJECT_TYPE_USER))
+	packet = <-packets
+	assert.Equal(t, "20000000"+"04000000"+"0f0000f0"+"04000000"+"02000000"+"01000000"+"00000000"+"00000000", hex.EncodeToString(packet))
+
+	require.NoError(t, transport.SubscribeToSystemEvent(3, "Pause"))
+	packet = <-packets
+	assert.Equal(t, "14010000"+"04000000"+"170000f0"+"05000000"+"03000000"+"5061757365", hex.EncodeToString(packet[:25]))
+
+	require.NoError(t, transport.Text(simconnect_data.TEXT_TYPE_PRINT_WHITE, 2.5, 4, "hi"))
+	packet = <-packets
+	assert.Equal(t, "23000000"+"04000000"+"400000f0"+"06000000"+"01010000"+"00002040"+"04000000"+"03000000"+"686900", hex.EncodeToString(packet))
+}
+
+func TestNetworkCloseWithPendingMessages(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	// More messages than are buffered, none of which are read
+	address := standInServer(t, func(req protocol.Request) [][]byte {
+		if req.ID != protocol.ID_OPEN {
+			return nil
+		}
+		responses := [][]byte{openResponse("KittyHawk")}
+		for i := 0; i < 300; i++ {
+			responses = append(responses, protocol.EncodeResponse(simconnect_data.RECV_ID_NULL, nil))
+		}
+		return responses
+	})
+
+	transport := newNetworkTransport(address)
+	require.NoError(t, transport.Open(t.Name()))
+	require.Eventually(t, func() bool { return len(transport.received) == cap(transport.received) }, 5*time.Second, 10*time.Millisecond)
+
+	// readLoop, blocked on the full queue, stops along with the stand-in server
+	assert.NoError(t, transport.Close())
+	assert.Eventually(t, func() bool { return runtime.NumGoroutine() <= goroutines }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNetworkConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	_, err = NewSimConnectTCP(t.Name(), address)
+	assert.Error(t, err)
+}
@@ -1,13 +1,39 @@
 package simconnect
 
 import (
-	"errors"
+	"bytes"
 	"fmt"
-	"time"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
 
 	simconnect_data "github.com/JRascagneres/Simconnect-Go/simconnect-data"
 )
 
+// structDataTypes maps the simconnect_data structs which can be used as fields to their SIMCONNECT_DATATYPE.
+var structDataTypes = map[reflect.Type]uint32{
+	reflect.TypeOf(simconnect_data.SimconnectDataInitPosition{}): simconnect_data.DATATYPE_INITPOSITION,
+	reflect.TypeOf(simconnect_data.MarkerState{}):                simconnect_data.DATATYPE_MARKERSTATE,
+	reflect.TypeOf(simconnect_data.Waypoint{}):                   simconnect_data.DATATYPE_WAYPOINT,
+	reflect.TypeOf(simconnect_data.LatLonAlt{}):                  simconnect_data.DATATYPE_LATLONALT,
+	reflect.TypeOf(simconnect_data.XYZ{}):                        simconnect_data.DATATYPE_XYZ,
+}
+
+// fieldDataType returns the SIMCONNECT_DATATYPE a struct field of type t is registered as.
+func fieldDataType(t reflect.Type) (uint32, error) {
+	if dataType, ok := structDataTypes[t]; ok {
+		return dataType, nil
+	}
+
+	fieldType := t.Kind().String()
+	if fieldType == "array" {
+		fieldType = fmt.Sprintf("[%d]byte", t.Len())
+	}
+	return derefDataType(fieldType)
+}
+
 func derefDataType(fieldType string) (uint32, error) {
 	var dataType uint32
 	switch fieldType {
@@ -31,6 +57,8 @@ func derefDataType(fieldType string) (uint32, error) {
 		dataType = simconnect_data.DATATYPE_STRING256
 	case "[260]byte":
 		dataType = simconnect_data.DATATYPE_STRING260
+	case "string":
+		dataType = simconnect_data.DATATYPE_STRINGV
 	default:
 		return 0, fmt.Errorf("DATATYPE not implemented: %s", fieldType)
 	}
@@ -38,21 +66,47 @@ func derefDataType(fieldType string) (uint32, error) {
 	return dataType, nil
 }
 
-func retryFunc(maxRetryCount int, waitDuration time.Duration, dataFunc func() (bool, error)) error {
-	numAttempts := 1
+// writeDLL writes data to dir/SimConnect.dll unless an identical file is already there, returning the path written.
+// The file is written under a temporary name first so a concurrent process never loads a partially written DLL.
+func writeDLL(dir string, data []byte) (string, error) {
+	dllPath := filepath.Join(dir, "SimConnect.dll")
 
-	for {
-		shouldRetry, _ := dataFunc()
-		if !shouldRetry {
-			return nil
-		}
-
-		numAttempts++
+	existing, err := ioutil.ReadFile(dllPath)
+	if err == nil && bytes.Equal(existing, data) {
+		return dllPath, nil
+	}
 
-		if numAttempts >= maxRetryCount {
-			return errors.New("timeout exceeded err")
-		}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
 
-		time.Sleep(waitDuration)
+	tempFile, err := ioutil.TempFile(dir, "SimConnect-*.dll")
+	if err != nil {
+		return "", err
+	}
+	_, err = tempFile.Write(data)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFile.Name(), dllPath)
 	}
+	if err != nil {
+		os.Remove(tempFile.Name())
+		return "", fmt.Errorf("error writing %s: %v", dllPath, err)
+	}
+
+	return dllPath, nil
+}
+
+// float32Arg returns f as an argument of a SimConnect.dll call taking a float. Converting it with uintptr(f) would
+// pass its integer part instead of its bits.
+func float32Arg(f float32) uintptr {
+	return uintptr(math.Float32bits(f))
+}
+
+// float64Arg returns f as an argument of a SimConnect.dll call taking a double, which fits a uintptr on 64 bit
+// Windows.
+func float64Arg(f float64) uintptr {
+	return uintptr(math.Float64bits(f))
 }
@@ -0,0 +1,44 @@
+package simconnect
+
+import (
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDLL(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+
+	dllPath, err := writeDLL(dir, []byte("first"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "SimConnect.dll"), dllPath)
+
+	// An identical DLL is reused, a different one replaced
+	_, err = writeDLL(dir, []byte("first"))
+	require.NoError(t, err)
+	_, err = writeDLL(dir, []byte("second"))
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(dllPath)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestFloat32Arg(t *testing.T) {
+	assert.Equal(t, uintptr(0x3f000000), float32Arg(0.5))
+	assert.Equal(t, float32(2.25), math.Float32frombits(uint32(float32Arg(2.25))))
+	assert.Equal(t, uintptr(0), float32Arg(0))
+}
+
+func TestFloat64Arg(t *testing.T) {
+	assert.Equal(t, uint64(0x3fe0000000000000), uint64(float64Arg(0.5)))
+	assert.Equal(t, 2.25, math.Float64frombits(uint64(float64Arg(2.25))))
+}